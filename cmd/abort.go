@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+var abortFile string
+
+// abortFilePollInterval is the interval of checking whether the abort file exists.
+const abortFilePollInterval = 500 * time.Millisecond
+
+func init() {
+	rootCmd.Flags().StringVar(&abortFile, "abort-file", "",
+		"stop the run when the file is created (e.g., by an operator in another terminal); the running block is terminated and teardown and always=true blocks still run")
+}
+
+// errAborted is the cause of canceling a run by the abort file.
+var errAborted = errors.New("aborted")
+
+// withAbortFile returns a context canceled when the file at path is created, and a function to stop watching it.
+// It returns an error if the file already exists, so that a run does not start while the kill switch is on.
+func withAbortFile(ctx context.Context, path string, interval time.Duration) (context.Context, func(), error) {
+	exists, err := fileExists(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		return nil, nil, fmt.Errorf("%w: the abort file %s exists (remove it to run)", errAborted, path)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if exists, _ := fileExists(path); exists { //nostyle:handlerrors
+					cancel(fmt.Errorf("%w: the abort file %s was created", errAborted, path))
+					return
+				}
+			}
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		cancel(nil)
+	}, nil
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to check the abort file: %w", err)
+	}
+}
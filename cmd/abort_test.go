@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithAbortFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "STOP")
+	ctx, stop, err := withAbortFile(context.Background(), path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	select {
+	case <-ctx.Done():
+		t.Fatal("canceled before the abort file was created")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("not canceled after the abort file was created")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, errAborted) {
+		t.Errorf("got cause %v, want %v", cause, errAborted)
+	}
+
+	// A run does not start while the abort file exists
+	if _, _, err := withAbortFile(context.Background(), path, 10*time.Millisecond); !errors.Is(err, errAborted) {
+		t.Errorf("got %v, want %v", err, errAborted)
+	}
+}
+
+func TestWithAbortFileStop(t *testing.T) {
+	ctx, stop, err := withAbortFile(context.Background(), filepath.Join(t.TempDir(), "STOP"), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+	<-ctx.Done()
+	if cause := context.Cause(ctx); errors.Is(cause, errAborted) {
+		t.Errorf("got cause %v, want a cancellation by stop", cause)
+	}
+}
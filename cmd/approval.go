@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// verifySource refuses documents that are not approved by the allow-list or the signature flags.
+func verifySource(source []byte, args []string) error {
+	if allowHashes != "" {
+		if err := verifyHash(source, allowHashes); err != nil {
+			return err
+		}
+	}
+	if publicKeyPath != "" {
+		sigPath := signaturePath
+		if sigPath == "" {
+			if len(args) == 0 {
+				return errors.New("--signature is required when reading from stdin")
+			}
+			sigPath = args[0] + ".sig"
+		}
+		if err := verifySignature(source, sigPath, publicKeyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyHash checks that the SHA-256 hash of source is listed in the allow-list file.
+// The file contains one hex encoded hash per line (sha256sum output is accepted). Lines starting with # are ignored.
+func verifyHash(source []byte, allowList string) error {
+	b, err := os.ReadFile(allowList)
+	if err != nil {
+		return fmt.Errorf("failed to read allow-list: %w", err)
+	}
+	sum := sha256.Sum256(source)
+	hash := hex.EncodeToString(sum[:])
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if strings.EqualFold(strings.TrimPrefix(fields[0], "sha256:"), hash) {
+			return nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("failed to read allow-list: %w", err)
+	}
+	return fmt.Errorf("document is not approved: sha256 %s is not in the allow-list", hash)
+}
+
+// verifySignature verifies the detached Ed25519 signature of source.
+// The signature file may contain the raw signature or its base64 encoding.
+func verifySignature(source []byte, sigPath, keyPath string) error {
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return errors.New("failed to decode public key: no PEM data found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported public key type %T: only Ed25519 is supported", key)
+	}
+
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("failed to decode signature: %w", err)
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(pub, source, sig) {
+		return errors.New("document is not approved: signature verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyHash(t *testing.T) {
+	dir := t.TempDir()
+	allowList := filepath.Join(dir, "allow.txt")
+	// sha256("hello")
+	content := "# approved runbooks\n2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  runbook.md\n"
+	if err := os.WriteFile(allowList, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyHash([]byte("hello"), allowList); err != nil {
+		t.Errorf("verifyHash() error = %v", err)
+	}
+	if err := verifyHash([]byte("tampered"), allowList); err == nil {
+		t.Error("verifyHash() should return error for a document not in the allow-list")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "key.pub")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := []byte("```sh sh\necho hello\n```\n")
+	sig := ed25519.Sign(priv, source)
+	rawPath := filepath.Join(dir, "raw.sig")
+	if err := os.WriteFile(rawPath, sig, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b64Path := filepath.Join(dir, "b64.sig")
+	if err := os.WriteFile(b64Path, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifySignature(source, rawPath, keyPath); err != nil {
+		t.Errorf("verifySignature() raw error = %v", err)
+	}
+	if err := verifySignature(source, b64Path, keyPath); err != nil {
+		t.Errorf("verifySignature() base64 error = %v", err)
+	}
+	if err := verifySignature([]byte("```sh sh\nrm -rf /\n```\n"), rawPath, keyPath); err == nil {
+		t.Error("verifySignature() should return error for a tampered document")
+	}
+}
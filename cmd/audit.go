@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// auditEntry is a record of an executed command in the audit log.
+type auditEntry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	File        string    `json:"file"`
+	Index       int       `json:"index"`
+	Command     string    `json:"command"`
+	ExitCode    int       `json:"exit_code"`
+	User        string    `json:"user"`
+	ContentHash string    `json:"content_hash"`
+}
+
+// auditLog appends executed commands to an append-only JSON Lines file.
+type auditLog struct {
+	mu   sync.Mutex
+	f    *os.File
+	file string
+	user string
+	err  error
+}
+
+// openAuditLog opens the audit log at path for appending records of commands executed for file.
+func openAuditLog(path, file string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	username := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	return &auditLog{f: f, file: file, user: username}, nil
+}
+
+// record appends the result of an executed code block. Skipped blocks are not recorded.
+func (a *auditLog) record(result *runner.Result) {
+	if result.Skipped || result.StartedAt.IsZero() {
+		return
+	}
+	sum := sha256.Sum256([]byte(result.Block.Content))
+	b, err := json.Marshal(auditEntry{
+		Timestamp:   result.StartedAt,
+		File:        a.file,
+		Index:       result.Index,
+		Command:     result.Command,
+		ExitCode:    result.ExitCode,
+		User:        a.user,
+		ContentHash: "sha256:" + hex.EncodeToString(sum[:]),
+	})
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err == nil {
+		_, err = a.f.Write(append(b, '\n'))
+	}
+	if err != nil && a.err == nil {
+		a.err = fmt.Errorf("failed to write audit log: %w", err)
+	}
+}
+
+// Close closes the audit log and returns the first error that occurred while writing records.
+func (a *auditLog) Close() error {
+	if err := a.f.Close(); err != nil && a.err == nil {
+		a.err = err
+	}
+	return a.err
+}
@@ -0,0 +1,262 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+var cacheURL string
+
+func init() {
+	rootCmd.Flags().StringVar(&cacheURL, "cache", "",
+		"share blocks that succeeded across machines in a remote cache keyed by their hash, and skip blocks found in it (http(s)://HOST/PATH, s3://BUCKET/PREFIX or gs://BUCKET/PREFIX)")
+}
+
+// cacheTimeout is the timeout of a request to the remote cache.
+const cacheTimeout = 10 * time.Second
+
+// errCacheMiss is returned by cache backends for keys they do not have.
+var errCacheMiss = errors.New("not in the cache")
+
+// cacheBackend stores entries of the remote cache.
+type cacheBackend interface {
+	// has returns nil if the key is in the cache and errCacheMiss if it is not.
+	has(ctx context.Context, key string) error
+	put(ctx context.Context, key string, entry []byte) error
+}
+
+// newCacheBackend returns the backend of the cache URL.
+func newCacheBackend(rawURL string) (cacheBackend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --cache: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return &httpCache{base: strings.TrimSuffix(rawURL, "/"), token: os.Getenv("RUNBLOCK_CACHE_TOKEN")}, nil
+	case "s3":
+		// The AWS CLI takes the credentials and the region from the usual places
+		bucket, prefix := u.Host, strings.Trim(u.Path, "/")
+		return &commandCache{
+			hasArgs: func(key string) []string {
+				return []string{"aws", "s3api", "head-object", "--bucket", bucket, "--key", joinKey(prefix, key)}
+			},
+			putArgs: func(key string) []string {
+				return []string{"aws", "s3", "cp", "-", "s3://" + bucket + "/" + joinKey(prefix, key)}
+			},
+		}, nil
+	case "gs":
+		bucket, prefix := u.Host, strings.Trim(u.Path, "/")
+		return &commandCache{
+			hasArgs: func(key string) []string {
+				return []string{"gcloud", "storage", "objects", "describe", "gs://" + bucket + "/" + joinKey(prefix, key)}
+			},
+			putArgs: func(key string) []string {
+				return []string{"gcloud", "storage", "cp", "-", "gs://" + bucket + "/" + joinKey(prefix, key)}
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid --cache: unsupported scheme %q (http, https, s3 or gs)", u.Scheme)
+	}
+}
+
+// joinKey joins the prefix of a bucket and a key.
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+// httpCache is a cache served over HTTP: HEAD URL/KEY checks an entry and PUT URL/KEY stores it,
+// like the HTTP caches of build tools. RUNBLOCK_CACHE_TOKEN is sent as a bearer token if set.
+type httpCache struct {
+	base  string
+	token string
+}
+
+func (c *httpCache) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+"/"+key, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) //nostyle:handlerrors
+	_ = resp.Body.Close()                 //nostyle:handlerrors
+	return resp, nil
+}
+
+func (c *httpCache) has(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errCacheMiss
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("HEAD %s/%s: %s", c.base, key, resp.Status)
+	}
+	return nil
+}
+
+func (c *httpCache) put(ctx context.Context, key string, entry []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, key, entry)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s/%s: %s", c.base, key, resp.Status)
+	}
+	return nil
+}
+
+// commandCache is a cache in a bucket accessed with the CLI of the cloud.
+// A failure to check an entry is a miss, since the CLIs do not tell missing objects from other errors.
+type commandCache struct {
+	hasArgs func(key string) []string // Command checking an entry
+	putArgs func(key string) []string // Command storing an entry read from stdin
+}
+
+func (c *commandCache) run(ctx context.Context, args []string, stdin []byte) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", strings.Join(args[:3], " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (c *commandCache) has(ctx context.Context, key string) error {
+	if err := c.run(ctx, c.hasArgs(key), nil); err != nil {
+		return errCacheMiss
+	}
+	return nil
+}
+
+func (c *commandCache) put(ctx context.Context, key string, entry []byte) error {
+	return c.run(ctx, c.putArgs(key), entry)
+}
+
+// cacheEntry is the JSON stored for a block that succeeded.
+type cacheEntry struct {
+	Source      string    `json:"source"`
+	Index       int       `json:"index"`
+	Lang        string    `json:"lang"`
+	SucceededAt time.Time `json:"succeeded_at"`
+}
+
+// remoteCache skips the blocks found in a cache backend and stores the blocks that succeed.
+// Errors of the backend are warnings: the blocks run as if they were not in the cache.
+type remoteCache struct {
+	mu      sync.Mutex
+	backend cacheBackend
+	file    string
+	w       io.Writer
+	hits    map[int]bool
+}
+
+// attach makes r skip the blocks in the cache (unless force is set), noting them on w, and stores the successes of r.
+// The blocks up to date in the local state (if any) are not looked up.
+func (c *remoteCache) attach(w io.Writer, r *runner.Runner, force bool) {
+	c.w = w
+	c.hits = map[int]bool{}
+	if !force {
+		local := r.UpToDate
+		r.UpToDate = func(result *runner.Result) bool {
+			if local != nil && local(result) {
+				return true
+			}
+			return c.lookup(result)
+		}
+	}
+	addResultHook(r, func(result *runner.Result) {
+		c.mu.Lock()
+		hit := c.hits[result.Index]
+		c.mu.Unlock()
+		if hit && result.Skipped {
+			fmt.Fprintf(w, "Block %d is verified in the cache\n", result.Index+1)
+		}
+	})
+	addResultHook(r, c.store)
+}
+
+// lookup reports whether the block has succeeded with the same hash anywhere.
+func (c *remoteCache) lookup(result *runner.Result) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	err := c.backend.has(ctx, result.Hash)
+	if err != nil && !errors.Is(err, errCacheMiss) {
+		c.warn(result, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits[result.Index] = err == nil
+	return err == nil
+}
+
+// store stores a block that succeeded in the cache.
+func (c *remoteCache) store(result *runner.Result) {
+	if result.Skipped || result.Err != nil || result.Hash == "" {
+		return
+	}
+	b, err := json.Marshal(cacheEntry{Source: c.file, Index: result.Index, Lang: result.Block.Language, SucceededAt: result.StartedAt})
+	if err != nil {
+		c.warn(result, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+	if err := c.backend.put(ctx, result.Hash, b); err != nil {
+		c.warn(result, err)
+	}
+}
+
+func (c *remoteCache) warn(result *runner.Result, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.w, "Warning: cache of block %d: %v\n", result.Index+1, err)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestRemoteCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	var mu sync.Mutex
+	entries := map[string][]byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.TrimPrefix(r.URL.Path, "/cache/")
+		switch r.Method {
+		case http.MethodHead:
+			if _, ok := entries[key]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			b, _ := io.ReadAll(r.Body) //nostyle:handlerrors
+			entries[key] = b
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("RUNBLOCK_CACHE_TOKEN", "secret")
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo one"},
+		{Language: "sh", Command: "echo two; exit 1"},
+	}
+	run := func(force bool) (string, string) {
+		t.Helper()
+		backend, err := newCacheBackend(ts.URL + "/cache/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stdout, notes bytes.Buffer
+		r := runner.New("", nil)
+		r.Stdout = &stdout
+		r.KeepGoing = true
+		c := &remoteCache{backend: backend, file: "runbook.md"}
+		c.attach(&notes, r, force)
+		_ = r.RunAll(t.Context(), blocks) //nostyle:handlerrors
+		return stdout.String(), notes.String()
+	}
+
+	// Only the block that succeeded is stored
+	if stdout, notes := run(false); stdout != "one\ntwo\n" || notes != "" {
+		t.Errorf("first run: got stdout %q and notes %q", stdout, notes)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	for _, b := range entries {
+		if !bytes.Contains(b, []byte(`"source":"runbook.md"`)) {
+			t.Errorf("got entry %s, want the source of the block", b)
+		}
+	}
+	if stdout, notes := run(false); stdout != "two\n" || notes != "Block 1 is verified in the cache\n" {
+		t.Errorf("second run: got stdout %q and notes %q", stdout, notes)
+	}
+	if stdout, notes := run(true); stdout != "one\ntwo\n" || notes != "" {
+		t.Errorf("forced run: got stdout %q and notes %q", stdout, notes)
+	}
+
+	// Errors of the cache are warnings
+	t.Setenv("RUNBLOCK_CACHE_TOKEN", "wrong")
+	stdout, notes := run(false)
+	if stdout != "one\ntwo\n" || !strings.Contains(notes, "Warning: cache of block 1: HEAD ") {
+		t.Errorf("unauthorized run: got stdout %q and notes %q", stdout, notes)
+	}
+}
+
+func TestNewCacheBackend(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://cache.example.com/runblock", false},
+		{"s3://bucket/prefix", false},
+		{"gs://bucket", false},
+		{"ftp://example.com", true},
+		{"cache", true},
+	}
+	for _, tt := range tests {
+		if _, err := newCacheBackend(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("newCacheBackend(%q) error = %v, want error %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
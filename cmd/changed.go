@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+var (
+	onlyChanged bool
+	baseRef     string
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&onlyChanged, "only-changed-blocks", false,
+		"run only blocks whose lines changed since the merge base with --base (requires a file in a git repository)")
+	rootCmd.Flags().StringVar(&baseRef, "base", "origin/main",
+		"git ref to compare with --only-changed-blocks")
+}
+
+// lineRange is a range of 1-based line numbers.
+// A range with end < start marks a deletion just after the line end.
+type lineRange struct {
+	start, end int
+}
+
+// hunkHeader matches the header of a unified diff hunk, capturing the range of the new file.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// changedLines returns the line ranges of the file changed since the merge base of base and HEAD,
+// including changes in the working tree.
+func changedLines(ctx context.Context, file, base string) ([]lineRange, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", filepath.Dir(file), "diff", "--merge-base", base,
+		"--unified=0", "--no-color", "--no-ext-diff", "--", filepath.Base(file))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s against %s: %w: %s", file, base, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseHunks(out)
+}
+
+// parseHunks parses the hunk headers of a unified diff into the changed line ranges of the new file.
+func parseHunks(diff []byte) ([]lineRange, error) {
+	var ranges []lineRange
+	s := bufio.NewScanner(bytes.NewReader(diff))
+	for s.Scan() {
+		m := hunkHeader.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1]) //nostyle:handlerrors
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2]) //nostyle:handlerrors
+		}
+		if count == 0 {
+			// A deletion is reported with the line just before it
+			ranges = append(ranges, lineRange{start: start + 1, end: start})
+			continue
+		}
+		ranges = append(ranges, lineRange{start: start, end: start + count - 1})
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+	return ranges, nil
+}
+
+// blockChanged reports whether the lines of the block (fences included) overlap one of the ranges.
+// A deletion counts if it is between the fences of the block.
+func blockChanged(block parser.CodeBlock, ranges []lineRange) bool {
+	for _, r := range ranges {
+		if r.end < r.start {
+			if block.Line <= r.end && r.end < block.EndLine {
+				return true
+			}
+			continue
+		}
+		if r.start <= block.EndLine && block.Line <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// selectChanged narrows the blocks selected by r to the ones changed since --base.
+func selectChanged(ctx context.Context, r *runner.Runner, args []string) error {
+	if len(args) == 0 {
+		return errors.New("--only-changed-blocks requires a file argument (cannot diff stdin)")
+	}
+	ranges, err := changedLines(ctx, args[0], baseRef)
+	if err != nil {
+		return err
+	}
+	prev := r.Select
+	r.Select = func(block parser.CodeBlock, i int) bool {
+		if prev != nil && !prev(block, i) {
+			return false
+		}
+		return blockChanged(block, ranges)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestBlockChanged(t *testing.T) {
+	diff := []byte(`diff --git a/runbook.md b/runbook.md
+--- a/runbook.md
++++ b/runbook.md
+@@ -3 +3 @@
+-echo one
++echo uno
+@@ -12,0 +13,2 @@
++echo four
++echo five
+@@ -20,2 +20,0 @@
+-echo six
+-echo seven
+`)
+	ranges, err := parseHunks(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line, endLine int
+		want          bool
+	}{
+		{2, 4, true},    // Modified line
+		{6, 8, false},   // Unchanged
+		{12, 16, true},  // Added lines
+		{16, 19, false}, // After the added lines
+		{20, 23, true},  // Deleted lines between the fences
+		{21, 24, false}, // Deletion just before the opening fence
+	}
+	for _, tt := range tests {
+		block := parser.CodeBlock{Line: tt.line, EndLine: tt.endLine}
+		if got := blockChanged(block, ranges); got != tt.want {
+			t.Errorf("blockChanged(%d-%d) = %v, want %v", tt.line, tt.endLine, got, tt.want)
+		}
+	}
+}
+
+func TestSelectChanged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	file := filepath.Join(dir, "runbook.md")
+	write := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(s), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	write("# Runbook\n\n```sh sh\necho one\n```\n\n```sh sh\necho two\n```\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	git("checkout", "-q", "-b", "feature")
+	write("# Runbook\n\nIntro.\n\n```sh sh\necho one\n```\n\n```sh sh\necho zwei\n```\n")
+
+	source, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := parser.Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	r := runner.New("", nil)
+	r.Stdout = &stdout
+	baseRef = "main"
+	t.Cleanup(func() { baseRef = "origin/main" })
+	if err := selectChanged(t.Context(), r, []string{file}); err != nil {
+		t.Fatalf("selectChanged() error = %v", err)
+	}
+	if err := r.RunAll(t.Context(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	// The first block only moved down, so only the second one runs
+	if got, want := stdout.String(), "zwei\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	baseRef = "unknown"
+	if err := selectChanged(t.Context(), runner.New("", nil), []string{file}); err == nil {
+		t.Error("selectChanged() should return error for an unknown ref")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteBlockValues(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.md")
+	doc := "```sh {name=build}\necho\n```\n\n```go {name=test}\n```\n\n```sh\n```\n"
+	if err := os.WriteFile(file, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		value         func(parser.CodeBlock) string
+		args          []string
+		want          []string
+		wantDirective cobra.ShellCompDirective
+	}{
+		{
+			name:          "names",
+			value:         parser.CodeBlock.Name,
+			args:          []string{file},
+			want:          []string{"build", "test"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "languages",
+			value:         func(b parser.CodeBlock) string { return b.Language },
+			args:          []string{file},
+			want:          []string{"sh", "go"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "no file",
+			value:         parser.CodeBlock.Name,
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "missing file",
+			value:         parser.CodeBlock.Name,
+			args:          []string{filepath.Join(t.TempDir(), "missing.md")},
+			wantDirective: cobra.ShellCompDirectiveError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := completeBlockValues(tt.value)(rootCmd, tt.args, "")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if directive != tt.wantDirective {
+				t.Errorf("got directive %v, want %v", directive, tt.wantDirective)
+			}
+		})
+	}
+}
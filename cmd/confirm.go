@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var assumeYes bool
+
+func init() {
+	rootCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false,
+		"assume yes to confirmations (e.g., --as-user)")
+}
+
+// errNotConfirmed is returned when a confirmation is declined.
+var errNotConfirmed = errors.New("aborted: not confirmed")
+
+// confirm asks the question on out and reads the answer from in.
+// It returns errNotConfirmed unless the answer is yes.
+func confirm(in io.Reader, out io.Writer, question string) error {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	br, ok := in.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(in)
+	}
+	answer, err := br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read the answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errNotConfirmed
+	}
+}
+
+// confirmOnTerminal asks the question on the terminal unless --yes is set.
+// The terminal must be stdin, so the document must be read from a file.
+func confirmOnTerminal(args []string, question string) error {
+	if assumeYes {
+		return nil
+	}
+	if len(args) == 0 || !isTerminal(os.Stdin) {
+		return errors.New("confirmation is required: use --yes in non-interactive runs")
+	}
+	return tty.interact(func(in *bufio.Reader, out io.Writer) error {
+		return confirm(in, out, question)
+	})
+}
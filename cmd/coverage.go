@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+// coverageCmd represents the coverage command
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [DIR]",
+	Short: "Show which code blocks in a docs tree have been exercised",
+	Long: `coverage finds the Markdown files under DIR (default: the current directory) and shows,
+from the execution state recorded by --state, which code blocks have succeeded with their
+current command and content, which have changed since they last succeeded and which have never run.
+
+Paths matching the gitignore-style patterns in DIR/.runblockignore are skipped.
+Blocks without a command are not counted. Run it in the directory the documents are run from,
+since the state is stored under .runblock/state in the working directory.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		return coverage(cmd.OutOrStdout(), r, dir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+}
+
+// Coverage statuses of code blocks.
+const (
+	coverageVerified = "verified"
+	coverageStale    = "changed since last success"
+	coverageNever    = "never run"
+)
+
+// coverage writes the coverage of the code blocks of the Markdown files under dir to w.
+func coverage(w io.Writer, r *runner.Runner, dir string) error {
+	files, err := markdownFiles(dir)
+	if err != nil {
+		return err
+	}
+	var total, verified int
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		state, err := loadState(file)
+		if err != nil {
+			return err
+		}
+
+		if err := applyDocConfig(r, source); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		r.File = file
+
+		var n, ok int
+		var lines []string
+		for i, block := range blocks {
+			res, err := r.Resolve(block, i)
+			if err != nil {
+				lines = append(lines, fmt.Sprintf("  block %d (line %d, %s): %v", i+1, block.Line, block.Language, err))
+				n++
+				continue
+			}
+			if res.Skip {
+				continue
+			}
+			n++
+			status := coverageNever
+			if b, exists := state.Blocks[i]; exists {
+				status = coverageStale
+				if b.Hash == res.Hash() {
+					status = coverageVerified
+				}
+			}
+			if status == coverageVerified {
+				ok++
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  block %d (line %d, %s): %s", i+1, block.Line, block.Language, status))
+		}
+		if n == 0 {
+			continue
+		}
+		total += n
+		verified += ok
+		fmt.Fprintf(w, "%s: %d/%d blocks verified (%s)\n", file, ok, n, percent(ok, n))
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+		}
+	}
+	fmt.Fprintf(w, "Total: %d/%d blocks verified (%s)\n", verified, total, percent(verified, total))
+	return nil
+}
+
+// markdownFiles returns the Markdown files under dir, skipping hidden directories
+// and the paths excluded by the .runblockignore file in dir.
+func markdownFiles(dir string) ([]string, error) {
+	rules, err := loadIgnore(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rules.ignored(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".markdown":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Markdown files: %w", err)
+	}
+	return files, nil
+}
+
+// percent formats n/total as a percentage.
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestCoverage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Chdir(t.TempDir())
+
+	doc := "```sh sh\necho one\n```\n\n```sh sh\necho two\n```\n\n```text\nplain\n```\n"
+	for _, path := range []string{"docs/a.md", "docs/b.md", ".hidden/c.md"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Run docs/a.md and then change its second block
+	blocks, err := parser.Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := loadState(filepath.Join("docs", "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runner.New("", nil)
+	r.Stdout = &bytes.Buffer{}
+	r.File = filepath.Join("docs", "a.md")
+	state.attach(&bytes.Buffer{}, r, false)
+	if err := r.RunAll(t.Context(), blocks); err != nil {
+		t.Fatal(err)
+	}
+	changed := strings.Replace(doc, "echo two", "echo three", 1)
+	if err := os.WriteFile(filepath.Join("docs", "a.md"), []byte(changed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := coverage(&buf, runner.New("", nil), "."); err != nil {
+		t.Fatalf("coverage() error = %v", err)
+	}
+	want := `docs/a.md: 1/2 blocks verified (50.0%)
+  block 2 (line 5, sh): changed since last success
+docs/b.md: 0/2 blocks verified (0.0%)
+  block 1 (line 1, sh): never run
+  block 2 (line 5, sh): never run
+Total: 1/4 blocks verified (25.0%)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
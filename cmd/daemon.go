@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	daemonConfigPath string
+	daemonListen     string
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run Markdown files periodically on cron-style schedules",
+	Long: `daemon runs the code blocks of Markdown files on cron-style schedules so that
+runbooks and docs are re-verified periodically. The files are listed in the config file:
+
+    notify_url: https://hooks.slack.com/services/...  # notified of failed runs
+    notify_failures: true                             # include failed blocks in notifications
+    files:
+      - path: docs/runbook.md
+        schedule: "0 6 * * *"                         # minute hour day-of-month month day-of-week
+      - path: README.md
+        schedule: "@hourly"
+        notify_url: https://example.com/webhook      # overrides notify_url for the file
+
+Paths are relative to the config file. Runs are executed one at a time, and the file is
+re-read on every run. With --listen, Prometheus metrics are served on /metrics.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		cfg, err := loadDaemonConfig(daemonConfigPath)
+		if err != nil {
+			return err
+		}
+		d := newDaemon(cfg, os.Stderr)
+		if daemonListen != "" {
+			ln, err := net.Listen("tcp", daemonListen)
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics\n", ln.Addr())
+			go func() {
+				if err := serve(ctx, ln, d.metrics); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: metrics server: %v\n", err)
+				}
+			}()
+		}
+		return d.run(ctx)
+	},
+}
+
+func init() {
+	daemonCmd.Flags().StringVar(&daemonConfigPath, "config", "runblock.yaml", "config file listing the files and their schedules")
+	daemonCmd.Flags().StringVar(&daemonListen, "listen", "", "address to serve Prometheus metrics on /metrics (e.g., ':9090')")
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// daemonConfig is the config file of the daemon.
+type daemonConfig struct {
+	NotifyURL      string       `yaml:"notify_url"`
+	NotifyFailures bool         `yaml:"notify_failures"`
+	Files          []daemonFile `yaml:"files"`
+}
+
+// daemonFile is a Markdown file run on a schedule.
+type daemonFile struct {
+	Path      string `yaml:"path"`
+	Schedule  string `yaml:"schedule"`
+	NotifyURL string `yaml:"notify_url"` // Overrides the notify_url of the config
+
+	schedule *schedule
+}
+
+// loadDaemonConfig loads the config file at path and parses the schedules of its files.
+func loadDaemonConfig(path string) (*daemonConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg := &daemonConfig{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if len(cfg.Files) == 0 {
+		return nil, fmt.Errorf("no files in config %s", path)
+	}
+	for i := range cfg.Files {
+		f := &cfg.Files[i]
+		if f.Path == "" {
+			return nil, fmt.Errorf("files[%d]: path is required", i)
+		}
+		if !filepath.IsAbs(f.Path) {
+			f.Path = filepath.Join(filepath.Dir(path), f.Path)
+		}
+		f.schedule, err = parseSchedule(f.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("files[%d] (%s): %w", i, f.Path, err)
+		}
+		if f.NotifyURL == "" {
+			f.NotifyURL = cfg.NotifyURL
+		}
+	}
+	return cfg, nil
+}
+
+// daemon runs files on their schedules.
+type daemon struct {
+	cfg     *daemonConfig
+	log     io.Writer
+	metrics *blockMetrics
+	running sync.Mutex // Held while a run is in progress
+	now     func() time.Time
+}
+
+func newDaemon(cfg *daemonConfig, log io.Writer) *daemon {
+	return &daemon{cfg: cfg, log: log, metrics: newBlockMetrics(), now: time.Now}
+}
+
+// run runs the files on their schedules until ctx is done.
+func (d *daemon) run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, f := range d.cfg.Files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				next := f.schedule.next(d.now())
+				if next.IsZero() {
+					fmt.Fprintf(d.log, "%s: the schedule %q never matches\n", f.Path, f.Schedule)
+					return
+				}
+				fmt.Fprintf(d.log, "%s: next run at %s\n", f.Path, next.Format(time.RFC3339))
+				if err := sleepUntil(ctx, next); err != nil {
+					return
+				}
+				d.running.Lock()
+				err := d.runFile(ctx, f)
+				d.running.Unlock()
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					fmt.Fprintf(d.log, "%s: run failed: %v\n", f.Path, err)
+					continue
+				}
+				fmt.Fprintf(d.log, "%s: run passed\n", f.Path)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// runFile runs the code blocks of a file and notifies the failure of the run.
+func (d *daemon) runFile(ctx context.Context, f daemonFile) (err error) {
+	source, err := readSource([]string{f.Path})
+	if err == nil {
+		err = verifySource(source, []string{f.Path})
+	}
+	if err != nil {
+		return err
+	}
+	blocks, err := parseBlocks(source)
+	if err != nil {
+		return err
+	}
+	r, err := newRunner()
+	if err != nil {
+		return err
+	}
+	if err := applyDocConfig(r, source); err != nil {
+		return err
+	}
+	r.File = f.Path
+	tmpDir, err := os.MkdirTemp("", "runblock-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	r.TmpDir = tmpDir
+	defer func() {
+		err = errors.Join(err, os.RemoveAll(tmpDir))
+	}()
+	if auditLogPath != "" {
+		audit, err := openAuditLog(auditLogPath, f.Path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, audit.Close())
+		}()
+		addResultHook(r, audit.record)
+	}
+
+	rp := newReport(f.Path)
+	r.CaptureOutput = d.cfg.NotifyFailures
+	addResultHook(r, rp.record)
+	addResultHook(r, d.metrics.record)
+	runErr := r.RunAll(ctx, blocks)
+	rp.Duration = time.Since(rp.StartedAt)
+	if runErr != nil && f.NotifyURL != "" {
+		runErr = errors.Join(runErr, notify(ctx, f.NotifyURL, rp, d.cfg.NotifyFailures))
+	}
+	return runErr
+}
+
+// sleepUntil waits until t or until ctx is done.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
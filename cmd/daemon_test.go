@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadDaemonConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runblock.yaml")
+	config := `notify_url: https://example.com/all
+files:
+  - path: docs/runbook.md
+    schedule: "0 6 * * *"
+  - path: /abs/README.md
+    schedule: "@hourly"
+    notify_url: https://example.com/readme
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadDaemonConfig(path)
+	if err != nil {
+		t.Fatalf("loadDaemonConfig() error = %v", err)
+	}
+	if got, want := cfg.Files[0].Path, filepath.Join(dir, "docs", "runbook.md"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if got := cfg.Files[1].Path; got != "/abs/README.md" {
+		t.Errorf("path = %q, want %q", got, "/abs/README.md")
+	}
+	if got := cfg.Files[0].NotifyURL; got != "https://example.com/all" {
+		t.Errorf("notify_url = %q", got)
+	}
+	if got := cfg.Files[1].NotifyURL; got != "https://example.com/readme" {
+		t.Errorf("notify_url = %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("files:\n  - path: a.md\n    schedule: \"every day\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDaemonConfig(path); err == nil {
+		t.Error("loadDaemonConfig() should return error for an invalid schedule")
+	}
+}
+
+func TestDaemon_RunFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	var notified []notification
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		notified = append(notified, n)
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	passing := filepath.Join(dir, "passing.md")
+	failing := filepath.Join(dir, "failing.md")
+	if err := os.WriteFile(passing, []byte("```sh sh\nexit 0\n```\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(failing, []byte("```sh sh\nexit 0\n```\n\n```sh sh\nexit 1\n```\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDaemon(&daemonConfig{}, &bytes.Buffer{})
+	if err := d.runFile(t.Context(), daemonFile{Path: passing, NotifyURL: ts.URL}); err != nil {
+		t.Errorf("runFile() error = %v", err)
+	}
+	if err := d.runFile(t.Context(), daemonFile{Path: failing, NotifyURL: ts.URL}); err == nil {
+		t.Error("runFile() should return error")
+	}
+
+	// Only the failed run is notified
+	if len(notified) != 1 || notified[0].File != failing || notified[0].Failed != 1 {
+		t.Errorf("notified = %+v", notified)
+	}
+	var metrics bytes.Buffer
+	d.metrics.write(&metrics)
+	for _, want := range []string{
+		`runblock_blocks_run_total{lang="sh",status="failed"} 1`,
+		`runblock_blocks_run_total{lang="sh",status="passed"} 2`,
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, metrics.String())
+		}
+	}
+}
+
+func TestDaemon_RunFileApproval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	approved := filepath.Join(dir, "approved.md")
+	unapproved := filepath.Join(dir, "unapproved.md")
+	if err := os.WriteFile(approved, []byte("```sh sh\nexit 0\n```\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(unapproved, []byte("```sh sh\necho changed\n```\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("```sh sh\nexit 0\n```\n"))
+	allowHashes = filepath.Join(dir, "allowed.txt")
+	auditLogPath = filepath.Join(dir, "audit.jsonl")
+	t.Cleanup(func() { allowHashes, auditLogPath = "", "" })
+	if err := os.WriteFile(allowHashes, []byte(hex.EncodeToString(sum[:])+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDaemon(&daemonConfig{}, &bytes.Buffer{})
+	if err := d.runFile(t.Context(), daemonFile{Path: unapproved}); err == nil || !strings.Contains(err.Error(), "document is not approved") {
+		t.Errorf("runFile() error = %v, want an approval error", err)
+	}
+	if err := d.runFile(t.Context(), daemonFile{Path: approved}); err != nil {
+		t.Errorf("runFile() error = %v", err)
+	}
+
+	b, err := os.ReadFile(auditLogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry auditEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatalf("invalid audit log: %v\n%s", err, b)
+	}
+	if entry.File != approved || entry.Command != "sh" {
+		t.Errorf("audit entry = %+v", entry)
+	}
+}
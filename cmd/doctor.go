@@ -0,0 +1,299 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+// doctorTimeout is the timeout of checking a backend.
+const doctorTimeout = 10 * time.Second
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [MARKDOWN_FILE]",
+	Short: "Check the environment for running code blocks",
+	Long: `doctor checks the environment code blocks are executed in and prints
+actionable fixes for the problems it finds:
+
+  - the flags and the policy file
+  - the shell used for commands with arguments
+  - the executables of the commands set by flags and, if a file is given,
+    used by its code blocks
+  - the Docker daemon and the SSH hosts used by the commands
+
+Unlike the other subcommands, doctor does not read stdin without a file.
+Nothing is executed except 'docker info' and 'ssh HOST true' to check backends.
+doctor exits with a non-zero status if it finds a problem.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var blocks []parser.CodeBlock
+		if len(args) > 0 {
+			var err error
+			blocks, err = readBlocks(args)
+			if err != nil {
+				return err
+			}
+		}
+		return doctor(cmd.Context(), cmd.OutOrStdout(), blocks)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// checkup writes the results of checks.
+type checkup struct {
+	w        io.Writer
+	problems int
+}
+
+func (c *checkup) section(name string) {
+	fmt.Fprintln(c.w, name)
+}
+
+func (c *checkup) ok(format string, a ...any) {
+	fmt.Fprintf(c.w, "  ✓ %s\n", fmt.Sprintf(format, a...))
+}
+
+func (c *checkup) fail(fix, format string, a ...any) {
+	c.problems++
+	fmt.Fprintf(c.w, "  ✗ %s\n    fix: %s\n", fmt.Sprintf(format, a...), fix)
+}
+
+// doctor checks the environment for running the code blocks and writes the results to w.
+func doctor(ctx context.Context, w io.Writer, blocks []parser.CodeBlock) error {
+	c := &checkup{w: w}
+
+	c.section("Configuration")
+	r, err := newRunner()
+	if err != nil {
+		c.fail("fix the flags (see 'runblock --help')", "%v", err)
+		r = runner.New(defaultCommand, nil)
+	} else {
+		c.ok("flags are valid")
+	}
+	if policyPath != "" {
+		if policy, err := os.ReadFile(policyPath); err == nil {
+			if err := runner.CheckPolicy(string(policy)); err != nil {
+				c.fail("fix the CEL expression in "+policyPath, "policy %s: %v", policyPath, err)
+				r.Policy = "" // Check the commands of the blocks regardless of the policy
+			} else {
+				c.ok("policy %s compiles", policyPath)
+			}
+		}
+	}
+
+	c.section("Shell")
+	sh, _, _ := runner.BuildCommand("echo ok")
+	if path, err := exec.LookPath(sh); err != nil {
+		c.fail("set SHELL to an installed shell (e.g., /bin/sh)", "%s: not found", sh)
+	} else {
+		c.ok("%s", path)
+	}
+
+	c.section("Commands")
+	uses := commandUses(c, r, blocks)
+	if len(uses) == 0 {
+		c.ok("no commands to check")
+	}
+	exes := make([]string, 0, len(uses))
+	for exe := range uses {
+		exes = append(exes, exe)
+	}
+	slices.Sort(exes)
+	found := map[string]bool{}
+	for _, exe := range exes {
+		users := strings.Join(uses[exe].users, ", ")
+		path, err := exec.LookPath(exe)
+		if err != nil {
+			c.fail(fmt.Sprintf("install %s, or change the command with -c LANG:COMMAND or --default-command", exe),
+				"%s: not found (used by %s)", exe, users)
+			continue
+		}
+		found[exe] = true
+		c.ok("%s: %s (used by %s)", exe, path, users)
+	}
+
+	var backends []string
+	for _, exe := range []string{"docker", "podman"} {
+		if found[exe] {
+			backends = append(backends, exe)
+		}
+	}
+	var hosts []string
+	if found["ssh"] {
+		hosts = uses["ssh"].hosts
+	}
+	if len(backends) == 0 && len(hosts) == 0 {
+		return c.result()
+	}
+	c.section("Backends")
+	for _, exe := range backends {
+		ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+		out, err := exec.CommandContext(ctx, exe, "info", "--format", "{{.ServerVersion}}").Output()
+		cancel()
+		if err != nil {
+			c.fail(fmt.Sprintf("start the %s daemon, or check DOCKER_HOST and the permissions of its socket", exe),
+				"%s: daemon is not reachable: %v", exe, err)
+			continue
+		}
+		c.ok("%s: daemon is reachable (server %s)", exe, strings.TrimSpace(string(out)))
+	}
+	for _, host := range hosts {
+		ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+		err := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", "-o", "ConnectTimeout=5", host, "true").Run()
+		cancel()
+		if err != nil {
+			c.fail("check the host name and that non-interactive (key-based) authentication works",
+				"ssh %s: not reachable: %v", host, err)
+			continue
+		}
+		c.ok("ssh %s: reachable", host)
+	}
+	return c.result()
+}
+
+// result returns an error if a check failed.
+func (c *checkup) result() error {
+	if c.problems > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", c.problems)
+	}
+	return nil
+}
+
+// commandUse is the usage of an executable by the commands.
+type commandUse struct {
+	users []string // Flags and blocks using the executable
+	hosts []string // Destinations of ssh
+}
+
+// commandUses resolves the commands set by the flags and used by the blocks
+// and returns their executables. Commands that fail to resolve are reported to c.
+func commandUses(c *checkup, r *runner.Runner, blocks []parser.CodeBlock) map[string]*commandUse {
+	uses := map[string]*commandUse{}
+	add := func(user, command string) {
+		fields := commandFields(command)
+		if len(fields) == 0 {
+			return
+		}
+		exe := fields[0]
+		u, ok := uses[exe]
+		if !ok {
+			u = &commandUse{}
+			uses[exe] = u
+		}
+		if !slices.Contains(u.users, user) {
+			u.users = append(u.users, user)
+		}
+		if exe == "ssh" {
+			if host := sshHost(fields[1:]); host != "" && !slices.Contains(u.hosts, host) {
+				u.hosts = append(u.hosts, host)
+			}
+		}
+	}
+
+	// Commands set by the flags are checked regardless of the policy
+	flagRunner := *r
+	flagRunner.Policy = ""
+	if r.DefaultCommand != "" {
+		if res, err := flagRunner.Resolve(parser.CodeBlock{}, 0); err != nil {
+			c.fail("fix the template of --default-command", "--default-command: %v", err)
+		} else {
+			add("--default-command", res.Command)
+		}
+	}
+	langs := make([]string, 0, len(r.Commands))
+	for lang := range r.Commands {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	for _, lang := range langs {
+		if res, err := flagRunner.Resolve(parser.CodeBlock{Language: lang}, 0); err != nil {
+			c.fail("fix the template of -c "+lang, "-c %s: %v", lang, err)
+		} else {
+			add("-c "+lang, res.Command)
+		}
+	}
+	for _, lang := range slices.Sorted(maps.Keys(r.Plugins)) {
+		add("--use "+lang, runner.PluginPrefix+r.Plugins[lang])
+	}
+
+	for i, block := range blocks {
+		if r.Select != nil && !r.Select(block, i) {
+			continue
+		}
+		user := fmt.Sprintf("block %d", i+1)
+		res, err := r.Resolve(block, i)
+		switch {
+		case errors.Is(err, runner.ErrPolicyDenied):
+			c.ok("%s is denied by the policy", user)
+		case err != nil:
+			c.fail(fmt.Sprintf("fix the info string of the block at line %d", block.Line), "%s: %v", user, err)
+		case !res.Skip:
+			add(user, res.Command)
+		}
+	}
+	return uses
+}
+
+// commandFields returns the fields of a command from its executable,
+// skipping the environment variable assignments, env and exec before it.
+func commandFields(command string) []string {
+	fields := strings.Fields(command)
+	for len(fields) > 0 && (strings.Contains(fields[0], "=") || fields[0] == "env" || fields[0] == "exec") {
+		fields = fields[1:]
+	}
+	return fields
+}
+
+// sshOptionsWithArg are the options of ssh taking an argument.
+const sshOptionsWithArg = "BbcDEeFIiJLlmOopQRSWw"
+
+// sshHost returns the destination of ssh arguments, or "" if there is none.
+func sshHost(args []string) string {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			return a
+		}
+		if len(a) == 2 && strings.ContainsRune(sshOptionsWithArg, rune(a[1])) {
+			i++
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestDoctor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	policy := filepath.Join(t.TempDir(), "policy.cel")
+	if err := os.WriteFile(policy, []byte(`lang +`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	commands = []string{"sh:sh"}
+	policyPath = policy
+	t.Cleanup(func() { commands, policyPath = nil, "" })
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Line: 1},
+		{Language: "txt", Command: "FOO=bar runblock-missing-command --flag", Line: 5},
+		{Language: "txt", Command: "echo {{nope}}", Line: 9},
+		{Language: "txt", Line: 13},
+	}
+	var out bytes.Buffer
+	err := doctor(t.Context(), &out, blocks)
+	if err == nil {
+		t.Fatal("doctor() should return error")
+	}
+	// The invalid policy, the invalid template and the missing command
+	if got, want := err.Error(), "doctor found 3 problem(s)"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+	for _, want := range []string{
+		"  ✗ policy " + policy + ": policy compilation error:",
+		"  ✗ block 3: failed to expand template:",
+		"    fix: fix the info string of the block at line 9\n",
+		"(used by -c sh, block 1)\n",
+		"  ✗ runblock-missing-command: not found (used by block 2)\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSSHHost(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"host", "uptime"}, want: "host"},
+		{args: []string{"-p", "2222", "-o", "BatchMode=yes", "user@host", "uptime"}, want: "user@host"},
+		{args: []string{"-tt", "host"}, want: "host"},
+		{args: []string{"-p", "22"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := sshHost(tt.args); got != tt.want {
+			t.Errorf("sshHost(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
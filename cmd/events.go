@@ -0,0 +1,262 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// eventsNDJSON is the format of the event log with one JSON object per line.
+const eventsNDJSON = "ndjson"
+
+var (
+	eventsFormat string
+	eventsTo     string
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&eventsFormat, "events", "",
+		"emit a stream of events (parse, block_start, output_chunk, block_end, summary) in the format (ndjson)")
+	rootCmd.Flags().StringVar(&eventsTo, "events-to", "",
+		"file to append the events to, or fd:N for a file descriptor (default: stderr)")
+}
+
+// Types of events.
+const (
+	eventParse       = "parse"
+	eventBlockStart  = "block_start"
+	eventOutputChunk = "output_chunk"
+	eventBlockEnd    = "block_end"
+	eventSummary     = "summary"
+)
+
+// eventHeader is the fields common to all events.
+type eventHeader struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+}
+
+// Events of a run.
+type (
+	parseEvent struct {
+		eventHeader
+		File     string `json:"file"`
+		Blocks   int    `json:"blocks"`
+		Selected int    `json:"selected"`
+	}
+	blockStartEvent struct {
+		eventHeader
+		Index   int    `json:"index"`
+		Name    string `json:"name,omitempty"`
+		Lang    string `json:"lang"`
+		Line    int    `json:"line"`
+		Command string `json:"command"`
+	}
+	outputChunkEvent struct {
+		eventHeader
+		Index  int    `json:"index"`
+		Stream string `json:"stream"`
+		Data   string `json:"data"`
+	}
+	blockEndEvent struct {
+		eventHeader
+		Index      int    `json:"index"`
+		Status     string `json:"status"`
+		ExitCode   int    `json:"exit_code"`
+		DurationMS int64  `json:"duration_ms"`
+		SkipReason string `json:"skip_reason,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}
+	summaryEvent struct {
+		eventHeader
+		Passed     int    `json:"passed"`
+		Failed     int    `json:"failed"`
+		Skipped    int    `json:"skipped"`
+		DurationMS int64  `json:"duration_ms"`
+		Error      string `json:"error,omitempty"`
+	}
+)
+
+// eventLog writes the events of a run as NDJSON.
+type eventLog struct {
+	mu      sync.Mutex
+	w       io.Writer
+	c       io.Closer // nil if w is not owned by the stream
+	index   int       // Index of the running block (-1 if none)
+	started time.Time
+	passed  int
+	failed  int
+	skipped int
+	err     error
+	now     func() time.Time
+}
+
+// openEventLog opens the event log of the format writing to dest
+// (a file to append to, fd:N for a file descriptor, or "" for stderr).
+func openEventLog(format, dest string) (*eventLog, error) {
+	if format != eventsNDJSON {
+		return nil, fmt.Errorf("invalid --events %q: expected 'ndjson'", format)
+	}
+	s := &eventLog{w: os.Stderr, index: -1, now: time.Now}
+	switch {
+	case dest == "":
+	case strings.HasPrefix(dest, "fd:"):
+		fd, err := strconv.Atoi(strings.TrimPrefix(dest, "fd:"))
+		if err != nil || fd < 0 {
+			return nil, fmt.Errorf("invalid --events-to %q: expected fd:N", dest)
+		}
+		s.w = os.NewFile(uintptr(fd), dest)
+	default:
+		f, err := os.OpenFile(dest, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open --events-to file: %w", err)
+		}
+		s.w, s.c = f, f
+	}
+	s.started = s.now()
+	return s, nil
+}
+
+// header returns the header of an event of the type.
+func (s *eventLog) header(typ string) eventHeader {
+	return eventHeader{Type: typ, Time: s.now()}
+}
+
+// emit writes an event. The caller must hold mu.
+func (s *eventLog) emit(v any) {
+	b, err := json.Marshal(v)
+	if err == nil {
+		_, err = s.w.Write(append(b, '\n'))
+	}
+	if err != nil && s.err == nil {
+		s.err = fmt.Errorf("failed to write events: %w", err)
+	}
+}
+
+// attach emits the parse event of the blocks of file and the events of the code blocks run by r.
+func (s *eventLog) attach(r *runner.Runner, file string, blocks []parser.CodeBlock) {
+	s.mu.Lock()
+	s.emit(parseEvent{
+		eventHeader: s.header(eventParse),
+		File:        file,
+		Blocks:      len(blocks),
+		Selected:    countSelected(blocks, r.Select),
+	})
+	s.mu.Unlock()
+	r.Stdout = s.wrap(r.Stdout, "stdout")
+	r.Stderr = s.wrap(r.Stderr, "stderr")
+	addStartHook(r, s.start)
+	addResultHook(r, s.finish)
+}
+
+// wrap returns a writer emitting the output written to w as output chunks of the running block.
+func (s *eventLog) wrap(w io.Writer, stream string) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		s.mu.Lock()
+		if s.index >= 0 && len(b) > 0 {
+			s.emit(outputChunkEvent{
+				eventHeader: s.header(eventOutputChunk),
+				Index:       s.index,
+				Stream:      stream,
+				Data:        string(b),
+			})
+		}
+		s.mu.Unlock()
+		return w.Write(b)
+	})
+}
+
+// start emits the block_start event of a code block.
+func (s *eventLog) start(result *runner.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = result.Index
+	s.emit(blockStartEvent{
+		eventHeader: s.header(eventBlockStart),
+		Index:       result.Index,
+		Name:        result.Block.Name(),
+		Lang:        result.Block.Language,
+		Line:        result.Block.Line,
+		Command:     result.Command,
+	})
+}
+
+// finish emits the block_end event of a code block.
+func (s *eventLog) finish(result *runner.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = -1
+	e := blockEndEvent{
+		eventHeader: s.header(eventBlockEnd),
+		Index:       result.Index,
+		Status:      statusPassed,
+		ExitCode:    result.ExitCode,
+		DurationMS:  result.Duration.Milliseconds(),
+		SkipReason:  result.SkipReason,
+	}
+	switch {
+	case result.Err != nil:
+		s.failed++
+		e.Status = statusFailed
+		e.Error = result.Err.Error()
+	case result.Skipped:
+		s.skipped++
+		e.Status = statusSkipped
+	default:
+		s.passed++
+	}
+	s.emit(e)
+}
+
+// Close emits the summary event of the run ending with err and closes the stream.
+// It returns the first error that occurred while writing events.
+func (s *eventLog) Close(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := summaryEvent{
+		eventHeader: s.header(eventSummary),
+		Passed:      s.passed,
+		Failed:      s.failed,
+		Skipped:     s.skipped,
+		DurationMS:  s.now().Sub(s.started).Milliseconds(),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	s.emit(e)
+	if s.c != nil {
+		if err := s.c.Close(); err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+	return s.err
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestEventLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	events, err := openEventLog(eventsNDJSON, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo hello", Line: 1},
+		{Language: "text", Line: 5},
+		{Language: "sh", Command: "echo oops >&2; exit 3", Line: 9},
+	}
+	var stdout, stderr bytes.Buffer
+	r := &runner.Runner{Stdout: &stdout, Stderr: &stderr, KeepGoing: true}
+	events.attach(r, "README.md", blocks)
+	runErr := r.RunAll(t.Context(), blocks)
+	if runErr == nil {
+		t.Fatal("RunAll() should return error")
+	}
+	if err := events.Close(runErr); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello\n" || stderr.String() != "oops\n" {
+		t.Errorf("output should pass through: stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }() //nostyle:handlerrors
+	var (
+		types []string
+		got   []map[string]any
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event %q: %v", sc.Text(), err)
+		}
+		types = append(types, e["type"].(string))
+		got = append(got, e)
+	}
+	want := []string{
+		eventParse,
+		eventBlockStart, eventOutputChunk, eventBlockEnd,
+		eventBlockEnd,
+		eventBlockStart, eventOutputChunk, eventBlockEnd,
+		eventSummary,
+	}
+	if !slices.Equal(types, want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	if got[0]["file"] != "README.md" || got[0]["blocks"] != 3.0 {
+		t.Errorf("parse event = %v", got[0])
+	}
+	if got[2]["data"] != "hello\n" || got[2]["stream"] != "stdout" || got[2]["index"] != 0.0 {
+		t.Errorf("output chunk event = %v", got[2])
+	}
+	if got[4]["status"] != statusSkipped {
+		t.Errorf("skipped block event = %v", got[4])
+	}
+	if got[6]["stream"] != "stderr" || got[7]["status"] != statusFailed || got[7]["exit_code"] != 3.0 {
+		t.Errorf("failed block events = %v, %v", got[6], got[7])
+	}
+	if s := got[8]; s["passed"] != 1.0 || s["failed"] != 1.0 || s["skipped"] != 1.0 || s["error"] == nil {
+		t.Errorf("summary event = %v", s)
+	}
+}
+
+func TestOpenEventLog(t *testing.T) {
+	for _, tt := range []struct {
+		format, dest string
+	}{
+		{"json", ""},
+		{eventsNDJSON, "fd:x"},
+		{eventsNDJSON, filepath.Join(t.TempDir(), "missing", "events.ndjson")},
+	} {
+		if _, err := openEventLog(tt.format, tt.dest); err == nil {
+			t.Errorf("openEventLog(%q, %q) should return error", tt.format, tt.dest)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// maxExcerptLines is the maximum number of source lines shown for a failed block.
+const maxExcerptLines = 20
+
+// failureExcerpt returns a hook printing the Markdown source of failed blocks to w,
+// with line numbers and the headings enclosing them, so that the broken step is seen without opening the file.
+func failureExcerpt(w io.Writer, file string, source []byte) func(*runner.Result) {
+	lines := strings.Split(string(source), "\n")
+	return func(result *runner.Result) {
+		if result.Err == nil || result.Skipped {
+			return
+		}
+		block := result.Block
+		fmt.Fprintf(w, "--- Block %d failed at %s:%d", result.Index+1, file, block.Line)
+		if len(block.HeadingPath) > 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(block.HeadingPath, " > "))
+		}
+		fmt.Fprintln(w, " ---")
+		if block.Line < 1 || block.EndLine > len(lines) {
+			return
+		}
+		width := len(fmt.Sprint(block.EndLine))
+		end := min(block.EndLine, block.Line+maxExcerptLines-1)
+		for n := block.Line; n <= end; n++ {
+			fmt.Fprintf(w, "%*d | %s\n", width, n, strings.TrimSuffix(lines[n-1], "\r"))
+		}
+		if end < block.EndLine {
+			fmt.Fprintf(w, "%*s | ... (%d more lines)\n", width, "", block.EndLine-end)
+		}
+	}
+}
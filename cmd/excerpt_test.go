@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestFailureExcerpt(t *testing.T) {
+	var long strings.Builder
+	for i := range 25 {
+		fmt.Fprintf(&long, "echo %d\n", i)
+	}
+	source := []byte("# Setup\n\n## Database\n\n```sh sh\ncreatedb app\n```\n\n# Deploy\n\n```sh sh\n" + long.String() + "```\n")
+	blocks, err := parser.Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	hook := failureExcerpt(&buf, "runbook.md", source)
+	hook(&runner.Result{Index: 0, Block: blocks[0]})
+	hook(&runner.Result{Index: 0, Block: blocks[0], Skipped: true, Err: errors.New("skipped")})
+	if buf.Len() != 0 {
+		t.Errorf("excerpt of a passed or skipped block = %q", buf.String())
+	}
+
+	hook(&runner.Result{Index: 0, Block: blocks[0], Err: errors.New("exit status 1")})
+	want := "--- Block 1 failed at runbook.md:5 (Setup > Database) ---\n5 | ```sh sh\n6 | createdb app\n7 | ```\n"
+	if got := buf.String(); got != want {
+		t.Errorf("excerpt = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	hook(&runner.Result{Index: 1, Block: blocks[1], Err: errors.New("exit status 1")})
+	got := buf.String()
+	for _, w := range []string{"--- Block 2 failed at runbook.md:11 (Deploy) ---\n", "11 | ```sh sh\n", "30 | echo 18\n", "   | ... (7 more lines)\n"} {
+		if !strings.Contains(got, w) {
+			t.Errorf("excerpt does not contain %q:\n%s", w, got)
+		}
+	}
+	if strings.Contains(got, "echo 19") {
+		t.Errorf("excerpt should be truncated:\n%s", got)
+	}
+}
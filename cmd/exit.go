@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// Exit codes of the classes of failures.
+const (
+	exitCodeFailure = 1 // A block failed (or any other error)
+	exitCodeParse   = 2 // The document could not be parsed
+	exitCodePolicy  = 3 // The policy denied a block
+	exitCodeTimeout = 4 // The run timed out
+)
+
+// Exit policies selected by --exit-policy.
+const (
+	exitPolicyFirst = "first" // Stop at the first failure
+	exitPolicyAll   = "all"   // Run all blocks and fail if any of them failed
+	exitPolicyCount = "count" // Run all blocks and exit with the number of failed blocks
+)
+
+// maxExitCode is the maximum exit code used for the number of failed blocks.
+const maxExitCode = 125
+
+// exitError is an error with a specific process exit code.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// parseError is an error parsing the document.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the process exit code for the error of a command.
+// An explicit exit code (e.g., of --exit-policy count) takes precedence over the classes of failures.
+func exitCode(err error) int {
+	var (
+		e  *exitError
+		pe *parseError
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &e):
+		return e.code
+	case errors.As(err, &pe):
+		return exitCodeParse
+	case errors.Is(err, runner.ErrPolicyDenied):
+		return exitCodePolicy
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitCodeTimeout
+	default:
+		return exitCodeFailure
+	}
+}
+
+// applyExitPolicy configures r for the exit policy and returns a function
+// that converts the error of a run into the error reflecting the policy.
+func applyExitPolicy(r *runner.Runner, policy string) (func(error) error, error) {
+	switch policy {
+	case exitPolicyFirst:
+		return func(err error) error { return err }, nil
+	case exitPolicyAll:
+		r.KeepGoing = true
+		return func(err error) error { return err }, nil
+	case exitPolicyCount:
+		r.KeepGoing = true
+		failed := 0
+		addResultHook(r, func(result *runner.Result) {
+			if result.Err != nil {
+				failed++
+			}
+		})
+		return func(err error) error {
+			if err == nil || failed == 0 {
+				return err
+			}
+			return &exitError{err: err, code: min(failed, maxExitCode)}
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid --exit-policy %q: expected 'first', 'all' or 'count'", policy)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestApplyExitPolicy(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "exit 1"},
+		{Language: "sh", Command: "exit 0"},
+		{Language: "sh", Command: "exit 1"},
+	}
+
+	tests := []struct {
+		policy   string
+		wantCode int
+		wantRan  int
+	}{
+		{exitPolicyFirst, 0, 1},
+		{exitPolicyAll, 0, 3},
+		{exitPolicyCount, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &runner.Runner{Stdout: &stdout, Stderr: &stderr}
+			ran := 0
+			addResultHook(r, func(*runner.Result) { ran++ })
+			exitWith, err := applyExitPolicy(r, tt.policy)
+			if err != nil {
+				t.Fatalf("applyExitPolicy() error = %v", err)
+			}
+			err = exitWith(r.RunAll(t.Context(), blocks))
+			if err == nil {
+				t.Fatal("error should not be nil")
+			}
+			if ran != tt.wantRan {
+				t.Errorf("ran %d blocks, want %d", ran, tt.wantRan)
+			}
+			var e *exitError
+			if tt.wantCode == 0 {
+				if errors.As(err, &e) {
+					t.Errorf("unexpected exit code %d", e.code)
+				}
+				return
+			}
+			if !errors.As(err, &e) || e.code != tt.wantCode {
+				t.Errorf("exit code = %v, want %d", err, tt.wantCode)
+			}
+		})
+	}
+
+	if _, err := applyExitPolicy(&runner.Runner{}, "never"); err == nil {
+		t.Error("applyExitPolicy() should return error for an invalid policy")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"failure", errors.New("exit status 2"), exitCodeFailure},
+		{"parse", &parseError{err: errors.New("invalid document")}, exitCodeParse},
+		{"policy", fmt.Errorf("%w: rm -rf /", runner.ErrPolicyDenied), exitCodePolicy},
+		{"timeout", fmt.Errorf("%w: timed out after 1s", context.DeadlineExceeded), exitCodeTimeout},
+		{"count", &exitError{err: fmt.Errorf("%w: x", runner.ErrPolicyDenied), code: 5}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain [MARKDOWN_FILE]",
+	Short: "Explain how each code block would be executed",
+	Long: `explain shows, for each code block, which command source was chosen
+(info string, language map or default), the fully expanded command,
+the environment variables that will be added and why a block would be skipped.
+
+Nothing is executed.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := readSource(args)
+		if err != nil {
+			return err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return err
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		if err := applyDocConfig(r, source); err != nil {
+			return err
+		}
+		r.File = sourceName(args)
+		return explain(cmd.OutOrStdout(), r, blocks)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+// explain writes the resolution of each code block to w.
+// Blocks that cannot be resolved are explained with their errors, which are returned together at the end.
+func explain(w io.Writer, r *runner.Runner, blocks []parser.CodeBlock) error {
+	var errs []error
+	first := true
+	for i, block := range blocks {
+		if r.Select != nil && !r.Select(block, i) {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+		fmt.Fprintf(w, "Block %d (lang: %q)\n", i+1, block.Language)
+		res, err := r.Resolve(block, i)
+		if err != nil {
+			fmt.Fprintf(w, "  error:    %v\n", err)
+			errs = append(errs, fmt.Errorf("code block %d: %w", i+1, err))
+			continue
+		}
+		if res.Source != "" {
+			fmt.Fprintf(w, "  source:   %s\n", res.Source)
+			fmt.Fprintf(w, "  template: %s\n", res.Template)
+		}
+		if res.Skip {
+			fmt.Fprintf(w, "  skip:     %s\n", res.SkipReason)
+			continue
+		}
+		fmt.Fprintf(w, "  command:  %s\n", r.Mask(res.Command))
+		if res.Dir != "" {
+			fmt.Fprintf(w, "  dir:      %s\n", res.Dir)
+		}
+		fmt.Fprintln(w, "  env:")
+		for _, e := range res.Env {
+			fmt.Fprintf(w, "    %q\n", r.Mask(e))
+		}
+	}
+	return errors.Join(errs...)
+}
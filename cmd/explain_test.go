@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestExplain(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "go", Command: "echo {{lang}}", Content: "package main\n"},
+		{Language: "python", Content: "print(1)\n"},
+		{Language: "text", Content: "plain\n"},
+		{Language: "sh", Command: "brew install jq", Attributes: map[string]string{"os": "plan9"}},
+	}
+	r := runner.New("", map[string]string{"python": "python3"})
+
+	var buf bytes.Buffer
+	if err := explain(&buf, r, blocks); err != nil {
+		t.Fatalf("explain() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"Block 1 (lang: \"go\")",
+		"source:   info string",
+		"command:  echo go",
+		`"CODEBLOCK_INDEX=0"`,
+		"source:   language map",
+		"command:  python3",
+		"skip:     no command specified",
+		"skip:     for os=plan9 (running on ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestExplain_Error(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo {{ unknown }}"},
+		{Language: "sh", Command: "echo ok"},
+		{Language: "sh", Command: "cat {{content}}"},
+	}
+	var buf bytes.Buffer
+	err := explain(&buf, runner.New("", nil), blocks)
+	if err == nil {
+		t.Fatal("explain() should return error")
+	}
+	for _, want := range []string{"code block 1:", "code block 3:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q: %v", want, err)
+		}
+	}
+	if !errors.Is(err, runner.ErrContentInterpolation) {
+		t.Errorf("explain() error = %v, want ErrContentInterpolation", err)
+	}
+	// The blocks after an error are explained as well
+	if !strings.Contains(buf.String(), "command:  echo ok") {
+		t.Errorf("output does not contain the second block:\n%s", buf.String())
+	}
+}
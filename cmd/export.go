@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [MARKDOWN_FILE]",
+	Short: "Export named code blocks as Makefile targets, Taskfile tasks or justfile recipes",
+	Long: `export turns named code blocks (blocks with a name attribute) into
+targets/tasks with their resolved commands, so that a runbook can be
+graduated into build tooling without rewriting it.
+
+    ` + "```sh {name=build} sh" + `
+    go build ./...
+    ` + "```" + `
+
+Supported formats are make, taskfile and just.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := readSource(args)
+		if err != nil {
+			return err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return err
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		if err := applyDocConfig(r, source); err != nil {
+			return err
+		}
+		r.File = sourceName(args)
+		return export(cmd.OutOrStdout(), r, blocks, exportFormat)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "make", "export format (make|taskfile|just)")
+	rootCmd.AddCommand(exportCmd)
+}
+
+// exportTarget is a named code block with its resolved shell command line.
+type exportTarget struct {
+	name    string
+	lang    string
+	command string
+}
+
+// export writes the named blocks of blocks to w in the format.
+func export(w io.Writer, r *runner.Runner, blocks []parser.CodeBlock, format string) error {
+	var targets []exportTarget
+	seen := map[string]bool{}
+	for i, block := range blocks {
+		name := block.Name()
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate block name %q", name)
+		}
+		seen[name] = true
+		res, err := r.Resolve(block, i)
+		if err != nil {
+			return fmt.Errorf("failed to resolve block %q: %w", name, err)
+		}
+		if res.Skip {
+			continue
+		}
+		targets = append(targets, exportTarget{
+			name:    name,
+			lang:    block.Language,
+			command: pipeContent(block, res.Command),
+		})
+	}
+
+	switch format {
+	case "make":
+		names := make([]string, 0, len(targets))
+		for _, t := range targets {
+			names = append(names, makeTargetName(t.name))
+		}
+		fmt.Fprintf(w, ".PHONY: %s\n", strings.Join(names, " "))
+		for i, t := range targets {
+			fmt.Fprintf(w, "\n# %s\n%s:\n\t%s\n", t.lang, names[i], strings.ReplaceAll(t.command, "$", "$$"))
+		}
+	case "taskfile":
+		fmt.Fprint(w, "version: '3'\n\ntasks:\n")
+		for _, t := range targets {
+			name, _ := json.Marshal(t.name)       //nostyle:handlerrors
+			cmdline, _ := json.Marshal(t.command) //nostyle:handlerrors
+			fmt.Fprintf(w, "  %s:\n    desc: %s block\n    cmds:\n      - %s\n", name, t.lang, cmdline)
+		}
+	case "just":
+		for i, t := range targets {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "# %s\n%s:\n    %s\n", t.lang, makeTargetName(t.name), strings.ReplaceAll(t.command, "{{", "{{{{"))
+		}
+	default:
+		return fmt.Errorf("invalid format %q: expected 'make', 'taskfile' or 'just'", format)
+	}
+	return nil
+}
+
+// invalidTargetCharReg matches characters that are not safe in make targets and just recipe names.
+var invalidTargetCharReg = regexp.MustCompile(`[^-_.a-zA-Z0-9]+`)
+
+// makeTargetName converts a block name into a target name.
+func makeTargetName(name string) string {
+	return invalidTargetCharReg.ReplaceAllString(name, "-")
+}
+
+// pipeContent returns a single shell command line that passes the block content
+// and the env attributes of the block to command, like runblock does.
+func pipeContent(block parser.CodeBlock, command string) string {
+	var env []string
+	for k, v := range runner.BlockEnv(block) {
+		env = append(env, k+"="+runner.ShellQuote(v))
+	}
+	sort.Strings(env)
+	if len(env) > 0 {
+		command = strings.Join(env, " ") + " sh -c " + runner.ShellQuote(command)
+	}
+	if block.Content == "" {
+		return command
+	}
+	lines := strings.Split(strings.TrimSuffix(block.Content, "\n"), "\n")
+	quoted := make([]string, 0, len(lines))
+	for _, l := range lines {
+		quoted = append(quoted, runner.ShellQuote(l))
+	}
+	return fmt.Sprintf(`printf '%%s\n' %s | %s`, strings.Join(quoted, " "), command)
+}
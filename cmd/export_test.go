@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestExport(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "sh", Content: "echo $HOME\n", Attributes: map[string]string{"name": "show home"}},
+		{Language: "go", Content: "package main\n", Attributes: map[string]string{"name": "fmt"}},
+		{Language: "sh", Command: "sh", Content: "echo unnamed\n"},
+		{Language: "text", Content: "no command\n", Attributes: map[string]string{"name": "skipped"}},
+	}
+	r := runner.New("", map[string]string{"go": "gofmt"})
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "make",
+			want: `.PHONY: show-home fmt
+
+# sh
+show-home:
+	printf '%s\n' 'echo $$HOME' | sh
+
+# go
+fmt:
+	printf '%s\n' 'package main' | gofmt
+`,
+		},
+		{
+			format: "taskfile",
+			want: `version: '3'
+
+tasks:
+  "show home":
+    desc: sh block
+    cmds:
+      - "printf '%s\\n' 'echo $HOME' | sh"
+  "fmt":
+    desc: go block
+    cmds:
+      - "printf '%s\\n' 'package main' | gofmt"
+`,
+		},
+		{
+			format: "just",
+			want: `# sh
+show-home:
+    printf '%s\n' 'echo $HOME' | sh
+
+# go
+fmt:
+    printf '%s\n' 'package main' | gofmt
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := export(&buf, r, blocks, tt.format); err != nil {
+				t.Fatalf("export() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("export() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExport_DuplicateName(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "sh", Attributes: map[string]string{"name": "a"}},
+		{Language: "sh", Command: "sh", Attributes: map[string]string{"name": "a"}},
+	}
+	var buf bytes.Buffer
+	if err := export(&buf, runner.New("", nil), blocks, "make"); err == nil {
+		t.Error("export() should return error for duplicate names")
+	}
+}
+
+func TestPipeContent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	block := parser.CodeBlock{
+		Language:   "sh",
+		Content:    "echo \"$GREETING\" 'it''s'\n",
+		Attributes: map[string]string{"env.GREETING": "hello world"},
+	}
+	line := pipeContent(block, "sh")
+	out, err := exec.Command("sh", "-c", line).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run %q: %v: %s", line, err, out)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hello world its" {
+		t.Errorf("output = %q, want %q", got, "hello world its")
+	}
+}
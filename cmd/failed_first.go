@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+var watchFailedFirst bool
+
+func init() {
+	rootCmd.Flags().BoolVar(&watchFailedFirst, "failed-first", false,
+		"in watch mode, re-run only the blocks that failed or did not run until all of them pass")
+}
+
+// failedFirst tracks the blocks that passed across runs in watch mode,
+// so that re-runs focus on the blocks that failed or did not run.
+type failedFirst struct {
+	n      int          // Number of blocks in the document
+	passed map[int]bool // Indices of the blocks passed since all blocks last passed
+}
+
+// apply makes r run only the blocks that have not passed yet and records the blocks that pass.
+func (f *failedFirst) apply(w io.Writer, r *runner.Runner, blocks []parser.CodeBlock) {
+	// Indices are meaningless once blocks are added or removed
+	if f.passed == nil || len(blocks) != f.n {
+		f.n = len(blocks)
+		f.passed = map[int]bool{}
+	}
+	if len(f.passed) > 0 {
+		fmt.Fprintf(w, "Skipping %d block(s) passed in previous runs\n", len(f.passed))
+	}
+	sel := r.Select
+	r.Select = func(block parser.CodeBlock, i int) bool {
+		return !f.passed[i] && (sel == nil || sel(block, i))
+	}
+	addResultHook(r, func(result *runner.Result) {
+		if result.Err == nil {
+			f.passed[result.Index] = true
+		}
+	})
+}
+
+// finish ends a run; once a run succeeds, the next run starts from the first block again.
+func (f *failedFirst) finish(err error) {
+	if err == nil {
+		f.passed = nil
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestFailedFirst(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	flag := filepath.Join(dir, "fixed")
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo 1 >> " + log},
+		{Language: "sh", Command: "echo 2 >> " + log + " && test -e " + flag},
+		{Language: "sh", Command: "echo 3 >> " + log},
+	}
+
+	ff := &failedFirst{}
+	run := func() error {
+		r := &runner.Runner{Stdout: io.Discard, Stderr: io.Discard}
+		var out bytes.Buffer
+		ff.apply(&out, r, blocks)
+		err := r.RunAll(t.Context(), blocks)
+		ff.finish(err)
+		return err
+	}
+	readLog := func() string {
+		t.Helper()
+		b, err := os.ReadFile(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = os.Remove(log) //nostyle:handlerrors
+		return string(b)
+	}
+
+	if err := run(); err == nil {
+		t.Fatal("first run should fail")
+	}
+	if got, want := readLog(), "1\n2\n"; got != want {
+		t.Errorf("first run: got %q, want %q", got, want)
+	}
+
+	// Still failing: the passed block is not replayed
+	if err := run(); err == nil {
+		t.Fatal("second run should fail")
+	}
+	if got, want := readLog(), "2\n"; got != want {
+		t.Errorf("second run: got %q, want %q", got, want)
+	}
+
+	// Fixed: the failed block and the blocks that did not run are run
+	if err := os.WriteFile(flag, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(); err != nil {
+		t.Fatalf("third run error = %v", err)
+	}
+	if got, want := readLog(), "2\n3\n"; got != want {
+		t.Errorf("third run: got %q, want %q", got, want)
+	}
+
+	// All passed: all blocks are run again
+	if err := run(); err != nil {
+		t.Fatalf("fourth run error = %v", err)
+	}
+	if got, want := readLog(), "1\n2\n3\n"; got != want {
+		t.Errorf("fourth run: got %q, want %q", got, want)
+	}
+}
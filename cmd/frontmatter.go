@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"go.yaml.in/yaml/v3"
+)
+
+// docConfig is the runblock section of the front matter of a document, configuring the runs of the document:
+//
+//	---
+//	runblock:
+//	  default_command: sh
+//	  env:
+//	    API_URL: http://localhost:8080
+//	  tags: [docs]
+//	  inputs:
+//	    - name: cluster
+//	      prompt: Enter the cluster name
+//	      default: staging
+//	---
+type docConfig struct {
+	DefaultCommand string            `yaml:"default_command"` // Used unless --default-command is given
+	Env            map[string]string `yaml:"env"`             // Added to every block unless it sets NAME with env.NAME or env
+	Tags           []string          `yaml:"tags"`            // Added to the tags of every block
+	Inputs         []docInput        `yaml:"inputs"`          // Asked before the run and available as {{vars.NAME}}
+}
+
+// readDocConfig reads the runblock section of the front matter of decoded source (empty if there is none).
+// Front matter that is not a YAML mapping is left alone, since it may not be meant for runblock.
+func readDocConfig(source []byte) (*docConfig, error) {
+	cfg := &docConfig{}
+	fm, _ := parser.FrontMatter(source)
+	var sections map[string]yaml.Node
+	if err := yaml.Unmarshal(fm, &sections); err != nil {
+		return cfg, nil
+	}
+	section, ok := sections["runblock"]
+	if !ok {
+		return cfg, nil
+	}
+	b, err := yaml.Marshal(&section)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("invalid runblock section in the front matter: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyBlocks adds the env and the tags of the document to its code blocks.
+func (cfg *docConfig) applyBlocks(blocks []parser.CodeBlock) {
+	if len(cfg.Env) == 0 && len(cfg.Tags) == 0 {
+		return
+	}
+	for i := range blocks {
+		attrs := maps.Clone(blocks[i].Attributes)
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		env := runner.BlockEnv(blocks[i])
+		for name, v := range cfg.Env {
+			if _, ok := env[name]; !ok {
+				attrs["env."+name] = v
+			}
+		}
+		if len(cfg.Tags) > 0 {
+			tags := blocks[i].Tags()
+			for _, t := range cfg.Tags {
+				if !slices.Contains(tags, t) {
+					tags = append(tags, t)
+				}
+			}
+			attrs[parser.AttrTags] = strings.Join(tags, ",")
+		}
+		blocks[i].Attributes = attrs
+	}
+}
+
+// applyDocConfig configures r with the front matter of the document source.
+// Flags take precedence over the front matter.
+func applyDocConfig(r *runner.Runner, source []byte) error {
+	source, err := decodeSource(source, encoding)
+	if err != nil {
+		return &parseError{err: err}
+	}
+	cfg, err := readDocConfig(source)
+	if err != nil {
+		return &parseError{err: err}
+	}
+	r.DefaultCommand = defaultCommand
+	if r.DefaultCommand == "" {
+		r.DefaultCommand = cfg.DefaultCommand
+	}
+	return nil
+}
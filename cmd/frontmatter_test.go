@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestReadDocConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{"section", "---\ntitle: x\nrunblock:\n  default_command: sh\n---\n", "sh", false},
+		{"no section", "---\ntitle: x\n---\n", "", false},
+		{"no front matter", "# Doc\n", "", false},
+		{"not a mapping", "---\nSome text\n---\n", "", false},
+		{"unknown field", "---\nrunblock:\n  session: true\n---\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := readDocConfig([]byte(tt.source))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.DefaultCommand != tt.want {
+				t.Errorf("got default command %q, want %q", cfg.DefaultCommand, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlocks_FrontMatter(t *testing.T) {
+	source := "---\nrunblock:\n  env:\n    FOO: doc\n    BAR: doc\n  tags: [docs, slow]\n---\n\n```sh {env.FOO=block tags=slow,ci}\n```\n\n```sh\n```\n"
+	blocks, err := parseBlocks([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(blocks))
+	}
+	tests := []struct {
+		foo, bar, tags string
+	}{
+		{"block", "doc", "slow,ci,docs"},
+		{"doc", "doc", "docs,slow"},
+	}
+	for i, tt := range tests {
+		attrs := blocks[i].Attributes
+		if attrs["env.FOO"] != tt.foo || attrs["env.BAR"] != tt.bar || attrs["tags"] != tt.tags {
+			t.Errorf("block %d: got env.FOO=%q env.BAR=%q tags=%q, want %q %q %q", i+1, attrs["env.FOO"], attrs["env.BAR"], attrs["tags"], tt.foo, tt.bar, tt.tags)
+		}
+	}
+}
+
+func TestParseBlocks_FrontMatterEnvList(t *testing.T) {
+	source := "---\nrunblock:\n  env:\n    FOO: doc\n    BAR: doc\n---\n\n```sh {env=\"FOO=block\"}\n```\n"
+	blocks, err := parseBlocks([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"FOO": "block", "BAR": "doc"}
+	if got := runner.BlockEnv(blocks[0]); !maps.Equal(got, want) {
+		t.Errorf("BlockEnv() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyDocConfig(t *testing.T) {
+	source := []byte("---\nrunblock:\n  default_command: sh\n---\n")
+	t.Cleanup(func() { defaultCommand = "" })
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"", "sh"},
+		{"bash", "bash"},
+	}
+	for _, tt := range tests {
+		defaultCommand = tt.flag
+		r := runner.New(defaultCommand, nil)
+		if err := applyDocConfig(r, source); err != nil {
+			t.Fatal(err)
+		}
+		if r.DefaultCommand != tt.want {
+			t.Errorf("with --default-command %q: got %q, want %q", tt.flag, r.DefaultCommand, tt.want)
+		}
+	}
+}
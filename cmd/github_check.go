@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	githubCheck     bool
+	githubCheckName string
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&githubCheck, "github-check", false,
+		"create a GitHub Check Run with annotations for failed blocks (requires GITHUB_TOKEN, GITHUB_REPOSITORY and GITHUB_SHA)")
+	rootCmd.Flags().StringVar(&githubCheckName, "github-check-name", "runblock",
+		"name of the GitHub Check Run")
+}
+
+// maxAnnotations is the maximum number of annotations GitHub accepts per request.
+const maxAnnotations = 50
+
+// checkRunConfig is the configuration of the GitHub Check Run reporter.
+type checkRunConfig struct {
+	apiURL string
+	token  string
+	repo   string
+	sha    string
+	name   string
+	path   string // Path of the document relative to the repository root
+}
+
+// newCheckRunConfig returns the configuration of the GitHub Check Run reporter from the environment.
+func newCheckRunConfig(args []string) (*checkRunConfig, error) {
+	if len(args) == 0 {
+		return nil, errors.New("--github-check requires a file argument")
+	}
+	c := &checkRunConfig{
+		apiURL: os.Getenv("GITHUB_API_URL"),
+		token:  os.Getenv("GITHUB_TOKEN"),
+		repo:   os.Getenv("GITHUB_REPOSITORY"),
+		sha:    os.Getenv("GITHUB_SHA"),
+		name:   githubCheckName,
+		path:   filepath.ToSlash(filepath.Clean(args[0])),
+	}
+	if c.apiURL == "" {
+		c.apiURL = "https://api.github.com"
+	}
+	var missing []string
+	for _, v := range []struct{ name, value string }{
+		{"GITHUB_TOKEN", c.token},
+		{"GITHUB_REPOSITORY", c.repo},
+		{"GITHUB_SHA", c.sha},
+	} {
+		if v.value == "" {
+			missing = append(missing, v.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("--github-check requires %s", strings.Join(missing, ", "))
+	}
+	return c, nil
+}
+
+// checkRun is the payload creating a GitHub Check Run.
+type checkRun struct {
+	Name        string         `json:"name"`
+	HeadSHA     string         `json:"head_sha"`
+	Status      string         `json:"status"`
+	Conclusion  string         `json:"conclusion"`
+	CompletedAt time.Time      `json:"completed_at"`
+	Output      checkRunOutput `json:"output"`
+}
+
+// checkRunOutput is the output of a GitHub Check Run.
+type checkRunOutput struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Annotations []annotation `json:"annotations,omitempty"`
+}
+
+// annotation is an annotation of a GitHub Check Run.
+type annotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title"`
+	Message         string `json:"message"`
+}
+
+// newCheckRun builds the Check Run payload from a report.
+func newCheckRun(c *checkRunConfig, rp *report) checkRun {
+	passed, failed, skipped := rp.Count(statusPassed), rp.Count(statusFailed), rp.Count(statusSkipped)
+	run := checkRun{
+		Name:        c.name,
+		HeadSHA:     c.sha,
+		Status:      "completed",
+		Conclusion:  "success",
+		CompletedAt: rp.StartedAt.Add(rp.Duration),
+		Output: checkRunOutput{
+			Title:   fmt.Sprintf("%d passed, %d failed, %d skipped", passed, failed, skipped),
+			Summary: fmt.Sprintf("runblock ran `%s` in %s.", c.path, rp.Duration.Round(time.Millisecond)),
+		},
+	}
+	if failed > 0 {
+		run.Conclusion = "failure"
+	}
+	for _, b := range rp.Blocks {
+		if b.Status != statusFailed || b.Line == 0 {
+			continue
+		}
+		if len(run.Output.Annotations) == maxAnnotations {
+			run.Output.Summary += fmt.Sprintf(" Only the first %d failures are annotated.", maxAnnotations)
+			break
+		}
+		title := fmt.Sprintf("Block %d failed", b.Index+1)
+		if b.Name != "" {
+			title = fmt.Sprintf("Block %d (%s) failed", b.Index+1, b.Name)
+		}
+		msg := b.Error
+		if out := tail(b.Stderr+b.Stdout, maxSnippetLen); out != "" {
+			msg += "\n\n" + out
+		}
+		run.Output.Annotations = append(run.Output.Annotations, annotation{
+			Path:            c.path,
+			StartLine:       b.Line,
+			EndLine:         max(b.EndLine, b.Line),
+			AnnotationLevel: "failure",
+			Title:           title,
+			Message:         msg,
+		})
+	}
+	return run
+}
+
+// createCheckRun creates a GitHub Check Run from the report.
+func createCheckRun(ctx context.Context, c *checkRunConfig, rp *report) error {
+	b, err := json.Marshal(newCheckRun(c, rp))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	url := fmt.Sprintf("%s/repos/%s/check-runs", strings.TrimSuffix(c.apiURL, "/"), c.repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create check run request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nostyle:handlerrors
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to create check run: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCheckRunConfig(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_REPOSITORY", "k1LoW/runblock")
+	t.Setenv("GITHUB_SHA", "abc")
+	if _, err := newCheckRunConfig([]string{"README.md"}); err == nil {
+		t.Error("newCheckRunConfig() should return error without GITHUB_TOKEN")
+	}
+	t.Setenv("GITHUB_TOKEN", "token")
+	if _, err := newCheckRunConfig(nil); err == nil {
+		t.Error("newCheckRunConfig() should return error for stdin")
+	}
+	c, err := newCheckRunConfig([]string{"./docs/../README.md"})
+	if err != nil {
+		t.Fatalf("newCheckRunConfig() error = %v", err)
+	}
+	if c.path != "README.md" || c.apiURL != "https://api.github.com" {
+		t.Errorf("unexpected config: %+v", c)
+	}
+}
+
+func TestCreateCheckRun(t *testing.T) {
+	var got checkRun
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/k1LoW/runblock/check-runs" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	c := &checkRunConfig{apiURL: ts.URL, token: "token", repo: "k1LoW/runblock", sha: "abc", name: "docs", path: "runbook.md"}
+	rp := newTestReport()
+	rp.Blocks[1].Line = 10
+	rp.Blocks[1].EndLine = 12
+	if err := createCheckRun(t.Context(), c, rp); err != nil {
+		t.Fatalf("createCheckRun() error = %v", err)
+	}
+
+	if got.Name != "docs" || got.HeadSHA != "abc" || got.Conclusion != "failure" {
+		t.Errorf("unexpected check run: %+v", got)
+	}
+	if len(got.Output.Annotations) != 1 {
+		t.Fatalf("got %d annotations, want 1", len(got.Output.Annotations))
+	}
+	a := got.Output.Annotations[0]
+	if a.Path != "runbook.md" || a.StartLine != 10 || a.EndLine != 12 || a.Message != "exit status 1\n\noops\n" {
+		t.Errorf("unexpected annotation: %+v", a)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph [MARKDOWN_FILE]",
+	Short: "Render the run order of the code blocks as a graph",
+	Long: `graph renders the order the code blocks run in as a Graphviz (dot) or Mermaid graph,
+so that authors can verify the ordering of a runbook visually.
+
+Blocks are grouped by stage (setup, main, teardown). A solid edge means the block runs
+only if the previous one succeeded; a dashed edge means it runs regardless (teardown and always=true).
+Skipped blocks are not shown.
+
+    runblock graph runbook.md | dot -Tsvg > runbook.svg`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := readSource(args)
+		if err != nil {
+			return err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return err
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		if err := applyDocConfig(r, source); err != nil {
+			return err
+		}
+		r.File = sourceName(args)
+		p, err := newPlan(r, sourceName(args), source, blocks)
+		if err != nil {
+			return err
+		}
+		return graph(cmd.OutOrStdout(), p, blocks, graphFormat)
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "graph format (dot|mermaid)")
+	rootCmd.AddCommand(graphCmd)
+}
+
+// graphEdge is an edge between code blocks in the run order.
+type graphEdge struct {
+	from, to int
+	always   bool // The block runs even if the previous one failed
+}
+
+// graph writes the run order of the steps of p as a graph to w in the format.
+func graph(w io.Writer, p *executionPlan, blocks []parser.CodeBlock, format string) error {
+	var steps []planStep
+	for _, step := range p.Steps {
+		if !step.Skip {
+			steps = append(steps, step)
+		}
+	}
+	var edges []graphEdge
+	for i := 1; i < len(steps); i++ {
+		edges = append(edges, graphEdge{from: steps[i-1].Index, to: steps[i].Index, always: steps[i].Always})
+	}
+	label := func(step planStep) string {
+		if name := blocks[step.Index].Name(); name != "" {
+			return fmt.Sprintf("%s (line %d)", name, step.Line)
+		}
+		return fmt.Sprintf("Block %d: %s (line %d)", step.Index+1, step.Lang, step.Line)
+	}
+
+	switch format {
+	case "dot":
+		fmt.Fprintln(w, "digraph runblock {")
+		forEachStage(steps, func(stage string, steps []planStep) {
+			fmt.Fprintf(w, "  subgraph cluster_%s {\n    label=%q;\n", stage, stage)
+			for _, step := range steps {
+				fmt.Fprintf(w, "    b%d [label=%q];\n", step.Index, label(step))
+			}
+			fmt.Fprintln(w, "  }")
+		})
+		for _, e := range edges {
+			style := ""
+			if e.always {
+				style = " [style=dashed]"
+			}
+			fmt.Fprintf(w, "  b%d -> b%d%s;\n", e.from, e.to, style)
+		}
+		fmt.Fprintln(w, "}")
+	case "mermaid":
+		fmt.Fprintln(w, "flowchart TD")
+		forEachStage(steps, func(stage string, steps []planStep) {
+			fmt.Fprintf(w, "  subgraph %s\n", stage)
+			for _, step := range steps {
+				fmt.Fprintf(w, "    b%d[\"%s\"]\n", step.Index, strings.ReplaceAll(label(step), `"`, "#quot;"))
+			}
+			fmt.Fprintln(w, "  end")
+		})
+		for _, e := range edges {
+			arrow := "-->"
+			if e.always {
+				arrow = "-.->"
+			}
+			fmt.Fprintf(w, "  b%d %s b%d\n", e.from, arrow, e.to)
+		}
+	default:
+		return fmt.Errorf("invalid --format %q: expected 'dot' or 'mermaid'", format)
+	}
+	return nil
+}
+
+// forEachStage calls fn with each stage and its steps in the run order.
+func forEachStage(steps []planStep, fn func(stage string, steps []planStep)) {
+	for _, stage := range []string{runner.StageSetup, runner.StageMain, runner.StageTeardown} {
+		var ss []planStep
+		for _, step := range steps {
+			if step.Stage == stage {
+				ss = append(ss, step)
+			}
+		}
+		if len(ss) > 0 {
+			fn(stage, ss)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestGraph(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo bye", Attributes: map[string]string{"stage": "teardown"}},
+		{Language: "sh", Command: "echo hello"},
+		{Language: "text", Content: "plain\n"},
+		{Language: "sh", Command: "echo world"},
+	}
+	p, err := newPlan(runner.New("", nil), "runbook.md", []byte("source"), blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"dot", []string{"subgraph cluster_main {", "subgraph cluster_teardown {", "b1 -> b3;", "b3 -> b0 [style=dashed];"}},
+		{"mermaid", []string{"flowchart TD", "subgraph teardown", "b1 --> b3", "b3 -.-> b0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := graph(buf, p, blocks, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("graph does not contain %q:\n%s", w, got)
+				}
+			}
+			if strings.Contains(got, "b2") {
+				t.Errorf("skipped block should not be shown:\n%s", got)
+			}
+		})
+	}
+
+	if err := graph(new(bytes.Buffer), p, blocks, "svg"); err == nil {
+		t.Error("expected error for invalid format")
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// CI formats of log groups.
+const (
+	ciAuto      = "auto"      // Detect the CI from the environment
+	ciGitHub    = "github"    // GitHub Actions ::group::
+	ciGitLab    = "gitlab"    // GitLab CI section_start/section_end
+	ciBuildkite = "buildkite" // Buildkite ---/+++ groups
+	ciNone      = "none"      // No log groups
+)
+
+var (
+	groupOutput   bool
+	silentSuccess bool
+	ciFormatName  string
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&groupOutput, "group-output", false,
+		"wrap the output of each block in a collapsible log group of the CI (see --ci-format)")
+	rootCmd.Flags().BoolVar(&silentSuccess, "silent-success", false,
+		"collapse the output of successful blocks and expand failed ones in CI log groups (outside CI, hide the output of successful blocks)")
+	rootCmd.Flags().StringVar(&ciFormatName, "ci-format", ciAuto,
+		"format of log groups (auto: detect from the environment, github, gitlab, buildkite, none)")
+}
+
+// ciFormat writes the markers of collapsible log groups of a CI system.
+type ciFormat interface {
+	// begin returns the line opening a group, or "" if the CI cannot open the group as requested.
+	begin(name, title string, collapsed bool) string
+	// end returns the line closing a group, or "" if groups are closed implicitly.
+	end(name string, failed bool) string
+}
+
+// newCIFormat returns the CI format of the name (nil for none, or auto outside a known CI).
+func newCIFormat(name string) (ciFormat, error) {
+	if name == ciAuto {
+		switch {
+		case os.Getenv("GITHUB_ACTIONS") == "true":
+			name = ciGitHub
+		case os.Getenv("GITLAB_CI") == "true":
+			name = ciGitLab
+		case os.Getenv("BUILDKITE") == "true":
+			name = ciBuildkite
+		default:
+			name = ciNone
+		}
+	}
+	switch name {
+	case ciGitHub:
+		return githubGroups{}, nil
+	case ciGitLab:
+		return gitlabSections{now: time.Now}, nil
+	case ciBuildkite:
+		return buildkiteGroups{}, nil
+	case ciNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid --ci-format %q: expected 'auto', 'github', 'gitlab', 'buildkite' or 'none'", name)
+	}
+}
+
+// githubGroups are the log groups of GitHub Actions. They are always collapsed.
+type githubGroups struct{}
+
+func (githubGroups) begin(_, title string, collapsed bool) string {
+	if !collapsed {
+		return ""
+	}
+	return "::group::" + title
+}
+
+func (githubGroups) end(string, bool) string {
+	return "::endgroup::"
+}
+
+// gitlabSections are the collapsible sections of GitLab CI.
+type gitlabSections struct {
+	now func() time.Time
+}
+
+func (g gitlabSections) begin(name, title string, collapsed bool) string {
+	return fmt.Sprintf("section_start:%d:%s[collapsed=%t]\r\x1b[0K%s", g.now().Unix(), name, collapsed, title)
+}
+
+func (g gitlabSections) end(name string, _ bool) string {
+	return fmt.Sprintf("section_end:%d:%s\r\x1b[0K", g.now().Unix(), name)
+}
+
+// buildkiteGroups are the log groups of Buildkite. A group lasts until the next one,
+// and "^^^ +++" expands the group of a failed block.
+type buildkiteGroups struct{}
+
+func (buildkiteGroups) begin(_, title string, collapsed bool) string {
+	if collapsed {
+		return "--- " + title
+	}
+	return "+++ " + title
+}
+
+func (buildkiteGroups) end(_ string, failed bool) string {
+	if failed {
+		return "^^^ +++"
+	}
+	return ""
+}
+
+// heldOutput is output of a code block held back until the block finishes.
+type heldOutput struct {
+	w io.Writer
+	b []byte
+}
+
+// outputGroups wraps the output of each code block in a collapsible log group of the CI.
+// With silent, the output is held back until the block finishes: the output of a successful block
+// is collapsed into a group (or dropped outside CI), and the output of a failed block is expanded.
+type outputGroups struct {
+	mu      sync.Mutex
+	w       io.Writer // Writer of the group markers
+	ci      ciFormat  // nil outside CI
+	silent  bool
+	running bool
+	held    []heldOutput
+	midLine bool // Whether the last output did not end with a newline
+}
+
+// newOutputGroups returns output groups writing the group markers to w.
+func newOutputGroups(w io.Writer, ci ciFormat, silent bool) *outputGroups {
+	return &outputGroups{w: w, ci: ci, silent: silent}
+}
+
+// attach groups the output of the code blocks run by r.
+func (g *outputGroups) attach(r *runner.Runner) {
+	r.Stdout = g.wrap(r.Stdout)
+	r.Stderr = g.wrap(r.Stderr)
+	addStartHook(r, g.start)
+	addResultHook(r, g.finish)
+}
+
+// wrap returns a writer writing to w, or holding the output back while a block runs with silent.
+func (g *outputGroups) wrap(w io.Writer) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.silent && g.running {
+			g.held = append(g.held, heldOutput{w: w, b: bytes.Clone(b)})
+			return len(b), nil
+		}
+		return g.write(w, b)
+	})
+}
+
+// start opens the group of a code block.
+func (g *outputGroups) start(result *runner.Result) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.running = true
+	if g.ci != nil && !g.silent {
+		g.begin(result, true)
+	}
+}
+
+// finish closes the group of a code block, or writes the held output of the block.
+func (g *outputGroups) finish(result *runner.Result) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.running {
+		// The block was not started (e.g., skipped)
+		return
+	}
+	g.running = false
+	held := g.held
+	g.held = nil
+	failed := result.Err != nil
+	switch {
+	case g.ci == nil:
+		if failed {
+			g.replay(held)
+		}
+	case !g.silent:
+		g.end(result, failed)
+	case g.begin(result, !failed):
+		g.replay(held)
+		g.end(result, false)
+	default:
+		// The CI cannot expand the group of a failed block
+		g.replay(held)
+	}
+}
+
+// groupName returns the name of the group of a code block.
+func groupName(result *runner.Result) string {
+	return fmt.Sprintf("runblock_block_%d", result.Index+1)
+}
+
+// begin writes the marker opening the group of a code block and reports whether it was written.
+func (g *outputGroups) begin(result *runner.Result, collapsed bool) bool {
+	title := fmt.Sprintf("Block %d (%s): %s", result.Index+1, result.Block.Language, summarizeCommand(result.Command))
+	line := g.ci.begin(groupName(result), title, collapsed)
+	if line == "" {
+		return false
+	}
+	g.marker(line)
+	return true
+}
+
+// end writes the marker closing the group of a code block.
+func (g *outputGroups) end(result *runner.Result, failed bool) {
+	g.marker(g.ci.end(groupName(result), failed))
+}
+
+// marker writes a group marker on its own line ("" writes nothing).
+func (g *outputGroups) marker(line string) {
+	if line == "" {
+		return
+	}
+	if g.midLine {
+		line = "\n" + line
+	}
+	_, _ = g.write(g.w, []byte(line+"\n")) //nostyle:handlerrors
+}
+
+// replay writes the held output.
+func (g *outputGroups) replay(held []heldOutput) {
+	for _, h := range held {
+		_, _ = g.write(h.w, h.b) //nostyle:handlerrors
+	}
+}
+
+// write writes b to w and records whether it ended in the middle of a line.
+func (g *outputGroups) write(w io.Writer, b []byte) (int, error) {
+	if len(b) > 0 {
+		g.midLine = b[len(b)-1] != '\n'
+	}
+	return w.Write(b)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestOutputGroups(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "printf ok"},
+		{Language: "sh", Command: "echo ng; exit 1"},
+	}
+
+	tests := []struct {
+		name   string
+		ci     ciFormat
+		silent bool
+		want   string
+	}{
+		{
+			name: "groups",
+			ci:   githubGroups{},
+			want: "::group::Block 1 (sh): printf ok\nok\n::endgroup::\n" +
+				"::group::Block 2 (sh): echo ng; exit 1\nng\n::endgroup::\n",
+		},
+		{
+			name:   "silent success",
+			ci:     githubGroups{},
+			silent: true,
+			want:   "::group::Block 1 (sh): printf ok\nok\n::endgroup::\nng\n",
+		},
+		{
+			name:   "gitlab",
+			ci:     gitlabSections{now: func() time.Time { return time.Unix(1700000000, 0) }},
+			silent: true,
+			want: "section_start:1700000000:runblock_block_1[collapsed=true]\r\x1b[0KBlock 1 (sh): printf ok\nok\n" +
+				"section_end:1700000000:runblock_block_1\r\x1b[0K\n" +
+				"section_start:1700000000:runblock_block_2[collapsed=false]\r\x1b[0KBlock 2 (sh): echo ng; exit 1\nng\n" +
+				"section_end:1700000000:runblock_block_2\r\x1b[0K\n",
+		},
+		{
+			name: "buildkite",
+			ci:   buildkiteGroups{},
+			want: "--- Block 1 (sh): printf ok\nok\n--- Block 2 (sh): echo ng; exit 1\nng\n^^^ +++\n",
+		},
+		{
+			name:   "buildkite silent success",
+			ci:     buildkiteGroups{},
+			silent: true,
+			want:   "--- Block 1 (sh): printf ok\nok\n+++ Block 2 (sh): echo ng; exit 1\nng\n",
+		},
+		{
+			name:   "silent success outside CI",
+			silent: true,
+			want:   "ng\n",
+		},
+		{
+			name: "outside CI",
+			want: "okng\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			r := &runner.Runner{Stdout: &stdout, Stderr: &stdout, KeepGoing: true}
+			newOutputGroups(&stdout, tt.ci, tt.silent).attach(r)
+			if err := r.RunAll(t.Context(), blocks); err == nil {
+				t.Fatal("RunAll() should return error")
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCIFormat(t *testing.T) {
+	tests := []struct {
+		env  string
+		want ciFormat
+	}{
+		{"GITHUB_ACTIONS", githubGroups{}},
+		{"GITLAB_CI", gitlabSections{}},
+		{"BUILDKITE", buildkiteGroups{}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			for _, env := range []string{"GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE"} {
+				t.Setenv(env, "")
+			}
+			if tt.env != "" {
+				t.Setenv(tt.env, "true")
+			}
+			got, err := newCIFormat(ciAuto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", tt.want) {
+				t.Errorf("newCIFormat() = %T, want %T", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := newCIFormat("jenkins"); err == nil {
+		t.Error("newCIFormat() should return error for an unsupported format")
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+var heartbeatInterval time.Duration
+
+func init() {
+	rootCmd.Flags().DurationVar(&heartbeatInterval, "heartbeat", 0,
+		"print a notice on stderr when a block produces no output for the interval (e.g., 30s)")
+}
+
+// heartbeat prints a notice when the running block is silent for the interval,
+// so that CI systems do not kill the job as stalled.
+type heartbeat struct {
+	mu       sync.Mutex
+	w        io.Writer
+	interval time.Duration
+	started  time.Time
+	last     time.Time // Last output or notice
+	stop     chan struct{}
+}
+
+// newHeartbeat returns a heartbeat writing notices to w.
+func newHeartbeat(w io.Writer, interval time.Duration) *heartbeat {
+	return &heartbeat{w: w, interval: interval}
+}
+
+// attach watches the output of the code blocks run by r.
+func (h *heartbeat) attach(r *runner.Runner) {
+	r.Stdout = h.wrap(r.Stdout)
+	r.Stderr = h.wrap(r.Stderr)
+	addStartHook(r, h.start)
+	addResultHook(r, h.finish)
+}
+
+// wrap returns a writer recording the time of the output written to w.
+func (h *heartbeat) wrap(w io.Writer) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		h.mu.Lock()
+		h.last = time.Now()
+		h.mu.Unlock()
+		return w.Write(b)
+	})
+}
+
+// start starts watching a code block.
+func (h *heartbeat) start(result *runner.Result) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.started = time.Now()
+	h.last = h.started
+	h.stop = make(chan struct{})
+	go h.watch(h.stop, fmt.Sprintf("block %d (%s)", result.Index+1, result.Block.Language))
+}
+
+// finish stops watching the code block.
+func (h *heartbeat) finish(_ *runner.Result) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+	}
+}
+
+// watch prints notices until stop is closed.
+func (h *heartbeat) watch(stop <-chan struct{}, block string) {
+	timer := time.NewTimer(h.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+		h.mu.Lock()
+		now := time.Now()
+		wait := h.interval - now.Sub(h.last)
+		if wait <= 0 {
+			fmt.Fprintf(h.w, "%s still running (%s)\n", block, now.Sub(h.started).Round(time.Second))
+			h.last = now
+			wait = h.interval
+		}
+		h.mu.Unlock()
+		timer.Reset(wait)
+	}
+}
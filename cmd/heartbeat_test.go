@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestHeartbeat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	var notices syncBuffer
+	var stdout bytes.Buffer
+	r := &runner.Runner{Stdout: &stdout, Stderr: &stdout}
+	newHeartbeat(&notices, 100*time.Millisecond).attach(r)
+	blocks := []parser.CodeBlock{
+		// Output keeps the block from being silent
+		{Language: "sh", Command: "for i in 1 2 3 4 5; do echo $i; sleep 0.05; done"},
+		{Language: "bash", Command: "sleep 0.35"},
+	}
+	if err := r.RunAll(t.Context(), blocks); err != nil {
+		t.Fatal(err)
+	}
+	got := notices.String()
+	if strings.Contains(got, "block 1") {
+		t.Errorf("block with output should not have notices:\n%s", got)
+	}
+	if n := strings.Count(got, "block 2 (bash) still running ("); n < 2 || n > 3 {
+		t.Errorf("got %d notices for the silent block, want 2-3:\n%s", n, got)
+	}
+
+	// No notices after the blocks finished
+	time.Sleep(200 * time.Millisecond)
+	if notices.String() != got {
+		t.Errorf("notices after the run:\n%s", notices.String())
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// historyPath is the file the run history is stored in, relative to the working directory.
+var historyPath = filepath.Join(".runblock", "history.json")
+
+// maxRuns is the number of runs kept in the history.
+const maxRuns = 100
+
+var (
+	noHistory    bool
+	historyLimit int
+	rerunLast    bool
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the recent runs in the working directory",
+	Long: `history shows the recent runs of documents in the working directory, newest first,
+with their IDs for 'runblock rerun'. Runs reading the document from stdin are not recorded.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		h, err := loadHistory(historyPath)
+		if err != nil {
+			return err
+		}
+		return writeHistory(cmd.OutOrStdout(), h, historyLimit)
+	},
+}
+
+// rerunCmd represents the rerun command
+var rerunCmd = &cobra.Command{
+	Use:   "rerun [ID | --last]",
+	Short: "Repeat a run from the history",
+	Long: `rerun repeats a run recorded in the history with the same file, flags, filters and --var inputs.
+Inputs answered on the terminal are asked again. The exit status is that of the repeated run.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (len(args) == 0) == !rerunLast {
+			return errors.New("specify either the ID of a run shown by 'runblock history' or --last")
+		}
+		h, err := loadHistory(historyPath)
+		if err != nil {
+			return err
+		}
+		var e *historyEntry
+		if rerunLast {
+			e, err = h.last()
+		} else {
+			e, err = h.find(args[0])
+		}
+		if err != nil {
+			return err
+		}
+		// The repeated run prints its own errors
+		cmd.SilenceUsage = true
+		fmt.Fprintf(cmd.ErrOrStderr(), "Rerunning #%d: runblock %s\n", e.ID, e.commandLine())
+		return rerun(cmd.Context(), e)
+	},
+}
+
+func init() {
+	rootCmd.Flags().BoolVar(&noHistory, "no-history", false,
+		"do not record the run in the history of 'runblock history' and 'runblock rerun'")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20,
+		"number of runs to show (0 shows all)")
+	rerunCmd.Flags().BoolVar(&rerunLast, "last", false,
+		"repeat the last run")
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(rerunCmd)
+}
+
+// runHistory is the history of runs in the working directory, oldest first.
+type runHistory struct {
+	Runs []*historyEntry `json:"runs"`
+}
+
+// historyEntry is a run recorded in the history.
+type historyEntry struct {
+	ID        int       `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+	File      string    `json:"file"`
+	Args      []string  `json:"args"` // Arguments of runblock including the file
+	ExitCode  int       `json:"exit_code"`
+}
+
+// newHistoryEntry returns the entry of the run of the file by cmd with the flags set on the command line.
+func newHistoryEntry(cmd *cobra.Command, file string) *historyEntry {
+	var args []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "no-history" {
+			return
+		}
+		switch v := f.Value.(type) {
+		case pflag.SliceValue:
+			for _, s := range v.GetSlice() {
+				args = append(args, "--"+f.Name+"="+s)
+			}
+		default:
+			if entries, ok := mapFlagEntries(cmd.Flags(), f); ok {
+				for _, e := range entries {
+					args = append(args, "--"+f.Name+"="+e)
+				}
+				return
+			}
+			if f.Value.Type() == "bool" && f.Value.String() == "true" {
+				args = append(args, "--"+f.Name)
+				return
+			}
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+	args = append(args, file)
+	return &historyEntry{StartedAt: time.Now(), File: file, Args: args}
+}
+
+// mapFlagEntries returns the entries of a map flag as KEY=VALUE arguments sorted by key,
+// since the string form of map flags (e.g., "[sh=2]") cannot be parsed back.
+func mapFlagEntries(flags *pflag.FlagSet, f *pflag.Flag) ([]string, bool) {
+	var entries []string
+	switch f.Value.Type() {
+	case "stringToInt":
+		m, _ := flags.GetStringToInt(f.Name) //nostyle:handlerrors
+		for k, v := range m {
+			entries = append(entries, k+"="+strconv.Itoa(v))
+		}
+	case "stringToInt64":
+		m, _ := flags.GetStringToInt64(f.Name) //nostyle:handlerrors
+		for k, v := range m {
+			entries = append(entries, k+"="+strconv.FormatInt(v, 10))
+		}
+	case "stringToString":
+		m, _ := flags.GetStringToString(f.Name) //nostyle:handlerrors
+		for k, v := range m {
+			e := k + "=" + v
+			if strings.ContainsAny(e, ",\"\n") {
+				// The value of stringToString flags is read as CSV
+				e = `"` + strings.ReplaceAll(e, `"`, `""`) + `"`
+			}
+			entries = append(entries, e)
+		}
+	default:
+		return nil, false
+	}
+	slices.Sort(entries)
+	return entries, true
+}
+
+// commandLine returns the arguments of the entry quoted for the shell where needed.
+func (e *historyEntry) commandLine() string {
+	quoted := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		quoted[i] = a
+		if a == "" || strings.ContainsFunc(a, unsafeInShell) {
+			quoted[i] = runner.ShellQuote(a)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// unsafeInShell reports whether r needs quoting in an argument of the shell.
+func unsafeInShell(r rune) bool {
+	return !strings.ContainsRune("-_./=:,@+%", r) && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+}
+
+// loadHistory loads the history from path (an empty history if it does not exist).
+func loadHistory(path string) (*runHistory, error) {
+	h := &runHistory{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(b, h); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// recordHistory adds the entry to the history in path, keeping the last maxRuns runs.
+func recordHistory(path string, e *historyEntry) error {
+	h, err := loadHistory(path)
+	if err != nil {
+		return err
+	}
+	e.ID = 1
+	if len(h.Runs) > 0 {
+		e.ID = h.Runs[len(h.Runs)-1].ID + 1
+	}
+	h.Runs = append(h.Runs, e)
+	if len(h.Runs) > maxRuns {
+		h.Runs = h.Runs[len(h.Runs)-maxRuns:]
+	}
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// last returns the last run.
+func (h *runHistory) last() (*historyEntry, error) {
+	if len(h.Runs) == 0 {
+		return nil, errors.New("no runs in the history")
+	}
+	return h.Runs[len(h.Runs)-1], nil
+}
+
+// find returns the run with the ID.
+func (h *runHistory) find(id string) (*historyEntry, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(id, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid run ID: %s", id)
+	}
+	for _, e := range h.Runs {
+		if e.ID == n {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("run #%d is not in the history", n)
+}
+
+// writeHistory writes the last limit runs of h on w, newest first (all runs if limit is 0).
+func writeHistory(w io.Writer, h *runHistory, limit int) error {
+	if len(h.Runs) == 0 {
+		_, err := fmt.Fprintln(w, "No runs in the history")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTARTED\tEXIT\tCOMMAND")
+	for i, n := len(h.Runs)-1, 0; i >= 0 && (limit <= 0 || n < limit); i, n = i-1, n+1 {
+		e := h.Runs[i]
+		fmt.Fprintf(tw, "%d\t%s\t%d\trunblock %s\n", e.ID, e.StartedAt.Local().Format(time.DateTime), e.ExitCode, e.commandLine())
+	}
+	return tw.Flush()
+}
+
+// rerun runs runblock again with the arguments of the entry, connected to the terminal.
+func rerun(ctx context.Context, e *historyEntry) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the runblock executable: %w", err)
+	}
+	c := exec.CommandContext(ctx, exe, e.Args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	err = c.Run()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return &exitError{err: fmt.Errorf("run #%d failed again", e.ID), code: ee.ExitCode()}
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNewHistoryEntry(t *testing.T) {
+	var (
+		names []string
+		state bool
+		keep  bool
+		limit string
+		nohis bool
+	)
+	cmd := &cobra.Command{}
+	cmd.Flags().StringArrayVar(&names, "name", nil, "")
+	cmd.Flags().BoolVar(&state, "state", false, "")
+	cmd.Flags().BoolVar(&keep, "keep-tmp", false, "")
+	cmd.Flags().StringVar(&limit, "max-output", "", "")
+	cmd.Flags().BoolVar(&nohis, "no-history", false, "")
+	if err := cmd.ParseFlags([]string{"--name", "a", "--name=b c", "--state", "--max-output", "1MB", "--no-history"}); err != nil {
+		t.Fatal(err)
+	}
+	e := newHistoryEntry(cmd, "doc.md")
+	want := []string{"--max-output=1MB", "--name=a", "--name=b c", "--state", "doc.md"}
+	if !slices.Equal(e.Args, want) {
+		t.Errorf("got %q, want %q", e.Args, want)
+	}
+	if got, want := e.commandLine(), "--max-output=1MB --name=a '--name=b c' --state doc.md"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewHistoryEntry_MapFlags(t *testing.T) {
+	newCmd := func(parallel *map[string]int, rates *map[string]string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringToIntVar(parallel, "parallel", nil, "")
+		cmd.Flags().StringToStringVar(rates, "tag-rate", nil, "")
+		return cmd
+	}
+	var (
+		parallel map[string]int
+		rates    map[string]string
+	)
+	cmd := newCmd(&parallel, &rates)
+	if err := cmd.ParseFlags([]string{"--parallel", "sh=2,python=1", "--tag-rate", "api=5/s", "--tag-rate", `"db=1,2"`}); err != nil {
+		t.Fatal(err)
+	}
+	e := newHistoryEntry(cmd, "doc.md")
+	want := []string{"--parallel=python=1", "--parallel=sh=2", `--tag-rate="db=1,2"`, "--tag-rate=api=5/s", "doc.md"}
+	if !slices.Equal(e.Args, want) {
+		t.Errorf("got %q, want %q", e.Args, want)
+	}
+
+	// Replay the recorded flags
+	var (
+		replayedParallel map[string]int
+		replayedRates    map[string]string
+	)
+	replay := newCmd(&replayedParallel, &replayedRates)
+	if err := replay.ParseFlags(e.Args[:len(e.Args)-1]); err != nil {
+		t.Fatalf("failed to replay %q: %v", e.Args, err)
+	}
+	if !maps.Equal(replayedParallel, parallel) || !maps.Equal(replayedRates, rates) {
+		t.Errorf("replayed %v and %v, want %v and %v", replayedParallel, replayedRates, parallel, rates)
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	for i := range maxRuns + 2 {
+		if err := recordHistory(path, &historyEntry{File: "doc.md", Args: []string{"doc.md"}, ExitCode: i % 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(h.Runs); got != maxRuns {
+		t.Errorf("got %d runs, want %d", got, maxRuns)
+	}
+	last, err := h.last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := last.ID, maxRuns+2; got != want {
+		t.Errorf("got last ID %d, want %d", got, want)
+	}
+	if _, err := h.find("#1"); err == nil {
+		t.Error("want error for a run dropped from the history")
+	}
+	e, err := h.find("#50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 50 {
+		t.Errorf("got ID %d, want 50", e.ID)
+	}
+	if _, err := h.find("last"); err == nil {
+		t.Error("want error for an invalid ID")
+	}
+}
+
+func TestLoadHistoryMissing(t *testing.T) {
+	h, err := loadHistory(filepath.Join(t.TempDir(), "history.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.last(); err == nil {
+		t.Error("want error for an empty history")
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	h := &runHistory{Runs: []*historyEntry{
+		{ID: 1, StartedAt: at, Args: []string{"a.md"}},
+		{ID: 2, StartedAt: at.Add(time.Minute), Args: []string{"--name=deploy", "b.md"}, ExitCode: 1},
+	}}
+	var buf bytes.Buffer
+	if err := writeHistory(&buf, h, 1); err != nil {
+		t.Fatal(err)
+	}
+	want := `ID  STARTED              EXIT  COMMAND
+2   2024-05-01 12:01:00  1     runblock --name=deploy b.md
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
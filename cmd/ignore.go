@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreFile is the file with gitignore-style patterns of files excluded in directory mode.
+const ignoreFile = ".runblockignore"
+
+// ignoreRule is a pattern of an ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool // !pattern re-includes matching paths
+	dirOnly bool // pattern/ matches directories only
+}
+
+// ignoreRules is the rules of an ignore file, the last matching rule winning like gitignore.
+type ignoreRules []ignoreRule
+
+// loadIgnore reads the ignore file in dir (no rules if there is none).
+func loadIgnore(dir string) (ignoreRules, error) {
+	f, err := os.Open(filepath.Join(dir, ignoreFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }() //nostyle:handlerrors
+	var rules ignoreRules
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		rule, ok, err := parseIgnoreRule(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, ignoreFile), err)
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, s.Err()
+}
+
+// parseIgnoreRule parses a line of an ignore file. Blank lines and comments are not rules.
+func parseIgnoreRule(line string) (ignoreRule, bool, error) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false, nil
+	}
+	var rule ignoreRule
+	if p, ok := strings.CutPrefix(line, "!"); ok {
+		rule.negate, line = true, p
+	}
+	line = strings.TrimPrefix(line, `\`) // \# and \! escape the first character
+	if p, ok := strings.CutSuffix(line, "/"); ok {
+		rule.dirOnly, line = true, p
+	}
+	// A pattern with a slash (other than at the end) is relative to the directory of the ignore file
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignoreRule{}, false, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if p, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + p
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(line):
+			i++
+			b.WriteString(regexp.QuoteMeta(line[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return ignoreRule{}, false, fmt.Errorf("invalid pattern %q: %w", line, err)
+	}
+	rule.re = re
+	return rule, true, nil
+}
+
+// ignored reports whether the slash-separated path relative to the directory of the ignore file is excluded.
+func (rules ignoreRules) ignored(path string, dir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !dir {
+			continue
+		}
+		if rule.re.MatchString(path) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	var rules ignoreRules
+	for _, line := range []string{
+		"# generated",
+		"",
+		"CHANGELOG.md",
+		"node_modules/",
+		"/vendor",
+		"docs/**/draft-*.md",
+		"*.tmp.md",
+		"!keep.tmp.md",
+		`\#notes.md`,
+	} {
+		rule, ok, err := parseIgnoreRule(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	tests := []struct {
+		path string
+		dir  bool
+		want bool
+	}{
+		{"CHANGELOG.md", false, true},
+		{"pkg/CHANGELOG.md", false, true},
+		{"CHANGELOG.markdown", false, false},
+		{"node_modules", true, true},
+		{"web/node_modules", true, true},
+		{"node_modules", false, false},
+		{"vendor", true, true},
+		{"pkg/vendor", true, false},
+		{"docs/draft-a.md", false, true},
+		{"docs/guide/v2/draft-a.md", false, true},
+		{"guide/draft-a.md", false, false},
+		{"a/b.tmp.md", false, true},
+		{"a/keep.tmp.md", false, false},
+		{"#notes.md", false, true},
+		{"README.md", false, false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignored(tt.path, tt.dir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownFiles_Ignore(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"README.md", "CHANGELOG.md", "docs/guide.md", "node_modules/pkg/README.md", ".hidden/a.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# doc\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ignoreFile), []byte("CHANGELOG.md\nnode_modules/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	files, err := markdownFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, err := filepath.Rel(dir, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	if want := []string{"README.md", "docs/guide.md"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
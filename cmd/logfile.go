@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+var logFile string
+
+func init() {
+	rootCmd.Flags().StringVar(&logFile, "log-file", "",
+		"write a timestamped log of every block to the file named by the template (e.g., 'logs/{{filename}}_{{i}}_{{lang}}.log')")
+}
+
+// logTimeFormat is the format of timestamps in block logs.
+const logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// blockLogs writes the output of every code block to its own log file.
+type blockLogs struct {
+	mu       sync.Mutex
+	template string
+	filename string // Name of the document without the extension
+	f        *os.File
+	pending  map[string][]byte // Incomplete lines per stream
+	errs     []error
+	now      func() time.Time
+}
+
+// newBlockLogs returns block logs named by the template for the document file ("-" for stdin).
+// The template can use filename, i, lang, name, heading and slug.
+func newBlockLogs(template, file string) (*blockLogs, error) {
+	filename := "stdin"
+	if file != "-" {
+		filename = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	l := &blockLogs{
+		template: template,
+		filename: filename,
+		pending:  map[string][]byte{},
+		now:      time.Now,
+	}
+	if _, err := l.path(0, parser.CodeBlock{}); err != nil {
+		return nil, fmt.Errorf("invalid --log-file: %w", err)
+	}
+	return l, nil
+}
+
+// path returns the path of the log file of a code block.
+func (l *blockLogs) path(index int, block parser.CodeBlock) (string, error) {
+	return runner.ExpandTemplate(l.template, map[string]any{
+		"filename": l.filename,
+		"i":        index,
+		"lang":     block.Language,
+		"name":     block.Name(),
+		"heading":  block.Heading,
+		"slug":     runner.Slug(block.Heading),
+	})
+}
+
+// attach writes the output of the code blocks run by r to their log files.
+func (l *blockLogs) attach(r *runner.Runner) {
+	r.Stdout = io.MultiWriter(r.Stdout, l.writer("stdout"))
+	r.Stderr = io.MultiWriter(r.Stderr, l.writer("stderr"))
+	addStartHook(r, l.start)
+	addResultHook(r, l.finish)
+}
+
+// start opens the log file of a code block.
+func (l *blockLogs) start(result *runner.Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	path, err := l.path(result.Index, result.Block)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+	if err == nil {
+		l.f, err = os.Create(path)
+	}
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("failed to open log file of block %d: %w", result.Index+1, err))
+		return
+	}
+	l.writeLine("start", fmt.Sprintf("block %d (%s): %q", result.Index+1, result.Block.Language, result.Command))
+}
+
+// finish writes the result of a code block and closes its log file.
+func (l *blockLogs) finish(result *runner.Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return
+	}
+	for _, stream := range []string{"stdout", "stderr"} {
+		if p := l.pending[stream]; len(p) > 0 {
+			l.writeLine(stream, string(p))
+		}
+	}
+	clear(l.pending)
+	status := fmt.Sprintf("exit_code=%d duration=%s", result.ExitCode, result.Duration.Round(time.Millisecond))
+	if result.Err != nil {
+		status += " error=" + result.Err.Error()
+	}
+	l.writeLine("result", status)
+	if err := l.f.Close(); err != nil {
+		l.errs = append(l.errs, err)
+	}
+	l.f = nil
+}
+
+// writer returns a writer writing complete lines to the log file of the running block.
+func (l *blockLogs) writer(stream string) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.f == nil {
+			return len(b), nil
+		}
+		p := append(l.pending[stream], b...)
+		for {
+			i := bytes.IndexByte(p, '\n')
+			if i < 0 {
+				break
+			}
+			l.writeLine(stream, string(p[:i]))
+			p = p[i+1:]
+		}
+		l.pending[stream] = p
+		return len(b), nil
+	})
+}
+
+// writeLine writes a timestamped line to the log file.
+func (l *blockLogs) writeLine(kind, line string) {
+	if _, err := fmt.Fprintf(l.f, "%s %-6s %s\n", l.now().Format(logTimeFormat), kind, line); err != nil {
+		l.errs = append(l.errs, err)
+	}
+}
+
+// Err returns the errors occurred while writing the logs.
+func (l *blockLogs) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.errs...)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestBlockLogs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	logs, err := newBlockLogs(filepath.Join(dir, "logs", "{{filename}}_{{i}}_{{lang}}.log"), "docs/runbook.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	var stdout, stderr bytes.Buffer
+	r := &runner.Runner{Stdout: &stdout, Stderr: &stderr, KeepGoing: true}
+	logs.attach(r)
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "printf 'a\\nb'; echo c >&2"},
+		{Language: "txt"},
+		{Language: "bash", Command: "exit 2"},
+	}
+	if err := r.RunAll(t.Context(), blocks); err == nil {
+		t.Fatal("RunAll() should return error")
+	}
+	if err := logs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "a\nb"; got != want {
+		t.Errorf("terminal output = %q, want %q", got, want)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "logs", "runbook_0_sh.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"2025-01-02T03:04:05.000Z start  block 1 (sh): \"printf 'a\\\\nb'; echo c >&2\"\n",
+		"2025-01-02T03:04:05.000Z stdout a\n",
+		"2025-01-02T03:04:05.000Z stderr c\n",
+		"2025-01-02T03:04:05.000Z stdout b\n2025-01-02T03:04:05.000Z result exit_code=0 duration=",
+	} {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("log does not contain %q:\n%s", want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logs", "runbook_1_txt.log")); !os.IsNotExist(err) {
+		t.Errorf("skipped block should not have a log file: %v", err)
+	}
+	got, err = os.ReadFile(filepath.Join(dir, "logs", "runbook_2_bash.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := " error=exit status 2\n"; !bytes.HasSuffix(got, []byte(want)) {
+		t.Errorf("log does not end with %q:\n%s", want, got)
+	}
+}
+
+func TestNewBlockLogs_InvalidTemplate(t *testing.T) {
+	if _, err := newBlockLogs("{{unknown}}.log", "-"); err == nil {
+		t.Error("newBlockLogs() should return error for an invalid template")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/spf13/cobra"
+)
+
+// matrixCmd represents the matrix command
+var matrixCmd = &cobra.Command{
+	Use:   "matrix [MARKDOWN_FILE]",
+	Short: "Print named code blocks as a JSON array for a CI matrix",
+	Long: `matrix prints a JSON array of named code block descriptors (name, lang, tags)
+suitable for a GitHub Actions matrix, so that each block can run as its own job
+via 'runblock --name'.
+
+    strategy:
+      matrix:
+        block: ${{ fromJSON(needs.list.outputs.blocks) }}
+    steps:
+      - run: runblock --name "${{ matrix.block.name }}" runbook.md`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		blocks, err := readBlocks(args)
+		if err != nil {
+			return err
+		}
+		return matrix(cmd.OutOrStdout(), blocks)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(matrixCmd)
+}
+
+// matrixEntry is a code block descriptor in a CI matrix.
+type matrixEntry struct {
+	Name  string   `json:"name"`
+	Lang  string   `json:"lang"`
+	Tags  []string `json:"tags"`
+	Index int      `json:"index"`
+}
+
+// matrix writes the named blocks as a JSON array to w.
+func matrix(w io.Writer, blocks []parser.CodeBlock) error {
+	entries := []matrixEntry{}
+	for i, block := range blocks {
+		if block.Name() == "" {
+			continue
+		}
+		tags := block.Tags()
+		if tags == nil {
+			tags = []string{}
+		}
+		entries = append(entries, matrixEntry{
+			Name:  block.Name(),
+			Lang:  block.Language,
+			Tags:  tags,
+			Index: i,
+		})
+	}
+	return json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestMatrix(t *testing.T) {
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Attributes: map[string]string{"name": "build", "tags": "ci, slow"}},
+		{Language: "sh"},
+		{Language: "go", Attributes: map[string]string{"name": "fmt"}},
+	}
+	var buf bytes.Buffer
+	if err := matrix(&buf, blocks); err != nil {
+		t.Fatalf("matrix() error = %v", err)
+	}
+	want := `[{"name":"build","lang":"sh","tags":["ci","slow"],"index":0},{"name":"fmt","lang":"go","tags":[],"index":2}]` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("matrix() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/k1LoW/runblock/render"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// durationBuckets are the upper bounds in seconds of the buckets of the block duration histogram.
+var durationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// metricKey is the labels of a metric of blocks.
+type metricKey struct {
+	lang   string
+	status string
+}
+
+// histogram is a cumulative histogram of durations in seconds.
+type histogram struct {
+	buckets []int // Counts of observations less than or equal to durationBuckets
+	sum     float64
+	count   int
+}
+
+// blockMetrics collects metrics of the blocks run and exposes them in the Prometheus text format.
+type blockMetrics struct {
+	mu        sync.Mutex
+	runs      map[metricKey]int
+	durations map[metricKey]*histogram
+}
+
+func newBlockMetrics() *blockMetrics {
+	return &blockMetrics{runs: map[metricKey]int{}, durations: map[metricKey]*histogram{}}
+}
+
+// record records the result of a block.
+func (m *blockMetrics) record(result *runner.Result) {
+	k := metricKey{lang: result.Block.Language, status: render.Status(result)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[k]++
+	if result.Skipped {
+		return
+	}
+	h, ok := m.durations[k]
+	if !ok {
+		h = &histogram{buckets: make([]int, len(durationBuckets))}
+		m.durations[k] = h
+	}
+	s := result.Duration.Seconds()
+	for i, le := range durationBuckets {
+		if s <= le {
+			h.buckets[i]++
+		}
+	}
+	h.sum += s
+	h.count++
+}
+
+// write writes the metrics to w in the Prometheus text exposition format.
+func (m *blockMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP runblock_blocks_run_total Number of code blocks run by language and status.")
+	fmt.Fprintln(w, "# TYPE runblock_blocks_run_total counter")
+	for _, k := range sortedKeys(m.runs) {
+		fmt.Fprintf(w, "runblock_blocks_run_total{%s} %d\n", k.labels(), m.runs[k])
+	}
+
+	fmt.Fprintln(w, "# HELP runblock_block_duration_seconds Duration of code blocks by language and status.")
+	fmt.Fprintln(w, "# TYPE runblock_block_duration_seconds histogram")
+	for _, k := range sortedKeys(m.durations) {
+		h := m.durations[k]
+		for i, le := range durationBuckets {
+			fmt.Fprintf(w, "runblock_block_duration_seconds_bucket{%s,le=%q} %d\n", k.labels(), strconv.FormatFloat(le, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(w, "runblock_block_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", k.labels(), h.count)
+		fmt.Fprintf(w, "runblock_block_duration_seconds_sum{%s} %s\n", k.labels(), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "runblock_block_duration_seconds_count{%s} %d\n", k.labels(), h.count)
+	}
+}
+
+func (m *blockMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+// labels returns the labels in the Prometheus text format.
+func (k metricKey) labels() string {
+	return fmt.Sprintf("lang=%s,status=%s", quoteLabel(k.lang), quoteLabel(k.status))
+}
+
+// labelEscaper escapes label values in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quoteLabel quotes a label value in the Prometheus text format.
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+// sortedKeys returns the keys of m sorted by language and status.
+func sortedKeys[V any](m map[metricKey]V) []metricKey {
+	keys := make([]metricKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b metricKey) int {
+		if c := strings.Compare(a.lang, b.lang); c != 0 {
+			return c
+		}
+		return strings.Compare(a.status, b.status)
+	})
+	return keys
+}
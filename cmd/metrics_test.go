@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestBlockMetrics(t *testing.T) {
+	m := newBlockMetrics()
+	m.record(&runner.Result{Block: parser.CodeBlock{Language: "sh"}, Duration: 300 * time.Millisecond})
+	m.record(&runner.Result{Block: parser.CodeBlock{Language: "sh"}, Duration: 2 * time.Second})
+	m.record(&runner.Result{Block: parser.CodeBlock{Language: `a"b`}, Duration: time.Second, Err: errors.New("failed")})
+	m.record(&runner.Result{Block: parser.CodeBlock{Language: "text"}, Skipped: true})
+
+	var buf bytes.Buffer
+	m.write(&buf)
+	want := `# HELP runblock_blocks_run_total Number of code blocks run by language and status.
+# TYPE runblock_blocks_run_total counter
+runblock_blocks_run_total{lang="a\"b",status="failed"} 1
+runblock_blocks_run_total{lang="sh",status="passed"} 2
+runblock_blocks_run_total{lang="text",status="skipped"} 1
+# HELP runblock_block_duration_seconds Duration of code blocks by language and status.
+# TYPE runblock_block_duration_seconds histogram
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="0.1"} 0
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="0.5"} 0
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="1"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="2.5"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="5"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="10"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="30"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="60"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="300"} 1
+runblock_block_duration_seconds_bucket{lang="a\"b",status="failed",le="+Inf"} 1
+runblock_block_duration_seconds_sum{lang="a\"b",status="failed"} 1
+runblock_block_duration_seconds_count{lang="a\"b",status="failed"} 1
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="0.1"} 0
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="0.5"} 1
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="1"} 1
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="2.5"} 2
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="5"} 2
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="10"} 2
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="30"} 2
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="60"} 2
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="300"} 2
+runblock_block_duration_seconds_bucket{lang="sh",status="passed",le="+Inf"} 2
+runblock_block_duration_seconds_sum{lang="sh",status="passed"} 2.3
+runblock_block_duration_seconds_count{lang="sh",status="passed"} 2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	notifyURL      string
+	notifyFailures bool
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&notifyURL, "notify-url", "",
+		"post a JSON summary of the run to the webhook URL (compatible with Slack incoming webhooks)")
+	rootCmd.Flags().BoolVar(&notifyFailures, "notify-failures", false,
+		"include failed blocks with output snippets in the notification")
+}
+
+// notifyTimeout is the timeout of posting a notification.
+const notifyTimeout = 10 * time.Second
+
+// maxSnippetLen is the maximum length of an output snippet in a notification.
+const maxSnippetLen = 1000
+
+// notification is the JSON payload posted to the webhook.
+type notification struct {
+	Text       string              `json:"text"` // For Slack incoming webhooks
+	File       string              `json:"file"`
+	Passed     int                 `json:"passed"`
+	Failed     int                 `json:"failed"`
+	Skipped    int                 `json:"skipped"`
+	DurationMS int64               `json:"duration_ms"`
+	Failures   []notificationBlock `json:"failures,omitempty"`
+}
+
+// notificationBlock is a failed block in a notification.
+type notificationBlock struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name,omitempty"`
+	Lang    string `json:"lang"`
+	Command string `json:"command"`
+	Error   string `json:"error"`
+	Output  string `json:"output,omitempty"`
+}
+
+// newNotification builds the notification payload from a report.
+func newNotification(rp *report, withFailures bool) notification {
+	n := notification{
+		File:       rp.File,
+		Passed:     rp.Count(statusPassed),
+		Failed:     rp.Count(statusFailed),
+		Skipped:    rp.Count(statusSkipped),
+		DurationMS: rp.Duration.Milliseconds(),
+	}
+	mark := "✅"
+	if n.Failed > 0 {
+		mark = "❌"
+	}
+	n.Text = fmt.Sprintf("%s runblock %s: %d passed, %d failed, %d skipped in %s",
+		mark, n.File, n.Passed, n.Failed, n.Skipped, rp.Duration.Round(time.Millisecond))
+	if !withFailures {
+		return n
+	}
+	for _, b := range rp.Blocks {
+		if b.Status != statusFailed {
+			continue
+		}
+		n.Failures = append(n.Failures, notificationBlock{
+			Index:   b.Index,
+			Name:    b.Name,
+			Lang:    b.Lang,
+			Command: b.Command,
+			Error:   b.Error,
+			Output:  tail(b.Stderr+b.Stdout, maxSnippetLen),
+		})
+		n.Text += fmt.Sprintf("\n• block %d (%s): %s", b.Index+1, b.Lang, b.Error)
+	}
+	return n
+}
+
+// notify posts the summary of the report to the webhook URL.
+func notify(ctx context.Context, url string, rp *report, withFailures bool) error {
+	b, err := json.Marshal(newNotification(rp, withFailures))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nostyle:handlerrors
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send notification: %s", resp.Status)
+	}
+	return nil
+}
+
+// tail returns the last n bytes of s.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "…" + s[len(s)-n:]
+}
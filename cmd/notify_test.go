@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	var got notification
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+	}))
+	defer ts.Close()
+
+	rp := newTestReport()
+	if err := notify(t.Context(), ts.URL, rp, true); err != nil {
+		t.Fatalf("notify() error = %v", err)
+	}
+	if got.File != "runbook.md" || got.Passed != 1 || got.Failed != 1 || got.Skipped != 1 {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+	if !strings.HasPrefix(got.Text, "❌ runblock runbook.md: 1 passed, 1 failed, 1 skipped") {
+		t.Errorf("Text = %q", got.Text)
+	}
+	if len(got.Failures) != 1 || got.Failures[0].Index != 1 || got.Failures[0].Output != "oops\n" {
+		t.Errorf("Failures = %+v", got.Failures)
+	}
+}
+
+func TestNotify_WithoutFailures(t *testing.T) {
+	n := newNotification(newTestReport(), false)
+	if n.Failures != nil {
+		t.Errorf("Failures = %+v, want nil", n.Failures)
+	}
+}
+
+func TestNotify_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	if err := notify(t.Context(), ts.URL, newTestReport(), false); err == nil {
+		t.Error("notify() should return error for a non-2xx response")
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+// planVersion is the version of the execution plan format.
+const planVersion = 1
+
+// maxPlanBlocks bounds the block indexes of a plan, so that an edited plan cannot make apply allocate without limit.
+const maxPlanBlocks = 100000
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [MARKDOWN_FILE]",
+	Short: "Write an execution plan of the code blocks as JSON",
+	Long: `plan resolves the code blocks and writes an execution plan as JSON:
+the blocks in the order they would run, their fully expanded commands,
+environment variables, skips and the blocks each of them depends on.
+
+Nothing is executed. Review the plan and execute exactly it with 'runblock apply'.
+
+    runblock plan runbook.md > plan.json
+    runblock apply plan.json`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := readSource(args)
+		if err != nil {
+			return err
+		}
+		if err := verifySource(source, args); err != nil {
+			return err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return err
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		if err := applyDocConfig(r, source); err != nil {
+			return err
+		}
+		r.File = sourceName(args)
+		// apply replaces the placeholder with the temporary directory of its run
+		r.TmpDir = runner.TmpDirPlaceholder
+		p, err := newPlan(r, sourceName(args), source, blocks)
+		if err != nil {
+			return err
+		}
+		for _, step := range p.Steps {
+			// Plans are meant to be reviewed and stored
+			if r.Mask(step.Command) != step.Command {
+				return fmt.Errorf("code block %d: the command contains secrets, which cannot be written to a plan", step.Index+1)
+			}
+			if err := checkPlanStep(step); err != nil {
+				return err
+			}
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	},
+}
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply PLAN_FILE",
+	Short: "Execute an execution plan written by 'runblock plan'",
+	Long: `apply executes exactly the commands recorded in an execution plan, in its order.
+The Markdown document is not read again, so later changes to it do not affect the run.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		p, err := readPlan(args[0])
+		if err != nil {
+			return err
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		r.File = p.Source
+		tmpDir, err := os.MkdirTemp("", "runblock-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		r.TmpDir = tmpDir
+		defer func() {
+			err = errors.Join(err, os.RemoveAll(tmpDir))
+		}()
+		fmt.Fprintf(cmd.ErrOrStderr(), "Applying plan of %s (sha256:%s)\n", p.Source, p.SHA256)
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		context.AfterFunc(ctx, stop)
+		return applyPlan(ctx, r, p)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+}
+
+// executionPlan is a serialized execution plan of a document.
+type executionPlan struct {
+	Version int        `json:"version"`
+	Source  string     `json:"source"`
+	SHA256  string     `json:"sha256"` // SHA-256 hash of the document the plan was made from
+	Steps   []planStep `json:"steps"`  // Blocks in the order they run
+}
+
+// planStep is a code block in an execution plan.
+type planStep struct {
+	Index      int               `json:"index"`
+	Line       int               `json:"line"`
+	Lang       string            `json:"lang"`
+	Content    string            `json:"content"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Stage      string            `json:"stage"`
+	Always     bool              `json:"always,omitempty"`
+	DependsOn  []int             `json:"depends_on,omitempty"` // Indexes of the blocks that must succeed before the block runs
+	runner.Resolution
+}
+
+// newPlan resolves the selected blocks into an execution plan.
+func newPlan(r *runner.Runner, name string, source []byte, blocks []parser.CodeBlock) (*executionPlan, error) {
+	if len(blocks) > maxPlanBlocks {
+		return nil, fmt.Errorf("too many code blocks to plan: %d (max %d)", len(blocks), maxPlanBlocks)
+	}
+	order, err := runner.RunOrder(blocks)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(source)
+	p := &executionPlan{
+		Version: planVersion,
+		Source:  name,
+		SHA256:  hex.EncodeToString(sum[:]),
+		Steps:   []planStep{},
+	}
+	var runs []int
+	for _, i := range order {
+		block := blocks[i]
+		if r.Select != nil && !r.Select(block, i) {
+			continue
+		}
+		res, err := r.Resolve(block, i)
+		if err != nil {
+			return nil, fmt.Errorf("code block %d: %w", i+1, err)
+		}
+		stage, _ := runner.BlockStage(block)  //nostyle:handlerrors
+		always, _ := runner.RunsAlways(block) //nostyle:handlerrors
+		step := planStep{
+			Index:      i,
+			Line:       block.Line,
+			Lang:       block.Language,
+			Content:    block.Content,
+			Attributes: block.Attributes,
+			Stage:      stage,
+			Always:     always,
+			Resolution: *res,
+		}
+		if !always && !res.Skip {
+			step.DependsOn = slices.Clone(runs)
+		}
+		if !res.Skip {
+			runs = append(runs, i)
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	return p, nil
+}
+
+// checkPlanStep returns an error if the step cannot be executed as planned.
+// The command of a split block is resolved for each chunk, but a plan records only one command.
+func checkPlanStep(step planStep) error {
+	if _, split := step.Attributes[runner.AttrSplit]; split && !step.Skip {
+		return fmt.Errorf("code block %d: split blocks cannot be planned", step.Index+1)
+	}
+	return nil
+}
+
+// readPlan reads an execution plan from the file.
+func readPlan(path string) (*executionPlan, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	p := &executionPlan{}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if p.Version != planVersion {
+		return nil, fmt.Errorf("unsupported plan version %d", p.Version)
+	}
+	return p, nil
+}
+
+// applyPlan executes the steps of an execution plan with r.
+// The commands are not resolved again; only the policy is evaluated and
+// the placeholder of the temporary directory is replaced with TmpDir of r.
+func applyPlan(ctx context.Context, r *runner.Runner, p *executionPlan) error {
+	size := 0
+	seen := map[int]bool{}
+	for _, step := range p.Steps {
+		if step.Index < 0 || step.Index >= maxPlanBlocks {
+			return fmt.Errorf("invalid plan: block index %d is out of range", step.Index)
+		}
+		if seen[step.Index] {
+			return fmt.Errorf("invalid plan: block index %d appears more than once", step.Index)
+		}
+		seen[step.Index] = true
+		if err := checkPlanStep(step); err != nil {
+			return err
+		}
+		size = max(size, step.Index+1)
+	}
+	blocks := make([]parser.CodeBlock, size)
+	r.Resolutions = make(map[int]*runner.Resolution, len(p.Steps))
+	for _, step := range p.Steps {
+		blocks[step.Index] = parser.CodeBlock{
+			Language:   step.Lang,
+			Content:    step.Content,
+			Attributes: step.Attributes,
+			Line:       step.Line,
+		}
+		res := step.Resolution
+		res.Command = strings.ReplaceAll(res.Command, runner.TmpDirPlaceholder, r.TmpDir)
+		res.Env = slices.Clone(res.Env)
+		for i, e := range res.Env {
+			res.Env[i] = strings.ReplaceAll(e, runner.TmpDirPlaceholder, r.TmpDir)
+		}
+		r.Resolutions[step.Index] = &res
+	}
+
+	// Blocks not in the plan are never run
+	sel := r.Select
+	r.Select = func(block parser.CodeBlock, i int) bool {
+		if _, ok := r.Resolutions[i]; !ok {
+			return false
+		}
+		return sel == nil || sel(block, i)
+	}
+	return r.RunAll(ctx, blocks)
+}
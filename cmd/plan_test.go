@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo bye", Attributes: map[string]string{"stage": "teardown"}},
+		{Language: "sh", Command: "echo {{lang}} $X", Attributes: map[string]string{"env.X": "1"}},
+		{Language: "text", Content: "plain\n"},
+		{Language: "sh", Command: "echo second"},
+	}
+	p, err := newPlan(runner.New("", nil), "runbook.md", []byte("source"), blocks)
+	if err != nil {
+		t.Fatalf("newPlan() error = %v", err)
+	}
+
+	var order []int
+	for _, step := range p.Steps {
+		order = append(order, step.Index)
+	}
+	if want := []int{1, 2, 3, 0}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if got := p.Steps[0].Command; got != "echo sh $X" {
+		t.Errorf("command = %q, want %q", got, "echo sh $X")
+	}
+	if !p.Steps[1].Skip {
+		t.Error("block without command should be skipped")
+	}
+	if got := p.Steps[2].DependsOn; !slices.Equal(got, []int{1}) {
+		t.Errorf("depends_on = %v, want [1]", got)
+	}
+	if got := p.Steps[3].DependsOn; got != nil {
+		t.Errorf("teardown depends_on = %v, want nil", got)
+	}
+
+	// Round trip through a file
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err = readPlan(path)
+	if err != nil {
+		t.Fatalf("readPlan() error = %v", err)
+	}
+
+	// The recorded commands are executed, not the document
+	p.Steps[0].Command = "echo planned $X"
+	var stdout bytes.Buffer
+	r := runner.New("", nil)
+	r.Stdout = &stdout
+	if err := applyPlan(t.Context(), r, p); err != nil {
+		t.Fatalf("applyPlan() error = %v", err)
+	}
+	if got, want := stdout.String(), "planned 1\nsecond\nbye\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestPlanAndApply_TmpDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "sh -c 'echo hello > {{tmpdir}}/x'"},
+		{Language: "sh", Command: "sh -c 'cat {{tmpdir}}/x'"},
+	}
+	pr := runner.New("", nil)
+	pr.TmpDir = runner.TmpDirPlaceholder
+	p, err := newPlan(pr, "runbook.md", []byte("source"), blocks)
+	if err != nil {
+		t.Fatalf("newPlan() error = %v", err)
+	}
+	if got, want := p.Steps[0].Command, "sh -c 'echo hello > {{tmpdir}}/x'"; got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+
+	var stdout bytes.Buffer
+	r := runner.New("", nil)
+	r.Stdout = &stdout
+	r.TmpDir = t.TempDir()
+	if err := applyPlan(t.Context(), r, p); err != nil {
+		t.Fatalf("applyPlan() error = %v", err)
+	}
+	if got := stdout.String(); got != "hello\n" {
+		t.Errorf("stdout = %q, want %q", got, "hello\n")
+	}
+	if _, err := os.Stat(filepath.Join(r.TmpDir, "x")); err != nil {
+		t.Errorf("file is not written to the temporary directory: %v", err)
+	}
+}
+
+func TestApplyPlan_Invalid(t *testing.T) {
+	step := func(index int, attrs map[string]string) planStep {
+		return planStep{Index: index, Lang: "sh", Attributes: attrs, Resolution: runner.Resolution{Command: "true"}}
+	}
+	tests := []struct {
+		name    string
+		steps   []planStep
+		wantErr string
+	}{
+		{"negative index", []planStep{step(-1, nil)}, "out of range"},
+		{"huge index", []planStep{step(1<<40, nil)}, "out of range"},
+		{"duplicate index", []planStep{step(0, nil), step(0, nil)}, "more than once"},
+		{"split block", []planStep{step(0, map[string]string{"split": ""})}, "split blocks cannot be planned"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &executionPlan{Version: planVersion, Steps: tt.steps}
+			if err := applyPlan(t.Context(), runner.New("", nil), p); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("applyPlan() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadPlan_Version(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version":2,"steps":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPlan(path); err == nil {
+		t.Error("readPlan() should fail for an unsupported version")
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// catCommand returns a command copying stdin to stdout in the shell of runner.BuildCommand,
+// so that tests do not depend on a POSIX shell.
+func catCommand(t *testing.T) string {
+	t.Helper()
+	switch sh, _, _ := runner.BuildCommand("echo ok"); sh {
+	case "pwsh":
+		return "[Console]::Out.Write([Console]::In.ReadToEnd())"
+	case "cmd":
+		t.Skip("requires PowerShell on Windows")
+	}
+	return "cat"
+}
+
+// portableBlocks replaces cat in the commands of the blocks with catCommand.
+func portableBlocks(t *testing.T, blocks []parser.CodeBlock) []parser.CodeBlock {
+	t.Helper()
+	for i := range blocks {
+		if blocks[i].Command == "cat" {
+			blocks[i].Command = catCommand(t)
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// spinnerFrames are the frames of the progress spinner.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// maxLabelLen is the maximum length of the command shown in the progress line.
+const maxLabelLen = 60
+
+// progress shows a live spinner with the elapsed time of the running block on a terminal.
+type progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	total   int
+	label   string
+	started time.Time
+	frame   int
+	visible bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newProgress returns a progress display for total blocks written to w.
+func newProgress(w io.Writer, total int) *progress {
+	return &progress{w: w, total: total}
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// start starts the spinner for a block.
+func (p *progress) start(result *runner.Result) {
+	p.mu.Lock()
+	p.label = fmt.Sprintf("[%d/%d] %s: %s", result.Index+1, p.total, result.Block.Language, summarizeCommand(result.Command))
+	p.started = result.StartedAt
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	p.draw()
+	p.mu.Unlock()
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.frame++
+				p.draw()
+				p.mu.Unlock()
+			}
+		}
+	}(p.stop, p.done)
+}
+
+// finish stops the spinner and prints the duration of the block.
+func (p *progress) finish(result *runner.Result) {
+	if result.Skipped || result.StartedAt.IsZero() {
+		return
+	}
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop = nil
+	p.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	status := "Done"
+	if result.Err != nil {
+		status = "Failed"
+	}
+	fmt.Fprintf(p.w, "%s %s (%s)\n", status, p.label, result.Duration.Round(time.Millisecond))
+}
+
+// draw draws the progress line. p.mu must be held.
+func (p *progress) draw() {
+	if p.stop == nil {
+		return
+	}
+	elapsed := time.Since(p.started).Truncate(time.Second)
+	fmt.Fprintf(p.w, "\r\033[K%s Running %s … %s", spinnerFrames[p.frame%len(spinnerFrames)], p.label, elapsed)
+	p.visible = true
+}
+
+// clear clears the progress line. p.mu must be held.
+func (p *progress) clear() {
+	if p.visible {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.visible = false
+	}
+}
+
+// wrap returns a writer that clears the progress line before writing block output.
+func (p *progress) wrap(w io.Writer) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.clear()
+		return w.Write(b)
+	})
+}
+
+// writerFunc is an adapter to use a function as an io.Writer.
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) {
+	return f(b)
+}
+
+// summarizeCommand returns the first line of a command shortened for display.
+func summarizeCommand(cmd string) string {
+	line, _, multi := strings.Cut(cmd, "\n")
+	if multi || len([]rune(line)) > maxLabelLen {
+		r := []rune(line)
+		return string(r[:min(len(r), maxLabelLen)]) + "…"
+	}
+	return line
+}
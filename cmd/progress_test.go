@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgress(&buf, 12)
+	out := p.wrap(&buf)
+
+	result := &runner.Result{
+		Index:     3,
+		Block:     parser.CodeBlock{Language: "sh"},
+		Command:   "make test",
+		StartedAt: time.Now(),
+	}
+	p.start(result)
+	_, _ = out.Write([]byte("output\n")) //nostyle:handlerrors
+	result.Duration = 1500 * time.Millisecond
+	result.Err = errors.New("exit status 1")
+	p.finish(result)
+
+	got := buf.String()
+	for _, want := range []string{
+		"Running [4/12] sh: make test … 0s",
+		"\r\033[Koutput\n",
+		"output\nFailed [4/12] sh: make test (1.5s)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q: %q", want, got)
+		}
+	}
+}
+
+func TestSummarizeCommand(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"make test", "make test"},
+		{"echo 1\necho 2", "echo 1…"},
+		{strings.Repeat("a", 70), strings.Repeat("a", 60) + "…"},
+	}
+	for _, tt := range tests {
+		if got := summarizeCommand(tt.in); got != tt.want {
+			t.Errorf("summarizeCommand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+var cliVars []string
+
+func init() {
+	rootCmd.PersistentFlags().StringArrayVar(&cliVars, "var", nil,
+		"value of {{vars.NAME}} (format: NAME=value); inputs given by flags are not asked (can be specified multiple times)")
+}
+
+// parseVars parses NAME=value specs of --var.
+func parseVars(specs []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q: must be NAME=value", spec)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// docInput is an input declared in the front matter.
+type docInput struct {
+	Name    string `yaml:"name"`
+	Prompt  string `yaml:"prompt"`
+	Default string `yaml:"default"`
+	Secret  bool   `yaml:"secret"`
+}
+
+// collectPrompts returns the inputs of the front matter and of the selected blocks in the order they appear.
+// The first declaration of a name wins.
+func collectPrompts(cfg *docConfig, blocks []parser.CodeBlock, sel func(parser.CodeBlock, int) bool) ([]runner.Prompt, error) {
+	var prompts []runner.Prompt
+	seen := map[string]bool{}
+	add := func(p runner.Prompt) {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			prompts = append(prompts, p)
+		}
+	}
+	for i, in := range cfg.Inputs {
+		if in.Name == "" {
+			return nil, fmt.Errorf("inputs[%d] in the front matter: name is required", i)
+		}
+		add(runner.Prompt{Name: in.Name, Message: in.Prompt, Default: in.Default, Secret: in.Secret})
+	}
+	for i, block := range blocks {
+		if sel != nil && !sel(block, i) {
+			continue
+		}
+		ps, err := runner.BlockPrompts(block)
+		if err != nil {
+			return nil, fmt.Errorf("code block %d: %w", i+1, err)
+		}
+		for _, p := range ps {
+			add(p)
+		}
+	}
+	return prompts, nil
+}
+
+// inputAsker asks for inputs on a terminal.
+type inputAsker struct {
+	in   *bufio.Reader
+	out  io.Writer
+	echo func(on bool) error // Turns the echo of the terminal on and off (nil if it cannot)
+}
+
+// ask sets the inputs that are not in vars, asking for them if interactive is set
+// and using their defaults otherwise.
+func (a *inputAsker) ask(prompts []runner.Prompt, vars map[string]string, interactive bool) error {
+	var missing []string
+	for _, p := range prompts {
+		if _, ok := vars[p.Name]; ok {
+			continue
+		}
+		if !interactive {
+			if p.Default == "" {
+				missing = append(missing, p.Name)
+				continue
+			}
+			vars[p.Name] = p.Default
+			continue
+		}
+		answer, err := a.askOne(p)
+		if err != nil {
+			return err
+		}
+		vars[p.Name] = answer
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("inputs are required: use --var %s=VALUE in non-interactive runs", strings.Join(missing, "=VALUE --var "))
+	}
+	return nil
+}
+
+// askOne asks for an input and returns the answer (the default if it is empty).
+func (a *inputAsker) askOne(p runner.Prompt) (string, error) {
+	msg := p.Message
+	if msg == "" {
+		msg = p.Name
+	}
+	if p.Default != "" && !p.Secret {
+		msg += fmt.Sprintf(" [%s]", p.Default)
+	}
+	fmt.Fprintf(a.out, "%s: ", msg)
+	if p.Secret && a.echo != nil {
+		if err := a.echo(false); err == nil {
+			defer func() {
+				_ = a.echo(true) //nostyle:handlerrors
+				fmt.Fprintln(a.out)
+			}()
+		}
+	}
+	answer, err := a.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read the answer: %w", err)
+	}
+	answer = strings.TrimRight(answer, "\r\n")
+	if answer == "" {
+		if errors.Is(err, io.EOF) && p.Default == "" {
+			// Stdin is closed, e.g., /dev/null in CI
+			return "", fmt.Errorf("no answer for input %s: use --var %s=VALUE in non-interactive runs", p.Name, p.Name)
+		}
+		return p.Default, nil
+	}
+	return answer, nil
+}
+
+// askInputs asks for the inputs of the front matter of source and of the selected blocks that are not given by --var.
+// They are asked on the terminal if the document is read from a file and stdin is a terminal.
+func askInputs(r *runner.Runner, source []byte, blocks []parser.CodeBlock, args []string) error {
+	source, err := decodeSource(source, encoding)
+	if err != nil {
+		return &parseError{err: err}
+	}
+	cfg, err := readDocConfig(source)
+	if err != nil {
+		return &parseError{err: err}
+	}
+	prompts, err := collectPrompts(cfg, blocks, r.Select)
+	if err != nil {
+		return &parseError{err: err}
+	}
+	if len(prompts) == 0 {
+		return nil
+	}
+	if r.Vars == nil {
+		r.Vars = map[string]string{}
+	}
+	interactive := len(args) > 0 && isTerminal(os.Stdin)
+	return tty.interact(func(in *bufio.Reader, out io.Writer) error {
+		a := &inputAsker{in: in, out: out, echo: func(on bool) error {
+			// stty changes the terminal it reads from
+			arg := "-echo"
+			if on {
+				arg = "echo"
+			}
+			cmd := exec.Command("stty", arg)
+			cmd.Stdin = os.Stdin
+			return cmd.Run()
+		}}
+		return a.ask(prompts, r.Vars, interactive)
+	})
+}
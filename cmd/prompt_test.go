@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestCollectPrompts(t *testing.T) {
+	cfg := &docConfig{Inputs: []docInput{{Name: "cluster", Prompt: "Cluster", Default: "staging"}}}
+	blocks := []parser.CodeBlock{
+		{Attributes: map[string]string{"prompt.cluster": "Another cluster", "prompt.token": "Token", "prompt.token.secret": "true"}},
+		{Attributes: map[string]string{"name": "skip", "prompt.region": "Region"}},
+	}
+	sel := func(block parser.CodeBlock, _ int) bool { return block.Name() != "skip" }
+	got, err := collectPrompts(cfg, blocks, sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []runner.Prompt{
+		{Name: "cluster", Message: "Cluster", Default: "staging"},
+		{Name: "token", Message: "Token", Secret: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestInputAsker(t *testing.T) {
+	prompts := []runner.Prompt{
+		{Name: "cluster", Message: "Cluster", Default: "staging"},
+		{Name: "token", Message: "Token", Default: "dev", Secret: true},
+		{Name: "region"},
+	}
+	t.Run("interactive", func(t *testing.T) {
+		var out bytes.Buffer
+		var echo []bool
+		a := &inputAsker{in: bufio.NewReader(strings.NewReader("\ns3cret\nus-east-1\n")), out: &out, echo: func(on bool) error {
+			echo = append(echo, on)
+			return nil
+		}}
+		vars := map[string]string{}
+		if err := a.ask(prompts, vars, true); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]string{"cluster": "staging", "token": "s3cret", "region": "us-east-1"}; !reflect.DeepEqual(vars, want) {
+			t.Errorf("got %v, want %v", vars, want)
+		}
+		if got, want := out.String(), "Cluster [staging]: Token: \nregion: "; got != want {
+			t.Errorf("got prompts %q, want %q", got, want)
+		}
+		if !reflect.DeepEqual(echo, []bool{false, true}) {
+			t.Errorf("got echo %v, want it turned off and on for the secret", echo)
+		}
+	})
+	t.Run("closed stdin", func(t *testing.T) {
+		var out bytes.Buffer
+		a := &inputAsker{in: bufio.NewReader(strings.NewReader("")), out: &out}
+		vars := map[string]string{}
+		err := a.ask(prompts, vars, true)
+		if err == nil || !strings.Contains(err.Error(), "no answer for input region") {
+			t.Fatalf("got err %v, want no answer for region", err)
+		}
+		if vars["cluster"] != "staging" || vars["token"] != "dev" {
+			t.Errorf("got %v, want the defaults", vars)
+		}
+	})
+	t.Run("given by flags", func(t *testing.T) {
+		var out bytes.Buffer
+		a := &inputAsker{in: bufio.NewReader(strings.NewReader("")), out: &out}
+		vars := map[string]string{"cluster": "prod", "token": "t", "region": "r"}
+		if err := a.ask(prompts, vars, true); err != nil {
+			t.Fatal(err)
+		}
+		if out.Len() != 0 || vars["cluster"] != "prod" {
+			t.Errorf("got prompts %q and vars %v, want nothing asked", out.String(), vars)
+		}
+	})
+	t.Run("non-interactive", func(t *testing.T) {
+		a := &inputAsker{}
+		vars := map[string]string{}
+		err := a.ask(prompts, vars, false)
+		if err == nil || !strings.Contains(err.Error(), "use --var region=VALUE") {
+			t.Fatalf("got err %v, want the missing input", err)
+		}
+		if vars["cluster"] != "staging" || vars["token"] != "dev" {
+			t.Errorf("got %v, want the defaults", vars)
+		}
+	})
+}
+
+func TestParseVars(t *testing.T) {
+	got, err := parseVars([]string{"cluster=prod", "query=a=b,c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"cluster": "prod", "query": "a=b,c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, err := parseVars([]string{"cluster"}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// Output formats selected by --format.
+const (
+	formatText     = "text"     // Stream the output of code blocks
+	formatQuickfix = "quickfix" // Print failed blocks as file:line:col: message
+)
+
+var outputFormat string
+
+func init() {
+	rootCmd.Flags().StringVar(&outputFormat, "format", formatText,
+		"output format (text: stream the output of blocks, quickfix: print file:line:col: message for failed blocks)")
+}
+
+// applyFormat configures r to write its output to w in the format.
+func applyFormat(r *runner.Runner, w io.Writer, file, format string) error {
+	switch format {
+	case formatText:
+		return nil
+	case formatQuickfix:
+		r.Stdout = io.Discard
+		r.Stderr = io.Discard
+		r.CaptureOutput = true
+		addResultHook(r, func(result *runner.Result) {
+			for _, e := range quickfixEntries(file, result) {
+				fmt.Fprintln(w, e)
+			}
+		})
+		return nil
+	default:
+		return fmt.Errorf("invalid --format %q: expected 'text' or 'quickfix'", format)
+	}
+}
+
+// stdinLinePatterns match references to lines of stdin in error messages of common interpreters.
+// The first submatch is the 1-based line number in the code block.
+var stdinLinePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:<stdin>|\[stdin\]|\bstdin):(\d+)`),     // <stdin>:3, [stdin]:3, stdin:3
+	regexp.MustCompile(`(?:^|[\s(])-:(\d+)`),                      // -:3 (ruby)
+	regexp.MustCompile(`File "<stdin>", line (\d+)`),              // python
+	regexp.MustCompile(`^(?:[\w/.-]*/)?(?:ba|z)?sh: line (\d+):`), // bash, zsh
+	regexp.MustCompile(`^(?:[\w/.-]*/)?sh: (\d+):`),               // dash
+	regexp.MustCompile(` at - line (\d+)`),                        // perl
+}
+
+// quickfixEntries returns the quickfix entries of a failed code block.
+// Lines of stderr referring to lines of stdin are mapped to the lines in the document;
+// if there are none, a single entry points to the opening fence.
+func quickfixEntries(file string, result *runner.Result) []string {
+	if result.Err == nil {
+		return nil
+	}
+	block := result.Block
+	var entries []string
+	for line := range strings.Lines(result.Stderr) {
+		line = strings.TrimSpace(line)
+		for _, re := range stdinLinePatterns {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n < 1 || block.Line+n >= block.EndLine {
+				continue
+			}
+			entries = append(entries, fmt.Sprintf("%s:%d:1: %s", file, block.Line+n, line))
+			break
+		}
+	}
+	if len(entries) > 0 {
+		return entries
+	}
+	msg := fmt.Sprintf("block %d (%s) failed: %v", result.Index+1, block.Language, result.Err)
+	if last := lastLine(result.Stderr); last != "" {
+		msg += ": " + last
+	}
+	return []string{fmt.Sprintf("%s:%d:1: %s", file, block.Line, msg)}
+}
+
+// lastLine returns the last non-empty line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
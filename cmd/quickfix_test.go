@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestQuickfixEntries(t *testing.T) {
+	// The content of the block is at lines 11-13 of the document
+	block := parser.CodeBlock{Language: "sh", Line: 10, EndLine: 14}
+
+	tests := []struct {
+		name   string
+		stderr string
+		err    error
+		want   []string
+	}{
+		{
+			name: "passed",
+		},
+		{
+			name:   "bash",
+			stderr: "bash: line 2: foo: command not found\n",
+			err:    errors.New("exit status 127"),
+			want:   []string{"doc.md:12:1: bash: line 2: foo: command not found"},
+		},
+		{
+			name:   "dash",
+			stderr: "sh: 3: foo: not found\n",
+			err:    errors.New("exit status 127"),
+			want:   []string{"doc.md:13:1: sh: 3: foo: not found"},
+		},
+		{
+			name:   "python",
+			stderr: "Traceback (most recent call last):\n  File \"<stdin>\", line 1, in <module>\nNameError: name 'x' is not defined\n",
+			err:    errors.New("exit status 1"),
+			want:   []string{"doc.md:11:1: File \"<stdin>\", line 1, in <module>"},
+		},
+		{
+			name:   "node and ruby",
+			stderr: "[stdin]:2\n-:3:in `<main>': oops (RuntimeError)\n",
+			err:    errors.New("exit status 1"),
+			want:   []string{"doc.md:12:1: [stdin]:2", "doc.md:13:1: -:3:in `<main>': oops (RuntimeError)"},
+		},
+		{
+			name:   "outside of the block",
+			stderr: "sh: 9: foo: not found\n",
+			err:    errors.New("exit status 127"),
+			want:   []string{"doc.md:10:1: block 3 (sh) failed: exit status 127: sh: 9: foo: not found"},
+		},
+		{
+			name: "no stderr",
+			err:  errors.New("exit status 1"),
+			want: []string{"doc.md:10:1: block 3 (sh) failed: exit status 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &runner.Result{Index: 2, Block: block, Stderr: tt.stderr, Err: tt.err}
+			got := quickfixEntries("doc.md", result)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// runRepeat runs all blocks n times (indefinitely if n < 2 and untilFailure is set)
+// and writes an aggregate pass/fail count to w.
+// If a run failed, the returned error wraps the error of the last failed run.
+func runRepeat(ctx context.Context, w io.Writer, r *runner.Runner, blocks []parser.CodeBlock, n int, untilFailure bool) error {
+	failures := make(map[int]int)
+	addResultHook(r, func(result *runner.Result) {
+		if result.Err != nil {
+			failures[result.Index]++
+		}
+	})
+
+	var (
+		runs, failed int
+		lastErr      error
+	)
+	for n < 2 || runs < n {
+		if err := ctx.Err(); err != nil {
+			if failed == 0 {
+				lastErr = err
+			}
+			break
+		}
+		runs++
+		if err := r.RunAll(ctx, blocks); err != nil {
+			failed++
+			lastErr = err
+			fmt.Fprintf(w, "Run %d failed: %v\n", runs, err)
+			if untilFailure {
+				break
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "Repeat summary: %d/%d runs passed, %d failed\n", runs-failed, runs, failed)
+	for i := range blocks {
+		if c := failures[i]; c > 0 {
+			fmt.Fprintf(w, "  block %d (%s): failed %d/%d\n", i+1, blocks[i].Language, c, runs)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d runs failed: %w", failed, runs, lastErr)
+	}
+	return lastErr
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestRunRepeat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	// The second block fails on every other run
+	counter := filepath.Join(t.TempDir(), "counter")
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo run >> " + counter},
+		{Language: "sh", Command: "test $(($(wc -l < " + counter + ") % 2)) -eq 1"},
+	}
+
+	tests := []struct {
+		name         string
+		n            int
+		untilFailure bool
+		wantRuns     int
+		wantSummary  string
+	}{
+		{
+			name:        "repeat",
+			n:           4,
+			wantRuns:    4,
+			wantSummary: "Repeat summary: 2/4 runs passed, 2 failed\n  block 2 (sh): failed 2/4\n",
+		},
+		{
+			name:         "until failure",
+			n:            4,
+			untilFailure: true,
+			wantRuns:     2,
+			wantSummary:  "Repeat summary: 1/2 runs passed, 1 failed\n  block 2 (sh): failed 1/2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(counter) //nostyle:handlerrors
+			var out, stdout, stderr bytes.Buffer
+			r := &runner.Runner{Stdout: &stdout, Stderr: &stderr}
+			if err := runRepeat(t.Context(), &out, r, blocks, tt.n, tt.untilFailure); err == nil {
+				t.Error("runRepeat() should return error when a run failed")
+			}
+			b, err := os.ReadFile(counter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Count(string(b), "run"); got != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", got, tt.wantRuns)
+			}
+			if got := out.String(); !strings.HasSuffix(got, tt.wantSummary) {
+				t.Errorf("summary = %q, want suffix %q", got, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestRunRepeat_LastError(t *testing.T) {
+	blocks := []parser.CodeBlock{{Language: "sh", Command: "echo denied"}}
+	var out bytes.Buffer
+	r := &runner.Runner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}, Policy: "false", PolicyAbort: true}
+	err := runRepeat(t.Context(), &out, r, blocks, 2, false)
+	if !errors.Is(err, runner.ErrPolicyDenied) {
+		t.Fatalf("runRepeat() error = %v, want ErrPolicyDenied", err)
+	}
+	if got := exitCode(err); got != exitCodePolicy {
+		t.Errorf("exitCode() = %d, want %d", got, exitCodePolicy)
+	}
+
+	// The exit policy applies to the failures of all runs
+	r = &runner.Runner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}, Policy: "false", PolicyAbort: true}
+	exitWith, err := applyExitPolicy(r, exitPolicyCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := exitCode(exitWith(runRepeat(t.Context(), &out, r, blocks, 3, false))); got != 3 {
+		t.Errorf("exitCode() = %d, want 3", got)
+	}
+}
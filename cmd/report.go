@@ -0,0 +1,183 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/render"
+	"github.com/k1LoW/runblock/runner"
+)
+
+var reportSpecs []string
+
+func init() {
+	rootCmd.Flags().StringArrayVar(&reportSpecs, "report", nil,
+		"write a run report (format: FORMAT=PATH, e.g., 'html=report.html')")
+}
+
+// Block statuses in a report.
+const (
+	statusPassed  = render.StatusPassed
+	statusFailed  = render.StatusFailed
+	statusSkipped = render.StatusSkipped
+)
+
+// report is the result of a run used to render reports.
+type report struct {
+	mu        sync.Mutex
+	File      string
+	StartedAt time.Time
+	Duration  time.Duration
+	Blocks    []reportBlock
+	Error     string // Error the run ended with (empty if none)
+	Source    []byte // The document (nil if not available)
+
+	Environment *reportEnvironment // nil if not probed
+	results     []*runner.Result
+}
+
+// reportBlock is the result of a code block in a report.
+type reportBlock struct {
+	Index      int
+	Line       int
+	EndLine    int
+	Name       string
+	Lang       string
+	Command    string
+	Status     string
+	SkipReason string
+	ExitCode   int
+	Duration   time.Duration
+	Stdout     string
+	Stderr     string
+	Artifacts  []string
+	Error      string
+}
+
+// reportFormats maps report formats to their renderers.
+var reportFormats = map[string]func(io.Writer, *report) error{
+	"annotated": renderAnnotatedReport,
+	"html":      renderHTMLReport,
+	"json":      renderJSONReport,
+	"md":        renderMarkdownReport,
+}
+
+// reportSpec is a parsed --report flag.
+type reportSpec struct {
+	format string
+	path   string
+}
+
+// parseReportSpecs parses --report flags.
+func parseReportSpecs(specs []string) ([]reportSpec, error) {
+	var parsed []reportSpec
+	for _, s := range specs {
+		format, path, ok := strings.Cut(s, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid report format %q: expected 'FORMAT=PATH'", s)
+		}
+		if _, ok := reportFormats[format]; !ok {
+			return nil, fmt.Errorf("unsupported report format %q", format)
+		}
+		parsed = append(parsed, reportSpec{format: format, path: path})
+	}
+	return parsed, nil
+}
+
+// newReport returns a report for a run of file starting now.
+func newReport(file string) *report {
+	return &report{File: file, StartedAt: time.Now()}
+}
+
+// record adds the result of a code block to the report.
+func (rp *report) record(result *runner.Result) {
+	b := reportBlock{
+		Index:      result.Index,
+		Line:       result.Block.Line,
+		EndLine:    result.Block.EndLine,
+		Name:       result.Block.Name(),
+		Lang:       result.Block.Language,
+		Command:    result.Command,
+		SkipReason: result.SkipReason,
+		ExitCode:   result.ExitCode,
+		Duration:   result.Duration,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		Artifacts:  result.Artifacts,
+		Status:     render.Status(result),
+	}
+	if result.Err != nil {
+		b.Error = result.Err.Error()
+	}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.Blocks = append(rp.Blocks, b)
+	rp.results = append(rp.results, result)
+}
+
+// Count returns the number of blocks with the status.
+func (rp *report) Count(status string) int {
+	n := 0
+	for _, b := range rp.Blocks {
+		if b.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// write finishes the report and writes it in every requested format.
+func (rp *report) write(specs []reportSpec) error {
+	rp.Duration = time.Since(rp.StartedAt)
+	// Only the JSON and HTML reports show the environment, which takes running the tools
+	if slices.ContainsFunc(specs, func(s reportSpec) bool { return s.format == "json" || s.format == "html" }) {
+		rp.Environment = probeEnvironment(context.Background(), rp.results)
+	}
+	var errs []error
+	for _, s := range specs {
+		if err := writeReport(s, rp); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write %s report: %w", s.format, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeReport writes the report to the file of the spec.
+func writeReport(s reportSpec, rp *report) (err error) {
+	f, err := os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	return reportFormats[s.format](f, rp)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"slices"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// reportEnvironment is the environment a run was executed in,
+// so that failures can be correlated with environment drift.
+// It is rendered as is in the JSON report.
+type reportEnvironment struct {
+	OS    string       `json:"os"`
+	Arch  string       `json:"arch"`
+	Shell reportTool   `json:"shell"`           // Shell used for commands with arguments
+	Tools []reportTool `json:"tools,omitempty"` // Executables of the commands of the blocks run, sorted by name
+}
+
+// reportTool is an executable in the environment of a run.
+type reportTool struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`    // Empty if not found
+	Version string `json:"version,omitempty"` // Empty if unknown
+}
+
+// probeEnvironment returns the environment of the results.
+// The versions of the tools are those reported by 'TOOL --version'.
+func probeEnvironment(ctx context.Context, results []*runner.Result) *reportEnvironment {
+	sh, _, _ := runner.BuildCommand("echo ok")
+	env := &reportEnvironment{
+		OS:    runtime.GOOS,
+		Arch:  runtime.GOARCH,
+		Shell: probeTool(ctx, sh),
+	}
+	var names []string
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+		fields := commandFields(result.Command)
+		if len(fields) == 0 || slices.Contains(names, fields[0]) {
+			continue
+		}
+		if _, err := exec.LookPath(fields[0]); err != nil {
+			continue // Shell keywords and builtins without an executable, such as if and cd
+		}
+		names = append(names, fields[0])
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		env.Tools = append(env.Tools, probeTool(ctx, name))
+	}
+	return env
+}
+
+// probeTool returns the path and the version of the executable name.
+func probeTool(ctx context.Context, name string) reportTool {
+	t := reportTool{Name: name}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return t
+	}
+	t.Path = path
+	t.Version, _ = runner.ProbeVersion(ctx, name) //nostyle:handlerrors
+	return t
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlReportTmpl is the template of the standalone HTML report.
+var htmlReportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"add":      func(a, b int) int { return a + b },
+	"duration": func(d time.Duration) string { return d.Round(time.Millisecond).String() },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>runblock report: {{.File}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table.summary td { padding: 0 1em 0 0; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin: 0.5em 0; padding: 0.5em 1em; }
+summary { cursor: pointer; font-weight: 600; }
+pre { background: #f6f8fa; padding: 0.75em; overflow-x: auto; white-space: pre-wrap; }
+.status { display: inline-block; border-radius: 1em; padding: 0 0.6em; color: #fff; font-size: 0.85em; }
+.passed { background: #1a7f37; }
+.failed { background: #cf222e; }
+.skipped { background: #6e7781; }
+</style>
+</head>
+<body>
+<h1>runblock report</h1>
+<table class="summary">
+<tr><td>File</td><td>{{.File}}</td></tr>
+<tr><td>Started at</td><td>{{.StartedAt.Format "2006-01-02T15:04:05Z07:00"}}</td></tr>
+<tr><td>Duration</td><td>{{duration .Duration}}</td></tr>
+<tr><td>Result</td><td>{{.Count "passed"}} passed, {{.Count "failed"}} failed, {{.Count "skipped"}} skipped</td></tr>
+{{with .Environment}}<tr><td>Platform</td><td>{{.OS}}/{{.Arch}}</td></tr>
+<tr><td>Shell</td><td>{{template "tool" .Shell}}</td></tr>
+{{range .Tools}}<tr><td>{{.Name}}</td><td>{{template "tool" .}}</td></tr>
+{{end}}{{end}}</table>
+{{range .Blocks}}
+<details{{if eq .Status "failed"}} open{{end}}>
+<summary><span class="status {{.Status}}">{{.Status}}</span> Block {{add .Index 1}}{{if .Name}} ({{.Name}}){{end}} {{.Lang}} &mdash; {{duration .Duration}}</summary>
+{{if .Command}}<p>Command</p>
+<pre>{{.Command}}</pre>{{end}}
+{{if .SkipReason}}<p>Skipped: {{.SkipReason}}</p>{{end}}
+{{if .Error}}<p>Error (exit code {{.ExitCode}})</p>
+<pre>{{.Error}}</pre>{{end}}
+{{if .Stdout}}<p>stdout</p>
+<pre>{{.Stdout}}</pre>{{end}}
+{{if .Stderr}}<p>stderr</p>
+<pre>{{.Stderr}}</pre>{{end}}
+{{if .Artifacts}}<p>Artifacts</p>
+<ul>{{range .Artifacts}}<li><a href="{{.}}">{{.}}</a></li>{{end}}</ul>{{end}}
+</details>
+{{end}}
+</body>
+</html>
+{{define "tool"}}{{if not .Path}}not found{{else}}{{.Path}} ({{or .Version "unknown version"}}){{end}}{{end}}`))
+
+// renderHTMLReport renders the report as a standalone HTML document.
+func renderHTMLReport(w io.Writer, rp *report) error {
+	return htmlReportTmpl.Execute(w, rp)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonReport is the report rendered as JSON.
+type jsonReport struct {
+	File        string             `json:"file"`
+	StartedAt   time.Time          `json:"started_at"`
+	DurationMS  int64              `json:"duration_ms"`
+	Status      string             `json:"status"`
+	Passed      int                `json:"passed"`
+	Failed      int                `json:"failed"`
+	Skipped     int                `json:"skipped"`
+	Error       string             `json:"error,omitempty"`
+	Environment *reportEnvironment `json:"environment,omitempty"`
+	Blocks      []jsonReportBlock  `json:"blocks"`
+}
+
+// jsonReportBlock is the result of a code block in the JSON report.
+type jsonReportBlock struct {
+	Index      int      `json:"index"`
+	Line       int      `json:"line"`
+	EndLine    int      `json:"end_line"`
+	Name       string   `json:"name,omitempty"`
+	Lang       string   `json:"lang"`
+	Command    string   `json:"command"`
+	Status     string   `json:"status"`
+	SkipReason string   `json:"skip_reason,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	DurationMS int64    `json:"duration_ms"`
+	Stdout     string   `json:"stdout,omitempty"`
+	Stderr     string   `json:"stderr,omitempty"`
+	Artifacts  []string `json:"artifacts,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// renderJSONReport renders the report as a JSON document for tools.
+// The status of the run is failed if a block failed or the run ended with an error.
+func renderJSONReport(w io.Writer, rp *report) error {
+	jr := jsonReport{
+		File:        rp.File,
+		StartedAt:   rp.StartedAt,
+		DurationMS:  rp.Duration.Milliseconds(),
+		Status:      statusPassed,
+		Passed:      rp.Count(statusPassed),
+		Failed:      rp.Count(statusFailed),
+		Skipped:     rp.Count(statusSkipped),
+		Error:       rp.Error,
+		Environment: rp.Environment,
+		Blocks:      []jsonReportBlock{},
+	}
+	if jr.Failed > 0 || jr.Error != "" {
+		jr.Status = statusFailed
+	}
+	for _, b := range rp.Blocks {
+		jr.Blocks = append(jr.Blocks, jsonReportBlock{
+			Index:      b.Index,
+			Line:       b.Line,
+			EndLine:    b.EndLine,
+			Name:       b.Name,
+			Lang:       b.Lang,
+			Command:    b.Command,
+			Status:     b.Status,
+			SkipReason: b.SkipReason,
+			ExitCode:   b.ExitCode,
+			DurationMS: b.Duration.Milliseconds(),
+			Stdout:     b.Stdout,
+			Stderr:     b.Stderr,
+			Artifacts:  b.Artifacts,
+			Error:      b.Error,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jr)
+}
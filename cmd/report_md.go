@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/runblock/render"
+)
+
+// renderMarkdownReport renders the report as a Markdown document
+// with a status table and the output of each block in fenced code blocks.
+func renderMarkdownReport(w io.Writer, rp *report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# runblock report: %s\n\n", rp.File)
+	fmt.Fprintf(&b, "%d passed, %d failed, %d skipped in %s\n\n",
+		rp.Count(statusPassed), rp.Count(statusFailed), rp.Count(statusSkipped), rp.Duration.Round(time.Millisecond))
+
+	b.WriteString("| # | Name | Lang | Command | Status | Duration |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, blk := range rp.Blocks {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s %s | %s |\n",
+			blk.Index+1, tableCell(blk.Name), tableCell(blk.Lang), tableCode(summarizeCommand(blk.Command)),
+			render.Badge(blk.Status), blk.Status, blk.Duration.Round(time.Millisecond))
+	}
+
+	for _, blk := range rp.Blocks {
+		if blk.Status == statusSkipped {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## Block %d", blk.Index+1)
+		if blk.Name != "" {
+			fmt.Fprintf(&b, " (%s)", blk.Name)
+		}
+		fmt.Fprintf(&b, " %s %s\n\n", render.Badge(blk.Status), blk.Status)
+		writeFenced(&b, "sh", blk.Command)
+		if blk.Error != "" {
+			fmt.Fprintf(&b, "\nError: %s\n", blk.Error)
+		}
+		if blk.Stdout != "" {
+			b.WriteString("\nstdout:\n\n")
+			writeFenced(&b, "", blk.Stdout)
+		}
+		if blk.Stderr != "" {
+			b.WriteString("\nstderr:\n\n")
+			writeFenced(&b, "", blk.Stderr)
+		}
+		if len(blk.Artifacts) > 0 {
+			b.WriteString("\nArtifacts:\n\n")
+			for _, a := range blk.Artifacts {
+				fmt.Fprintf(&b, "- `%s`\n", a)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFenced writes s in a fenced code block longer than any backtick run in s.
+func writeFenced(b *strings.Builder, lang, s string) {
+	b.WriteString(render.Fenced(lang, s) + "\n")
+}
+
+// renderAnnotatedReport renders the document with the result of each block annotated after it.
+func renderAnnotatedReport(w io.Writer, rp *report) error {
+	if rp.Source == nil {
+		return errors.New("the document is not available")
+	}
+	return render.Markdown(w, rp.Source, rp.results)
+}
+
+// tableCell escapes s for a Markdown table cell.
+func tableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// tableCode formats s as inline code in a Markdown table cell.
+func tableCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = tableCell(s)
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence + " " + s + " " + fence
+}
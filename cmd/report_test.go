@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// newTestReport returns a report with passed, failed and skipped blocks.
+func newTestReport() *report {
+	rp := newReport("runbook.md")
+	rp.record(&runner.Result{
+		Index:     0,
+		Block:     parser.CodeBlock{Language: "sh", Attributes: map[string]string{"name": "hello"}},
+		Command:   "echo <hello>",
+		Duration:  10 * time.Millisecond,
+		Stdout:    "<hello>\n",
+		Artifacts: []string{"artifacts/block-1-hello/out.txt"},
+	})
+	rp.record(&runner.Result{
+		Index:    1,
+		Block:    parser.CodeBlock{Language: "sh"},
+		Command:  "exit 1",
+		ExitCode: 1,
+		Err:      errors.New("exit status 1"),
+		Stderr:   "oops\n",
+	})
+	rp.record(&runner.Result{
+		Index:      2,
+		Block:      parser.CodeBlock{Language: "text"},
+		Skipped:    true,
+		SkipReason: "no command specified",
+	})
+	return rp
+}
+
+func TestParseReportSpecs(t *testing.T) {
+	specs, err := parseReportSpecs([]string{"html=out/report.html"})
+	if err != nil {
+		t.Fatalf("parseReportSpecs() error = %v", err)
+	}
+	if len(specs) != 1 || specs[0].format != "html" || specs[0].path != "out/report.html" {
+		t.Errorf("parseReportSpecs() = %+v", specs)
+	}
+	for _, invalid := range []string{"html", "html=", "pdf=report.pdf"} {
+		if _, err := parseReportSpecs([]string{invalid}); err == nil {
+			t.Errorf("parseReportSpecs(%q) should return error", invalid)
+		}
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	rp := newTestReport()
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := rp.write([]reportSpec{{format: "html", path: path}}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"<title>runblock report: runbook.md</title>",
+		"1 passed, 1 failed, 1 skipped",
+		`<span class="status passed">passed</span> Block 1 (hello) sh`,
+		"<pre>echo &lt;hello&gt;</pre>",
+		"<details open>\n<summary><span class=\"status failed\">failed</span> Block 2",
+		"<pre>oops\n</pre>",
+		"Skipped: no command specified",
+		`<li><a href="artifacts/block-1-hello/out.txt">artifacts/block-1-hello/out.txt</a></li>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, rp); err != nil {
+		t.Fatalf("renderHTMLReport() error = %v", err)
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	rp := newTestReport()
+	rp.Blocks[0].Stdout = "```\nnested\n```\n"
+	var buf bytes.Buffer
+	if err := renderMarkdownReport(&buf, rp); err != nil {
+		t.Fatalf("renderMarkdownReport() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# runblock report: runbook.md\n",
+		"1 passed, 1 failed, 1 skipped",
+		"| 1 | hello | sh | ` echo <hello> ` | ✅ passed | 10ms |\n",
+		"| 3 |  | text |  | ⏭️ skipped | 0s |\n",
+		"## Block 2 ❌ failed\n\n```sh\nexit 1\n```\n\nError: exit status 1\n",
+		"stdout:\n\n````\n```\nnested\n```\n````\n",
+		"stderr:\n\n```\noops\n```\n",
+		"Artifacts:\n\n- `artifacts/block-1-hello/out.txt`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "## Block 3") {
+		t.Error("skipped blocks should not have a section")
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	rp := newTestReport()
+	var buf bytes.Buffer
+	if err := renderJSONReport(&buf, rp); err != nil {
+		t.Fatalf("renderJSONReport() error = %v", err)
+	}
+	var got jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.File != "runbook.md" || got.Status != statusFailed || got.Passed != 1 || got.Failed != 1 || got.Skipped != 1 {
+		t.Errorf("summary = %+v", got)
+	}
+	if len(got.Blocks) != 3 || got.Blocks[1].Error != "exit status 1" || got.Blocks[1].Stderr != "oops\n" || got.Blocks[0].DurationMS != 10 {
+		t.Errorf("blocks = %+v", got.Blocks)
+	}
+}
+
+func TestWriteResultFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".runblock", "last_run.json")
+	rp := newReport("runbook.md")
+	if err := writeResultFile(path, rp, errors.New("failed to parse markdown")); err != nil {
+		t.Fatalf("writeResultFile() error = %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got jsonReport
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != statusFailed || got.Error != "failed to parse markdown" || got.Blocks == nil {
+		t.Errorf("result = %+v", got)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary file should not be left")
+	}
+}
+
+func TestAnnotatedReport(t *testing.T) {
+	rp := newTestReport()
+	if err := renderAnnotatedReport(&bytes.Buffer{}, rp); err == nil {
+		t.Error("renderAnnotatedReport() should return error without the document")
+	}
+	rp.Source = []byte("# Hello\n\n```sh\necho\n```\n")
+	rp.results[0].Block.EndLine = 5
+	var buf bytes.Buffer
+	if err := renderAnnotatedReport(&buf, rp); err != nil {
+		t.Fatalf("renderAnnotatedReport() error = %v", err)
+	}
+	if want := "```\n\n**✅ passed** (exit code 0, 10ms)\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("report does not contain %q:\n%s", want, buf.String())
+	}
+}
+
+func TestProbeEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a tool")
+	}
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "faketool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\necho faketool version 1.2.3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	env := probeEnvironment(context.Background(), []*runner.Result{
+		{Command: "FOO=1 faketool run"},
+		{Command: "if true; then :; fi"},
+		{Command: "faketool again"},
+		{Command: "skippedtool", Skipped: true},
+	})
+	if env.OS != runtime.GOOS || env.Arch != runtime.GOARCH || env.Shell.Name == "" {
+		t.Errorf("probeEnvironment() = %+v", env)
+	}
+	want := []reportTool{{Name: "faketool", Path: tool, Version: "1.2.3"}}
+	if !reflect.DeepEqual(env.Tools, want) {
+		t.Errorf("Tools = %+v, want %+v", env.Tools, want)
+	}
+}
+
+func TestReportEnvironment(t *testing.T) {
+	rp := newTestReport()
+	rp.Environment = &reportEnvironment{
+		OS:    "linux",
+		Arch:  "amd64",
+		Shell: reportTool{Name: "bash", Path: "/bin/bash", Version: "5.2.15"},
+		Tools: []reportTool{{Name: "python3", Path: "/usr/bin/python3"}, {Name: "node"}},
+	}
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, rp); err != nil {
+		t.Fatalf("renderHTMLReport() error = %v", err)
+	}
+	for _, want := range []string{
+		"<tr><td>Platform</td><td>linux/amd64</td></tr>",
+		"<tr><td>Shell</td><td>/bin/bash (5.2.15)</td></tr>",
+		"<tr><td>python3</td><td>/usr/bin/python3 (unknown version)</td></tr>",
+		"<tr><td>node</td><td>not found</td></tr>",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+
+	buf.Reset()
+	if err := renderJSONReport(&buf, rp); err != nil {
+		t.Fatalf("renderJSONReport() error = %v", err)
+	}
+	var got jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Environment, rp.Environment) {
+		t.Errorf("environment = %+v, want %+v", got.Environment, rp.Environment)
+	}
+}
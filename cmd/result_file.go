@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultResultFile is the well-known path of the result file.
+const defaultResultFile = ".runblock/last_run.json"
+
+var resultFile string
+
+func init() {
+	rootCmd.Flags().StringVar(&resultFile, "result-file", "",
+		"always write the result of the run as JSON to the file at the end of the run, atomically")
+	rootCmd.Flags().Lookup("result-file").NoOptDefVal = defaultResultFile
+}
+
+// writeResultFile writes the JSON report of a run ending with runErr to path atomically,
+// so that readers never see a partially written file.
+func writeResultFile(path string, rp *report, runErr error) error {
+	rp.Duration = time.Since(rp.StartedAt)
+	if runErr != nil {
+		rp.Error = runErr.Error()
+	}
+	var buf bytes.Buffer
+	if err := renderJSONReport(&buf, rp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create result file directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write result file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
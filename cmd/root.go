@@ -29,6 +29,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -43,7 +44,45 @@ import (
 var (
 	defaultCommand string
 	commands       []string
+	plugins        []string
+	langAliases    []string
+	defaultLangs   []string
+	honorShebang   bool
+	mergeAdjacent  bool
+	encoding       string
+	normalizeCRLF  bool
 	watch          bool
+	traceTemplates bool
+	rawContent     bool
+	auditLogPath   string
+	allowHashes    string
+	signaturePath  string
+	publicKeyPath  string
+	policyPath     string
+	policyAction   string
+	maxOutput      string
+	detectBinary   bool
+	combineOutput  bool
+	stderrTo       string
+	showProgress   bool
+	interval       time.Duration
+	runTimeout     time.Duration
+	repeat         int
+	untilFailure   bool
+	exitPolicy     string
+	niceness       int
+	asUser         string
+	readOnly       bool
+	allowWrite     []string
+	artifactsDir   string
+	keepTmp        bool
+	parallel       map[string]int
+	keepSiblings   bool
+	requires       []string
+	rate           string
+	tagRates       map[string]string
+	normalizers    []string
+	replacements   []string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -62,16 +101,46 @@ Template variables are supported:
   {{lang}}    - Language identifier of the code block
   {{content}} - Content of the code block
   {{i}}       - Index of the code block (0-based)
+  {{env}}     - Map of environment variables set by env.NAME attributes
+  {{tmpdir}}  - Temporary directory of the run (deleted at the end)
+  {{chunk}}   - Chunk of the content divided by the split attribute
+  {{heading}} - Text of the nearest heading before the code block
+  {{slug}}    - Slug of the heading for file names (e.g., "set-up-the-db")
+  {{id}}      - Stable short hash of the file path, the enclosing headings and the content
+  {{prev}}    - Result of the previous block (prev.exit_code, prev.duration_ms, prev.stdout, ...)
+  {{vars}}    - Inputs given by --var or asked by prompt.NAME attributes (e.g., vars.cluster)
+  {{secrets}} - Secrets of --secrets-from, masked as *** in the output (e.g., secrets.API_KEY)
+  {{os}}      - Operating system runblock runs on (linux, darwin, windows, ...)
+  {{arch}}    - Architecture runblock runs on (amd64, arm64, ...)
+
+Attributes can be specified in braces after the language:
+
+    ` + "```sh {env.FOO=bar} sh -c 'echo $FOO'" + `
+
+A block with use=NAME (or a language set by --use) is run by the executor plugin
+runblock-exec-NAME in PATH, which receives a JSON descriptor of the block on stdin.
 
 Environment variables are also set:
   CODEBLOCK_LANG    - Language identifier
   CODEBLOCK_CONTENT - Content of the code block
   CODEBLOCK_INDEX   - Index of the code block (0-based)
-
-The code block content is also passed via stdin.`,
-	Args:    cobra.MaximumNArgs(1),
-	RunE:    run,
-	Version: version.Version,
+  CODEBLOCK_COMMAND - Fully expanded command
+  CODEBLOCK_TMPDIR  - Temporary directory of the run
+  CODEBLOCK_ID      - Stable ID of the code block (same as {{id}})
+
+The code block content is also passed via stdin.
+
+Exit status:
+  0 - All blocks succeeded
+  1 - A block failed (or another error occurred)
+  2 - The document could not be parsed
+  3 - The policy denied a block (--policy-action abort)
+  4 - The run timed out (--timeout)
+With --exit-policy count, the exit status is the number of failed blocks instead.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE:              run,
+	Version:           version.Version,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -79,20 +148,96 @@ The code block content is also passed via stdin.`,
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
-	rootCmd.Flags().StringVar(&defaultCommand, "default-command", "",
+	rootCmd.PersistentFlags().StringVar(&defaultCommand, "default-command", "",
 		"default command for code blocks without explicit command")
-	rootCmd.Flags().StringArrayVarP(&commands, "command", "c", nil,
+	rootCmd.PersistentFlags().StringSliceVar(&defaultLangs, "default-command-langs", nil,
+		"apply the default command only to blocks with the languages (comma separated, e.g., 'sh,bash')")
+	rootCmd.PersistentFlags().BoolVar(&mergeAdjacent, "merge-adjacent", false,
+		"merge consecutive blocks with the same language, command and attributes into one execution unit")
+	rootCmd.PersistentFlags().StringVar(&encoding, "encoding", "",
+		"character encoding of the document (e.g., 'shift_jis', 'euc-jp', 'utf-16le') (default: UTF-8)")
+	rootCmd.PersistentFlags().BoolVar(&normalizeCRLF, "normalize-newlines", false,
+		"convert CRLF line endings in block content to LF before execution")
+	rootCmd.PersistentFlags().BoolVar(&honorShebang, "honor-shebang", false,
+		"execute blocks without a command whose content starts with #! as scripts with the interpreter")
+	rootCmd.PersistentFlags().StringArrayVarP(&commands, "command", "c", nil,
 		"command for specific language (format: lang:command, e.g., 'go:gofmt')")
+	rootCmd.PersistentFlags().StringArrayVar(&plugins, "use", nil,
+		"executor plugin for specific language (format: lang:plugin, e.g., 'sql:bigquery' runs runblock-exec-bigquery)")
+	rootCmd.PersistentFlags().StringArrayVar(&langAliases, "alias", nil,
+		"equivalent language identifiers for commands and filters (format: lang=alias[=alias...], e.g., 'shell=sh=bash')")
+	rootCmd.PersistentFlags().BoolVar(&traceTemplates, "trace-templates", false,
+		"log every template expression, the values it saw and its result to stderr")
+	rootCmd.PersistentFlags().BoolVar(&rawContent, "allow-content-interpolation", false,
+		"allow commands to interpolate {{content}} and {{chunk}} into the shell unquoted (prefer stdin or shquote())")
+	rootCmd.PersistentFlags().StringVar(&policyPath, "policy", "",
+		"CEL policy file evaluated per block; blocks it denies are not executed")
+	rootCmd.PersistentFlags().StringVar(&policyAction, "policy-action", "abort",
+		"action when the policy denies a block (skip|abort)")
+	rootCmd.Flags().StringVar(&maxOutput, "max-output", "",
+		"maximum output size streamed per block and stream (e.g., 64KB, 1MB)")
+	rootCmd.Flags().BoolVar(&detectBinary, "detect-binary", false,
+		"replace binary output with a notice and a hex preview")
+	rootCmd.Flags().BoolVar(&combineOutput, "combine-output", false,
+		"merge stderr into stdout as one ordered stream")
+	rootCmd.Flags().StringVar(&stderrTo, "stderr-to", "",
+		"write stderr of blocks to the file instead of the terminal")
+	rootCmd.Flags().BoolVar(&showProgress, "progress", false,
+		"show the running block and its elapsed time on stderr (only when stderr is a terminal)")
+	rootCmd.Flags().IntVar(&niceness, "nice", 0,
+		"run block processes with the niceness (-20 to 19; also sets the I/O priority on Linux)")
+	rootCmd.Flags().StringVar(&asUser, "as-user", "",
+		"run block processes as the user (directly when running as root, via sudo otherwise); asks for confirmation unless --yes")
+	rootCmd.Flags().BoolVar(&readOnly, "read-only", false,
+		"run block processes without write access to the filesystem (Linux only; uses Landlock or bubblewrap)")
+	rootCmd.Flags().StringArrayVar(&allowWrite, "allow-write", nil,
+		"path block processes can write to with --read-only (can be specified multiple times)")
+	rootCmd.Flags().StringVar(&artifactsDir, "artifacts-dir", "",
+		"copy the files matching the artifacts attribute of blocks into per-block directories under the directory")
+	rootCmd.Flags().BoolVar(&keepTmp, "keep-tmp", false,
+		"keep the temporary directory of the run ({{tmpdir}}) instead of deleting it at the end")
+	rootCmd.Flags().StringToIntVar(&parallel, "parallel", nil,
+		"run blocks of the languages concurrently up to the number per language (e.g., 'go=4,python=2'); blocks of other languages run alone")
+	rootCmd.Flags().BoolVar(&keepSiblings, "no-cancel-on-failure", false,
+		"with --parallel, let concurrent blocks in flight finish when a block fails instead of canceling them")
+	rootCmd.Flags().StringArrayVar(&requires, "require", nil,
+		"check that tools are installed in the versions before running any block, like the requires attribute (e.g., 'go>=1.22,node>=20')")
+	rootCmd.Flags().StringArrayVar(&normalizers, "normalize", nil,
+		"normalize the output of every block before expect-stdout~, expect-stderr~ and assert compare it, like the normalize attribute (ansi,timestamps,uuids,space)")
+	rootCmd.Flags().StringArrayVar(&replacements, "replace", nil,
+		"replace the matches of the regular expression with <NAME> in the output of every block before it is compared, like replace.NAME attributes (format: NAME=REGEXP)")
+	rootCmd.Flags().StringVar(&rate, "rate", "",
+		"limit how often block processes are started across parallel blocks (format: N/UNIT, e.g., '10/min', '2/s')")
+	rootCmd.Flags().StringToStringVar(&tagRates, "tag-rate", nil,
+		"rate limits overriding --rate for blocks with the tags (e.g., 'api=5/min')")
+	rootCmd.Flags().DurationVar(&interval, "interval", 0,
+		"pause between block executions (e.g., 2s)")
+	rootCmd.Flags().DurationVar(&runTimeout, "timeout", 0,
+		"abort the run after the duration; teardown and always=true blocks still run (e.g., 10m)")
+	rootCmd.Flags().IntVar(&repeat, "repeat", 1,
+		"run the blocks N times and report an aggregate pass/fail count")
+	rootCmd.Flags().BoolVar(&untilFailure, "until-failure", false,
+		"stop repeating at the first failed run (repeats indefinitely without --repeat)")
+	rootCmd.Flags().StringVar(&exitPolicy, "exit-policy", exitPolicyFirst,
+		"exit status policy (first: stop at the first failure, all: run all blocks and fail if any failed, count: exit with the number of failed blocks)")
+	rootCmd.PersistentFlags().StringVar(&auditLogPath, "audit-log", "",
+		"append every executed command to the audit log file (JSON Lines)")
+	rootCmd.PersistentFlags().StringVar(&allowHashes, "allow-hashes", "",
+		"only execute documents whose SHA-256 hash is listed in the file")
+	rootCmd.PersistentFlags().StringVar(&publicKeyPath, "public-key", "",
+		"only execute documents with a detached signature verified by the Ed25519 public key (PEM)")
+	rootCmd.PersistentFlags().StringVar(&signaturePath, "signature", "",
+		"detached signature of the document (default: MARKDOWN_FILE.sig)")
 	rootCmd.Flags().BoolVarP(&watch, "watch", "w", false,
 		"watch the file for changes and re-run on modifications")
 }
 
-func run(cmd *cobra.Command, args []string) error {
+func run(cmd *cobra.Command, args []string) (err error) {
 	ctx := cmd.Context()
 	if ctx == nil {
 		ctx = context.Background()
@@ -103,15 +248,379 @@ func run(cmd *cobra.Command, args []string) error {
 		return errors.New("--watch requires a file argument (cannot watch stdin)")
 	}
 
+	if watchFailedFirst && !watch {
+		return errors.New("--failed-first requires --watch")
+	}
+
+	if confirmChanges && !watch {
+		return errors.New("--confirm-changes requires --watch")
+	}
+
+	// Record the run so that it can be repeated by rerun (a document read from stdin cannot)
+	if len(args) > 0 && !noHistory {
+		e := newHistoryEntry(cmd, args[0])
+		defer func() {
+			e.ExitCode = exitCode(err)
+			if herr := recordHistory(historyPath, e); herr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", herr)
+			}
+		}()
+	}
+
 	if watch {
 		return runWatch(ctx, args[0])
 	}
 
-	return runOnce(ctx, args)
+	// Cancel the run on interrupt so that teardown blocks still run; a second interrupt terminates immediately
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	return runOnce(ctx, args, nil, nil)
+}
+
+// runOnce runs the code blocks of the file in args (or stdin).
+// If ff is not nil, only the blocks that have not passed in previous runs are run.
+// If diff is not nil, the blocks changed since the previous run are shown first
+// (and confirmed with --confirm-changes; declined changes are shown again on the next run).
+func runOnce(ctx context.Context, args []string, ff *failedFirst, diff *watchDiff) (err error) {
+	// The result file is written even if the run ends before any block runs (e.g., a parse error)
+	var rp *report
+	if resultFile != "" {
+		rp = newReport(sourceName(args))
+		defer func() {
+			err = errors.Join(err, writeResultFile(resultFile, rp, err))
+		}()
+	}
+
+	if runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, runTimeout)
+		defer cancel()
+	}
+
+	if abortFile != "" {
+		var stop func()
+		ctx, stop, err = withAbortFile(ctx, abortFile, abortFilePollInterval)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	source, err := readSource(args)
+	if err != nil {
+		return err
+	}
+
+	// Refuse documents that are not approved
+	if err := verifySource(source, args); err != nil {
+		return err
+	}
+
+	// Parse markdown
+	blocks, err := parseBlocks(source)
+	if err != nil {
+		return err
+	}
+	if diff != nil {
+		changes := diff.show(os.Stderr, blocks)
+		if confirmChanges {
+			if err := confirmBlockChanges(os.Stderr, args, changes); err != nil {
+				return err
+			}
+		}
+		diff.record(blocks)
+	}
+
+	// Execute code blocks
+	r, err := newRunner()
+	if err != nil {
+		return err
+	}
+	if err := applyDocConfig(r, source); err != nil {
+		return err
+	}
+	r.File = sourceName(args)
+	if err := checkPosition(blocks, r.Select); err != nil {
+		return err
+	}
+	if onlyChanged {
+		if err := selectChanged(ctx, r, args); err != nil {
+			return err
+		}
+	}
+	if err := askInputs(r, source, blocks, args); err != nil {
+		return err
+	}
+	if err := applyFormat(r, os.Stdout, sourceName(args), outputFormat); err != nil {
+		return err
+	}
+	if asUser != "" {
+		q := fmt.Sprintf("Run %d block(s) of %s as user %q?", countSelected(blocks, r.Select), sourceName(args), asUser)
+		if err := confirmOnTerminal(args, q); err != nil {
+			return err
+		}
+		r.User = asUser
+	}
+	if ff != nil {
+		ff.apply(os.Stderr, r, blocks)
+		defer func() {
+			ff.finish(err)
+		}()
+	}
+
+	if force && !useState && cacheURL == "" {
+		return errors.New("--force requires --state or --cache")
+	}
+	if useState {
+		if len(args) == 0 {
+			return errors.New("--state requires a file argument (cannot record stdin)")
+		}
+		state, err := loadState(args[0])
+		if err != nil {
+			return err
+		}
+		state.attach(os.Stderr, r, force)
+		defer func() {
+			err = errors.Join(err, state.Err())
+		}()
+	}
+	if cacheURL != "" {
+		backend, err := newCacheBackend(cacheURL)
+		if err != nil {
+			return err
+		}
+		c := &remoteCache{backend: backend, file: sourceName(args)}
+		c.attach(os.Stderr, r, force)
+	}
+
+	// Blocks exchange files through the temporary directory of the run
+	tmpDir, err := os.MkdirTemp("", "runblock-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	r.TmpDir = tmpDir
+	defer func() {
+		if keepTmp {
+			fmt.Fprintf(os.Stderr, "Temporary directory kept: %s\n", tmpDir)
+			return
+		}
+		err = errors.Join(err, os.RemoveAll(tmpDir))
+	}()
+
+	if auditLogPath != "" {
+		audit, err := openAuditLog(auditLogPath, sourceName(args))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, audit.Close())
+		}()
+		addResultHook(r, audit.record)
+	}
+
+	if stderrTo != "" {
+		if combineOutput {
+			return errors.New("--stderr-to cannot be used with --combine-output")
+		}
+		f, err := os.Create(stderrTo)
+		if err != nil {
+			return fmt.Errorf("failed to open --stderr-to file: %w", err)
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		r.Stderr = f
+	}
+
+	if groupOutput || silentSuccess {
+		ci, err := newCIFormat(ciFormatName)
+		if err != nil {
+			return err
+		}
+		newOutputGroups(r.Stdout, ci, silentSuccess).attach(r)
+	}
+	if outputFormat == formatText {
+		decoded, err := decodeSource(source, encoding)
+		if err != nil {
+			return err
+		}
+		addResultHook(r, failureExcerpt(os.Stderr, sourceName(args), decoded))
+	}
+
+	if timestamps != "" {
+		stamp, err := newTimestamper(timestamps, time.Now(), time.Now)
+		if err != nil {
+			return err
+		}
+		r.Stdout = &stampWriter{w: r.Stdout, stamp: stamp}
+		if !combineOutput {
+			r.Stderr = &stampWriter{w: r.Stderr, stamp: stamp}
+		}
+	}
+
+	if logFile != "" {
+		logs, err := newBlockLogs(logFile, sourceName(args))
+		if err != nil {
+			return err
+		}
+		logs.attach(r)
+		defer func() {
+			err = errors.Join(err, logs.Err())
+		}()
+	}
+
+	if heartbeatInterval > 0 {
+		newHeartbeat(os.Stderr, heartbeatInterval).attach(r)
+	}
+
+	if eventsFormat != "" {
+		events, err := openEventLog(eventsFormat, eventsTo)
+		if err != nil {
+			return err
+		}
+		events.attach(r, sourceName(args), blocks)
+		defer func() {
+			err = errors.Join(err, events.Close(err))
+		}()
+	}
+
+	if showProgress && isTerminal(os.Stderr) {
+		p := newProgress(os.Stderr, countSelected(blocks, r.Select))
+		r.Stdout = p.wrap(r.Stdout)
+		r.Stderr = p.wrap(r.Stderr)
+		addStartHook(r, p.start)
+		addResultHook(r, p.finish)
+	}
+
+	exitWith, err := applyExitPolicy(r, exitPolicy)
+	if err != nil {
+		return err
+	}
+
+	specs, err := parseReportSpecs(reportSpecs)
+	if err != nil {
+		return err
+	}
+	if len(specs) > 0 || notifyURL != "" || githubCheck || rp != nil {
+		var check *checkRunConfig
+		if githubCheck {
+			check, err = newCheckRunConfig(args)
+			if err != nil {
+				return err
+			}
+		}
+		if rp == nil {
+			rp = newReport(sourceName(args))
+		}
+		rp.Source, err = decodeSource(source, encoding)
+		if err != nil {
+			return err
+		}
+		r.CaptureOutput = len(specs) > 0 || notifyFailures || githubCheck || resultFile != ""
+		addResultHook(r, rp.record)
+		defer func() {
+			err = errors.Join(err, rp.write(specs))
+			if notifyURL != "" {
+				err = errors.Join(err, notify(ctx, notifyURL, rp, notifyFailures))
+			}
+			if check != nil {
+				err = errors.Join(err, createCheckRun(ctx, check, rp))
+			}
+		}()
+	}
+
+	if repeat > 1 || untilFailure {
+		err = runRepeat(ctx, os.Stderr, r, blocks, repeat, untilFailure)
+	} else {
+		err = r.RunAll(ctx, blocks)
+	}
+	if errors.Is(err, runner.ErrContentInterpolation) {
+		err = fmt.Errorf("%w (use --allow-content-interpolation to allow it)", err)
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: timed out after %s", context.DeadlineExceeded, runTimeout)
+	}
+	if cause := context.Cause(ctx); err != nil && errors.Is(cause, errAborted) {
+		err = fmt.Errorf("%w: %w", cause, err)
+	}
+	return exitWith(err)
+}
+
+// addStartHook adds fn to the hooks called before the command of every code block is started.
+func addStartHook(r *runner.Runner, fn func(*runner.Result)) {
+	prev := r.OnStart
+	if prev == nil {
+		r.OnStart = fn
+		return
+	}
+	r.OnStart = func(result *runner.Result) {
+		prev(result)
+		fn(result)
+	}
 }
 
-func runOnce(ctx context.Context, args []string) error {
-	// Read input
+// addResultHook adds fn to the hooks called with the result of every code block.
+func addResultHook(r *runner.Runner, fn func(*runner.Result)) {
+	prev := r.OnResult
+	if prev == nil {
+		r.OnResult = fn
+		return
+	}
+	r.OnResult = func(result *runner.Result) {
+		prev(result)
+		fn(result)
+	}
+}
+
+// readBlocks reads Markdown from the file in args (or stdin) and parses its code blocks.
+func readBlocks(args []string) ([]parser.CodeBlock, error) {
+	source, err := readSource(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBlocks(source)
+}
+
+// parseBlocks parses the code blocks of Markdown source, decoding it with --encoding,
+// applying the env and tags of its front matter, normalizing newlines with --normalize-newlines
+// and merging adjacent blocks with --merge-adjacent.
+// Signatures and hashes of the document are computed over the source as read.
+func parseBlocks(source []byte) ([]parser.CodeBlock, error) {
+	source, err := decodeSource(source, encoding)
+	if err != nil {
+		return nil, &parseError{err: err}
+	}
+	blocks, err := parser.Parse(source)
+	if err != nil {
+		return nil, &parseError{err: fmt.Errorf("failed to parse markdown: %w", err)}
+	}
+	cfg, err := readDocConfig(source)
+	if err != nil {
+		return nil, &parseError{err: err}
+	}
+	cfg.applyBlocks(blocks)
+	if normalizeCRLF {
+		blocks = parser.NormalizeNewlines(blocks)
+	}
+	if mergeAdjacent {
+		blocks = parser.MergeAdjacent(blocks)
+	}
+	return blocks, nil
+}
+
+// sourceName returns the name of the input for records and reports ("-" for stdin).
+func sourceName(args []string) string {
+	if len(args) == 0 {
+		return "-"
+	}
+	return args[0]
+}
+
+// readSource reads Markdown from the file in args (or stdin).
+func readSource(args []string) ([]byte, error) {
 	var source []byte
 	var err error
 
@@ -123,25 +632,137 @@ func runOnce(ctx context.Context, args []string) error {
 		source, err = os.ReadFile(args[0])
 	}
 	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
+		return nil, fmt.Errorf("failed to read input: %w", err)
 	}
 
-	// Parse markdown
-	blocks, err := parser.Parse(source)
-	if err != nil {
-		return fmt.Errorf("failed to parse markdown: %w", err)
-	}
+	return source, nil
+}
 
+// newRunner creates a Runner configured from the command line flags.
+func newRunner() (*runner.Runner, error) {
 	// Parse language-specific commands
 	cmdMap, err := parseCommands(commands)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	pluginMap, err := parseCommands(plugins)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --use: %w", err)
+	}
+
+	aliases, err := runner.ParseAliases(langAliases)
+	if err != nil {
+		return nil, err
 	}
 
-	// Execute code blocks
 	r := runner.New(defaultCommand, cmdMap)
+	r.Plugins = pluginMap
+	r.Aliases = aliases
+	r.DefaultLangs = defaultLangs
+	r.HonorShebang = honorShebang
+	r.RawContent = rawContent
+	r.Select = newSelector(aliases)
+	if traceTemplates {
+		r.Trace = os.Stderr
+	}
+
+	if maxOutput != "" {
+		size, err := parseSize(maxOutput)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-output: %w", err)
+		}
+		r.MaxOutput = size
+	}
+	r.DetectBinary = detectBinary
+	r.CombineOutput = combineOutput
+	r.Interval = interval
+	for lang, n := range parallel {
+		if n < 1 {
+			return nil, fmt.Errorf("invalid --parallel %s=%d: must be at least 1", lang, n)
+		}
+	}
+	r.Parallel = parallel
+	r.KeepSiblings = keepSiblings
+	if len(secretsFrom) > 0 {
+		secrets, err := loadSecrets(secretsFrom)
+		if err != nil {
+			return nil, err
+		}
+		r.Secrets = secrets
+	}
+	if len(cliVars) > 0 {
+		vars, err := parseVars(cliVars)
+		if err != nil {
+			return nil, err
+		}
+		r.Vars = vars
+	}
+	for _, spec := range requires {
+		reqs, err := runner.ParseRequirements(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --require: %w", err)
+		}
+		r.Requires = append(r.Requires, reqs...)
+	}
+	for _, spec := range normalizers {
+		names, err := runner.ParseNormalizers(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --normalize: %w", err)
+		}
+		r.Normalizers = append(r.Normalizers, names...)
+	}
+	for _, spec := range replacements {
+		name, expr, err := runner.ParseReplacement(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --replace: %w", err)
+		}
+		if r.Replacements == nil {
+			r.Replacements = map[string]string{}
+		}
+		r.Replacements[name] = expr
+	}
+	if rate != "" {
+		l, err := runner.ParseRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --rate: %w", err)
+		}
+		r.RateLimit = l
+	}
+	for tag, v := range tagRates {
+		l, err := runner.ParseRate(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --tag-rate %s: %w", tag, err)
+		}
+		if r.TagRateLimits == nil {
+			r.TagRateLimits = map[string]*runner.Limiter{}
+		}
+		r.TagRateLimits[tag] = l
+	}
+	r.Nice = niceness
+	if len(allowWrite) > 0 && !readOnly {
+		return nil, errors.New("--allow-write requires --read-only")
+	}
+	r.ReadOnly = readOnly
+	r.AllowWrite = allowWrite
+	r.ArtifactsDir = artifactsDir
+
+	if policyPath != "" {
+		policy, err := os.ReadFile(policyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		r.Policy = string(policy)
+		switch policyAction {
+		case "abort":
+			r.PolicyAbort = true
+		case "skip":
+		default:
+			return nil, fmt.Errorf("invalid --policy-action %q: expected 'skip' or 'abort'", policyAction)
+		}
+	}
 
-	return r.RunAll(ctx, blocks)
+	return r, nil
 }
 
 func runWatch(ctx context.Context, filePath string) error {
@@ -170,9 +791,15 @@ func runWatch(ctx context.Context, filePath string) error {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	var ff *failedFirst
+	if watchFailedFirst {
+		ff = &failedFirst{}
+	}
+	diff := &watchDiff{}
+
 	// Run once initially
 	fmt.Fprintf(os.Stderr, "Watching %s for changes...\n", absPath)
-	if err := runOnce(ctx, []string{filePath}); err != nil {
+	if err := runOnce(ctx, []string{filePath}, ff, diff); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 	}
 
@@ -215,7 +842,7 @@ func runWatch(ctx context.Context, filePath string) error {
 			}
 
 			fmt.Fprintf(os.Stderr, "\nFile changed, re-running...\n")
-			if err := runOnce(ctx, []string{filePath}); err != nil {
+			if err := runOnce(ctx, []string{filePath}, ff, diff); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			}
 		}
@@ -242,3 +869,30 @@ func parseCommands(cmds []string) (map[string]string, error) {
 	}
 	return result, nil
 }
+
+// parseSize parses a size such as "1024", "64KB" or "1MB" into bytes.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	v := strings.ToUpper(strings.TrimSpace(s))
+	factor := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			factor = u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * factor, nil
+}
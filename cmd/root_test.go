@@ -25,7 +25,6 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"testing"
 
@@ -34,10 +33,6 @@ import (
 )
 
 func TestRunBlock_FromFile(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	testFile := filepath.Join("..", "testdata", "basic.md")
 	source, err := os.ReadFile(testFile)
 	if err != nil {
@@ -48,6 +43,7 @@ func TestRunBlock_FromFile(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to parse markdown: %v", err)
 	}
+	blocks = portableBlocks(t, blocks)
 
 	var stdout, stderr bytes.Buffer
 	r := &runner.Runner{
@@ -72,10 +68,6 @@ func TestRunBlock_FromFile(t *testing.T) {
 }
 
 func TestRunBlock_FromStdin(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	// Simulate stdin content
 	content := "```sh cat\nstdin content\n```\n"
 	source := []byte(content)
@@ -84,6 +76,7 @@ func TestRunBlock_FromStdin(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to parse markdown: %v", err)
 	}
+	blocks = portableBlocks(t, blocks)
 
 	var stdout, stderr bytes.Buffer
 	r := &runner.Runner{
@@ -105,10 +98,6 @@ func TestRunBlock_FromStdin(t *testing.T) {
 }
 
 func TestRunBlock_WithDefaultCommand(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	testFile := filepath.Join("..", "testdata", "mixed.md")
 	source, err := os.ReadFile(testFile)
 	if err != nil {
@@ -119,10 +108,11 @@ func TestRunBlock_WithDefaultCommand(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to parse markdown: %v", err)
 	}
+	blocks = portableBlocks(t, blocks)
 
 	var stdout, stderr bytes.Buffer
 	r := &runner.Runner{
-		DefaultCommand: "cat", // Default command for blocks without command
+		DefaultCommand: catCommand(t), // Default command for blocks without command
 		Stdout:         &stdout,
 		Stderr:         &stderr,
 	}
@@ -149,10 +139,6 @@ func TestRunBlock_WithDefaultCommand(t *testing.T) {
 }
 
 func TestRunBlock_MixedBlocks(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	testFile := filepath.Join("..", "testdata", "with_template.md")
 	source, err := os.ReadFile(testFile)
 	if err != nil {
@@ -189,10 +175,6 @@ func TestRunBlock_MixedBlocks(t *testing.T) {
 }
 
 func TestRunBlock_CELExpression(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	// Test CEL ternary expression
 	content := "```go echo {{ lang == \"\" ? \"none\" : lang }}\npackage main\n```\n"
 	source := []byte(content)
@@ -222,10 +204,6 @@ func TestRunBlock_CELExpression(t *testing.T) {
 }
 
 func TestRunBlock_CELExpressionEmptyLang(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	// Test CEL ternary expression with empty lang using default command
 	// Note: When lang is empty, we can't specify command in info string
 	// So we use default command with CEL expression
@@ -257,11 +235,12 @@ func TestRunBlock_CELExpressionEmptyLang(t *testing.T) {
 }
 
 func TestRunOnce(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
+	testFile := filepath.Join(t.TempDir(), "basic.md")
+	if err := os.WriteFile(testFile, []byte("# Test\n\n```sh\nhello world\n```\n"), 0o600); err != nil {
+		t.Fatal(err)
 	}
-
-	testFile := filepath.Join("..", "testdata", "basic.md")
+	commands = []string{"sh:" + catCommand(t)}
+	t.Cleanup(func() { commands = nil })
 
 	// Capture original stdout
 	oldStdout := os.Stdout
@@ -271,7 +250,7 @@ func TestRunOnce(t *testing.T) {
 	// Reset defaultCommand
 	defaultCommand = ""
 
-	err := runOnce(t.Context(), []string{testFile})
+	err := runOnce(t.Context(), []string{testFile}, nil, nil)
 	if err != nil {
 		t.Fatalf("runOnce() error = %v", err)
 	}
@@ -287,3 +266,30 @@ func TestRunOnce(t *testing.T) {
 		t.Errorf("stdout does not contain 'hello world': %q", got)
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"64KB", 64 << 10, false},
+		{"1mb", 1 << 20, false},
+		{"2 GB", 2 << 30, false},
+		{"10B", 10, false},
+		{"abc", 0, true},
+		{"-1", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+// scriptCmd represents the script command
+var scriptCmd = &cobra.Command{
+	Use:   "script [MARKDOWN_FILE]",
+	Short: "Emit the resolved commands of the code blocks as a POSIX shell script",
+	Long: `script writes a POSIX shell script running the resolved commands of the code blocks
+in their run order instead of executing them, so that the equivalent script can be reviewed or shipped.
+
+    runblock script runbook.md > run.sh
+
+The content of each block is passed to its command on stdin with a heredoc. Like runblock,
+the script stops running blocks at the first failure except for teardown and always=true blocks,
+and exits with the status of the failure. CODEBLOCK_CONTENT is not set (the content is on stdin),
+and assertions and artifacts are not included.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := readSource(args)
+		if err != nil {
+			return err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return err
+		}
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		if err := applyDocConfig(r, source); err != nil {
+			return err
+		}
+		r.File = sourceName(args)
+		// Templates refer to the temporary directory created by the script
+		r.TmpDir = "$CODEBLOCK_TMPDIR"
+		p, err := newPlan(r, sourceName(args), source, blocks)
+		if err != nil {
+			return err
+		}
+		return script(cmd.OutOrStdout(), p, blocks)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scriptCmd)
+}
+
+// heredocDelimiter is the delimiter of the heredocs passing the content of blocks.
+const heredocDelimiter = "RUNBLOCK_EOF"
+
+// script writes the steps of p as a POSIX shell script to w.
+func script(w io.Writer, p *executionPlan, blocks []parser.CodeBlock) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#!/bin/sh\n# Generated by runblock from %s (sha256:%s)\n", p.Source, p.SHA256)
+	b.WriteString("status=0\n")
+	b.WriteString("CODEBLOCK_TMPDIR=$(mktemp -d) || exit\n")
+	b.WriteString("export CODEBLOCK_TMPDIR\n")
+	b.WriteString("trap 'rm -rf \"$CODEBLOCK_TMPDIR\"' EXIT\n")
+	for _, step := range p.Steps {
+		block := blocks[step.Index]
+		label := step.Lang
+		if name := block.Name(); name != "" {
+			label = name
+		}
+		fmt.Fprintf(&b, "\n# Block %d: %s (line %d)\n", step.Index+1, label, step.Line)
+		if step.Skip {
+			fmt.Fprintf(&b, "# skipped: %s\n", step.SkipReason)
+			continue
+		}
+		switch {
+		case step.Source == runner.SourcePlugin || step.Source == runner.SourceExecutor:
+			return fmt.Errorf("code block %d: blocks run by the %s %q cannot be emitted as a script", step.Index+1, step.Source, step.Command)
+		case hasAttr(block, runner.AttrSplit):
+			return fmt.Errorf("code block %d: split blocks cannot be emitted as a script", step.Index+1)
+		case hasAttr(block, runner.AttrPipeTo):
+			return fmt.Errorf("code block %d: piped blocks cannot be emitted as a script", step.Index+1)
+		}
+
+		// Only run after a failure if the block runs regardless
+		if !step.Always {
+			b.WriteString(`[ "$status" -ne 0 ] || `)
+		}
+		content := block.Content
+		stdin, redirect := runner.StdinPath(p.Source, block)
+		if !redirect && content != "" && !strings.HasSuffix(content, "\n") {
+			// A heredoc always ends with a newline
+			fmt.Fprintf(&b, "printf '%%s' %s | ", runner.ShellQuote(content))
+		}
+		b.WriteString("(\n")
+		if step.Dir != "" {
+			fmt.Fprintf(&b, "  cd %s || exit\n", runner.ShellQuote(step.Dir))
+		}
+		for _, e := range step.Env {
+			k, v, _ := strings.Cut(e, "=")
+			if k == "CODEBLOCK_CONTENT" {
+				// Read from the heredoc instead of repeating the content
+				continue
+			}
+			fmt.Fprintf(&b, "  export %s=%s\n", k, runner.ShellQuote(v))
+		}
+		fmt.Fprintf(&b, "  %s\n)", step.Command)
+		switch {
+		case redirect:
+			fmt.Fprintf(&b, " <%s || status=$?\n", runner.ShellQuote(stdin))
+		case content == "":
+			b.WriteString(" </dev/null || status=$?\n")
+		case strings.HasSuffix(content, "\n"):
+			delim := heredocDelimiterFor(content)
+			fmt.Fprintf(&b, " <<'%s' || status=$?\n%s%s\n", delim, content, delim)
+		default:
+			b.WriteString(" || status=$?\n")
+		}
+	}
+	b.WriteString("\nexit \"$status\"\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hasAttr reports whether the block has the attribute.
+func hasAttr(block parser.CodeBlock, key string) bool {
+	_, ok := block.Attributes[key]
+	return ok
+}
+
+// heredocDelimiterFor returns a heredoc delimiter that is not a line of content.
+func heredocDelimiterFor(content string) string {
+	lines := strings.Split(content, "\n")
+	for i := 0; ; i++ {
+		delim := heredocDelimiter
+		if i > 0 {
+			delim = fmt.Sprintf("%s_%d", heredocDelimiter, i)
+		}
+		if !slices.Contains(lines, delim) {
+			return delim
+		}
+	}
+}
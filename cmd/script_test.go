@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "cat {{tmpdir}}/greeting", Attributes: map[string]string{"stage": "teardown"}},
+		{Language: "sh", Command: "sh", Content: "echo \"$GREETING\" > \"$CODEBLOCK_TMPDIR/greeting\"\necho 'it''s' RUNBLOCK_EOF\n", Attributes: map[string]string{"env.GREETING": "hello world"}},
+		{Language: "text", Content: "plain\n"},
+		{Language: "sh", Command: "cat", Content: "RUNBLOCK_EOF\nno newline"},
+		{Language: "sh", Command: "exit 3"},
+		{Language: "sh", Command: "echo not reached"},
+	}
+	r := runner.New("", nil)
+	r.TmpDir = "$CODEBLOCK_TMPDIR"
+	p, err := newPlan(r, "runbook.md", []byte("source"), blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := script(&buf, p, blocks); err != nil {
+		t.Fatalf("script() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "# skipped: no command specified") {
+		t.Errorf("script does not mention the skipped block:\n%s", buf.String())
+	}
+
+	out, err := exec.Command("sh", "-c", buf.String()).Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("script should exit with 3: %v\n%s", err, buf.String())
+	}
+	if want := "its RUNBLOCK_EOF\nRUNBLOCK_EOF\nno newlinehello world\n"; string(out) != want {
+		t.Errorf("output = %q, want %q\n%s", out, want, buf.String())
+	}
+}
+
+func TestScript_Workdir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("services", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: `sh -c 'basename "$PWD"'`, Attributes: map[string]string{runner.AttrWorkdir: "services/api"}},
+		{Language: "sh", Command: "echo back", Attributes: map[string]string{runner.AttrWorkdir: "missing"}},
+	}
+	p, err := newPlan(runner.New("", nil), "runbook.md", []byte("source"), blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := script(&buf, p, blocks); err != nil {
+		t.Fatalf("script() error = %v", err)
+	}
+	out, err := exec.Command("sh", "-c", buf.String()).Output()
+	if err == nil {
+		t.Fatalf("script should fail in a missing directory\n%s", buf.String())
+	}
+	if want := "api\n"; string(out) != want {
+		t.Errorf("output = %q, want %q\n%s", out, want, buf.String())
+	}
+}
+
+func TestScript_Unsupported(t *testing.T) {
+	for _, block := range []parser.CodeBlock{
+		{Language: "sh", Command: "sh", Content: "a\n\nb\n", Attributes: map[string]string{"split": ""}},
+		{Language: "sh", Content: "echo\n", Attributes: map[string]string{"use": "remote"}},
+		{Language: "sh", Command: "cat", Attributes: map[string]string{"pipe-to": "sort"}},
+	} {
+		blocks := []parser.CodeBlock{block}
+		p, err := newPlan(runner.New("", nil), "runbook.md", []byte("source"), blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := script(&bytes.Buffer{}, p, blocks); err == nil {
+			t.Errorf("script() should return error for %v", block.Attributes)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+var secretsFrom []string
+
+func init() {
+	rootCmd.PersistentFlags().StringArrayVar(&secretsFrom, "secrets-from", nil,
+		"provider of {{secrets.NAME}}, masked as *** in the output (env: RUNBLOCK_SECRET_NAME variables, file:PATH, exec:COMMAND; can be specified multiple times)")
+}
+
+// secretEnvPrefix is the prefix of the environment variables of the env secret provider.
+const secretEnvPrefix = "RUNBLOCK_SECRET_"
+
+// loadSecrets loads the secrets of the providers. Later providers override earlier ones.
+func loadSecrets(providers []string) (map[string]string, error) {
+	secrets := map[string]string{}
+	for _, p := range providers {
+		kind, arg, _ := strings.Cut(p, ":")
+		var s map[string]string
+		var err error
+		switch kind {
+		case "env":
+			s = secretsFromEnv(os.Environ())
+		case "file":
+			var b []byte
+			if b, err = os.ReadFile(arg); err == nil {
+				s, err = parseSecrets(b)
+			}
+		case "exec":
+			s, err = secretsFromCommand(arg)
+		default:
+			return nil, fmt.Errorf("invalid --secrets-from %q: must be env, file:PATH or exec:COMMAND", p)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load secrets from %s: %w", p, err)
+		}
+		maps.Copy(secrets, s)
+	}
+	return secrets, nil
+}
+
+// secretsFromEnv returns the secrets in the RUNBLOCK_SECRET_NAME variables of environ.
+func secretsFromEnv(environ []string) map[string]string {
+	secrets := map[string]string{}
+	for _, e := range environ {
+		k, v, _ := strings.Cut(e, "=")
+		if name, ok := strings.CutPrefix(k, secretEnvPrefix); ok && name != "" {
+			secrets[name] = v
+		}
+	}
+	return secrets
+}
+
+// secretsFromCommand runs the command with the shell and parses its stdout, so that secret managers
+// such as 'op' and 'vault' can provide the secrets.
+func secretsFromCommand(command string) (map[string]string, error) {
+	if command == "" {
+		return nil, fmt.Errorf("command is empty")
+	}
+	name, args, err := runner.BuildCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseSecrets(out)
+}
+
+// parseSecrets parses a JSON object of strings or NAME=value lines (blank lines and # comments are ignored;
+// values can be quoted).
+func parseSecrets(b []byte) (map[string]string, error) {
+	secrets := map[string]string{}
+	if trimmed := bytes.TrimSpace(b); bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &secrets); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return secrets, nil
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: must be NAME=value", n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		secrets[name] = value
+	}
+	return secrets, s.Err()
+}
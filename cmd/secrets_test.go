@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestLoadSecrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secrets.env")
+	if err := os.WriteFile(file, []byte("# comment\nexport DB_PASSWORD=\"p@ss word\"\n\nAPI_KEY='k1'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RUNBLOCK_SECRET_API_KEY", "from-env")
+	t.Setenv("RUNBLOCK_SECRET_TOKEN", "t0ken")
+
+	tests := []struct {
+		name      string
+		providers []string
+		want      map[string]string
+		wantErr   bool
+	}{
+		{"env", []string{"env"}, map[string]string{"API_KEY": "from-env", "TOKEN": "t0ken"}, false},
+		{"file", []string{"file:" + file}, map[string]string{"DB_PASSWORD": "p@ss word", "API_KEY": "k1"}, false},
+		{"exec json", []string{`exec:echo '{"API_KEY": "from-exec"}'`}, map[string]string{"API_KEY": "from-exec"}, false},
+		{"later wins", []string{"file:" + file, "env"}, map[string]string{"DB_PASSWORD": "p@ss word", "API_KEY": "from-env", "TOKEN": "t0ken"}, false},
+		{"missing file", []string{"file:" + filepath.Join(dir, "missing")}, nil, true},
+		{"failing command", []string{"exec:exit 1"}, nil, true},
+		{"unknown provider", []string{"vault"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadSecrets(tt.providers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSecrets_Invalid(t *testing.T) {
+	for _, s := range []string{"NAME\n", "=value\n", "{not json"} {
+		if _, err := parseSecrets([]byte(s)); err == nil {
+			t.Errorf("parseSecrets(%q): expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+var (
+	names     []string
+	langs     []string
+	skipLangs []string
+	atLine    int
+	atOffset  int
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringArrayVarP(&names, "name", "n", nil,
+		"run only blocks with the name (can be specified multiple times)")
+	rootCmd.PersistentFlags().StringArrayVar(&langs, "lang", nil,
+		"run only blocks with the language (can be specified multiple times)")
+	rootCmd.PersistentFlags().StringSliceVar(&skipLangs, "skip-lang", nil,
+		"never run blocks with the languages, even with a default command (comma separated, e.g., 'text,mermaid,plaintext')")
+	_ = rootCmd.RegisterFlagCompletionFunc("name", completeBlockValues(parser.CodeBlock.Name)) //nostyle:handlerrors
+	_ = rootCmd.RegisterFlagCompletionFunc("lang", completeBlockValues(blockLanguage))         //nostyle:handlerrors
+	_ = rootCmd.RegisterFlagCompletionFunc("skip-lang", completeBlockValues(blockLanguage))    //nostyle:handlerrors
+	rootCmd.PersistentFlags().IntVar(&atLine, "at-line", 0,
+		"run only the block containing the 1-based line (e.g., the line under the cursor in an editor)")
+	rootCmd.PersistentFlags().IntVar(&atOffset, "at-offset", -1,
+		"run only the block containing the 0-based byte offset")
+}
+
+// newSelector returns a function selecting blocks by the command line flags, or nil if all blocks are selected.
+// Languages are matched with all the identifiers of a block and their aliases.
+func newSelector(aliases runner.Aliases) func(parser.CodeBlock, int) bool {
+	var filters []func(parser.CodeBlock) bool
+	if len(names) > 0 {
+		filters = append(filters, func(block parser.CodeBlock) bool {
+			return slices.Contains(names, block.Name())
+		})
+	}
+	if len(langs) > 0 {
+		filters = append(filters, func(block parser.CodeBlock) bool {
+			return aliases.MatchBlock(block, langs)
+		})
+	}
+	if len(skipLangs) > 0 {
+		filters = append(filters, func(block parser.CodeBlock) bool {
+			return !aliases.MatchBlock(block, skipLangs)
+		})
+	}
+	if atLine > 0 {
+		filters = append(filters, func(block parser.CodeBlock) bool {
+			return block.Line <= atLine && atLine <= block.EndLine
+		})
+	}
+	if atOffset >= 0 {
+		filters = append(filters, func(block parser.CodeBlock) bool {
+			return block.Offset <= atOffset && atOffset < block.EndOffset
+		})
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	return func(block parser.CodeBlock, _ int) bool {
+		for _, f := range filters {
+			if !f(block) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// checkPosition returns an error if --at-line or --at-offset is given but no block is selected.
+func checkPosition(blocks []parser.CodeBlock, sel func(parser.CodeBlock, int) bool) error {
+	if atLine <= 0 && atOffset < 0 {
+		return nil
+	}
+	if countSelected(blocks, sel) > 0 {
+		return nil
+	}
+	if atLine > 0 {
+		return fmt.Errorf("no code block at line %d", atLine)
+	}
+	return fmt.Errorf("no code block at offset %d", atOffset)
+}
+
+// countSelected returns the number of blocks selected by sel.
+func countSelected(blocks []parser.CodeBlock, sel func(parser.CodeBlock, int) bool) int {
+	if sel == nil {
+		return len(blocks)
+	}
+	n := 0
+	for i, b := range blocks {
+		if sel(b, i) {
+			n++
+		}
+	}
+	return n
+}
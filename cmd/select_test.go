@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestNewSelector(t *testing.T) {
+	source := []byte("# Title\n\n```sh {name=a}\necho a\n```\n\ntext\n\n```bash {name=b}\necho b\n```\n")
+	blocks, err := parser.Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		names    []string
+		langs    []string
+		aliases  []string
+		skip     []string
+		atLine   int
+		atOffset int
+		want     []int
+		wantErr  bool
+	}{
+		{name: "all", atOffset: -1, want: []int{0, 1}},
+		{name: "name", names: []string{"b"}, atOffset: -1, want: []int{1}},
+		{name: "lang", langs: []string{"bash", "go"}, atOffset: -1, want: []int{1}},
+		{name: "lang alias", langs: []string{"shell"}, aliases: []string{"shell=sh=bash"}, atOffset: -1, want: []int{0, 1}},
+		{name: "skip lang", skip: []string{"text", "bash"}, atOffset: -1, want: []int{0}},
+		{name: "skip lang alias", skip: []string{"shell"}, aliases: []string{"shell=sh=bash"}, atOffset: -1, want: nil},
+		{name: "opening fence", atLine: 3, atOffset: -1, want: []int{0}},
+		{name: "content", atLine: 10, atOffset: -1, want: []int{1}},
+		{name: "closing fence", atLine: 5, atOffset: -1, want: []int{0}},
+		{name: "outside", atLine: 7, atOffset: -1, wantErr: true},
+		{name: "line and name", names: []string{"a"}, atLine: 10, atOffset: -1, wantErr: true},
+		{name: "offset", atOffset: 60, want: []int{1}},
+		{name: "offset outside", atOffset: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, langs, skipLangs, atLine, atOffset = tt.names, tt.langs, tt.skip, tt.atLine, tt.atOffset
+			t.Cleanup(func() { names, langs, skipLangs, atLine, atOffset = nil, nil, nil, 0, -1 })
+
+			aliases, err := runner.ParseAliases(tt.aliases)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sel := newSelector(aliases)
+			var got []int
+			for i, b := range blocks {
+				if sel == nil || sel(b, i) {
+					got = append(got, i)
+				}
+			}
+			err = checkPosition(blocks, sel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkPosition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
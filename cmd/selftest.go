@@ -0,0 +1,248 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+//go:embed selftest/selftest.md
+var selftestDocument []byte
+
+// selftestTimeout is the time a self-test check waits for the environment.
+const selftestTimeout = 2 * time.Second
+
+var printSelftest bool
+
+// selftestCmd represents the selftest command
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run an embedded example document to check the features supported in this environment",
+	Long: `selftest runs an example document embedded in runblock against the local environment
+(the shell, stdin, environment variables, temporary directories, pipes, ...) and checks
+the termination of blocks, file watching for --watch and the terminal, reporting a matrix
+of the features that work. It is useful when debugging platform-specific problems.
+
+Use --print to print the example document, e.g., to run it with other flags:
+
+    runblock selftest --print > selftest.md
+    runblock --read-only selftest.md
+
+selftest exits with a non-zero status if a feature does not work.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if printSelftest {
+			_, err := cmd.OutOrStdout().Write(selftestDocument)
+			return err
+		}
+		return selftest(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	selftestCmd.Flags().BoolVar(&printSelftest, "print", false,
+		"print the example document instead of running it")
+	rootCmd.AddCommand(selftestCmd)
+}
+
+// selfCheck is the result of checking a feature.
+type selfCheck struct {
+	feature string
+	status  string // ok, FAILED or - (for information only)
+	detail  string
+}
+
+func (c *selfCheck) set(err error, detail string) {
+	if err != nil {
+		c.status = "FAILED"
+		c.detail = firstLine(err.Error())
+		return
+	}
+	c.status = "ok"
+	c.detail = detail
+}
+
+// selftest runs the example document and the checks of the environment, and writes the matrix of the features to w.
+func selftest(ctx context.Context, w io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tmpDir, err := os.MkdirTemp("", "runblock-selftest-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }() //nostyle:handlerrors
+
+	checks, err := selftestDocumentChecks(ctx, tmpDir)
+	if err != nil {
+		return err
+	}
+	checks = append(checks, selftestTermination(ctx), selftestWatch(tmpDir), selftestTerminal())
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FEATURE\tRESULT\tDETAIL")
+	failed := 0
+	for _, c := range checks {
+		if c.status == "FAILED" {
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.feature, c.status, c.detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("selftest: %d feature(s) do not work in this environment", failed)
+	}
+	return nil
+}
+
+// selftestDocumentChecks runs the named blocks of the example document and returns their results.
+func selftestDocumentChecks(ctx context.Context, tmpDir string) ([]*selfCheck, error) {
+	blocks, err := parser.Parse(selftestDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the example document: %w", err)
+	}
+	var checks []*selfCheck
+	byIndex := map[int]*selfCheck{}
+	for i, b := range blocks {
+		c := &selfCheck{feature: b.Name(), status: "FAILED", detail: "not run"}
+		checks = append(checks, c)
+		byIndex[i] = c
+	}
+
+	r := runner.New("", nil)
+	r.File = "selftest.md"
+	r.TmpDir = tmpDir
+	r.KeepGoing = true
+	r.Stdout = io.Discard
+	r.Stderr = io.Discard
+	r.OnResult = func(result *runner.Result) {
+		byIndex[result.Index].set(result.Err, blocks[result.Index].Heading)
+	}
+	_ = r.RunAll(ctx, blocks) //nostyle:handlerrors
+
+	// The shell runs the commands with arguments
+	if len(checks) > 0 {
+		if sh, _, err := runner.BuildCommand("echo ok"); err == nil && checks[0].status == "ok" {
+			checks[0].detail = sh
+		}
+	}
+	return checks, nil
+}
+
+// selftestTermination checks that a running block is terminated when the run is canceled (e.g., by --timeout).
+func selftestTermination(ctx context.Context) *selfCheck {
+	c := &selfCheck{feature: "termination"}
+	ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	r := runner.New("", nil)
+	r.Stdout = io.Discard
+	r.Stderr = io.Discard
+	started := time.Now()
+	err := r.Run(ctx, parser.CodeBlock{Language: "sh", Command: "sleep 3"}, 0)
+	elapsed := time.Since(started)
+	switch {
+	case err == nil:
+		c.set(errors.New("the block was not canceled"), "")
+	case ctx.Err() == nil:
+		// The block failed before the cancellation
+		c.set(err, "")
+	case elapsed >= selftestTimeout:
+		c.set(fmt.Errorf("the canceled block ran for %s (child processes of the shell keep running)", elapsed.Round(100*time.Millisecond)), "")
+	default:
+		c.set(nil, fmt.Sprintf("canceled blocks end in %s", elapsed.Round(10*time.Millisecond)))
+	}
+	return c
+}
+
+// selftestWatch checks that the changes of files are notified for --watch.
+func selftestWatch(dir string) *selfCheck {
+	c := &selfCheck{feature: "watch"}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.set(err, "")
+		return c
+	}
+	defer func() { _ = watcher.Close() }() //nostyle:handlerrors
+	if err := watcher.Add(dir); err != nil {
+		c.set(err, "")
+		return c
+	}
+	path := filepath.Join(dir, "watched.md")
+	if err := os.WriteFile(path, []byte("# watched\n"), 0o600); err != nil {
+		c.set(err, "")
+		return c
+	}
+	timeout := time.After(selftestTimeout)
+	for {
+		select {
+		case e := <-watcher.Events:
+			if filepath.Base(e.Name) == "watched.md" {
+				c.set(nil, "changes of files are notified")
+				return c
+			}
+		case err := <-watcher.Errors:
+			c.set(err, "")
+			return c
+		case <-timeout:
+			c.set(errors.New("no notification of a change of a file"), "")
+			return c
+		}
+	}
+}
+
+// selftestTerminal reports whether runblock runs on a terminal. It is for information only.
+func selftestTerminal() *selfCheck {
+	yesNo := func(f *os.File) string {
+		if isTerminal(f) {
+			return "yes"
+		}
+		return "no"
+	}
+	return &selfCheck{
+		feature: "terminal",
+		status:  "-",
+		detail: fmt.Sprintf("stdin: %s, stderr: %s (prompts and --progress need a terminal; blocks run with pipes, not a pseudo-terminal)",
+			yesNo(os.Stdin), yesNo(os.Stderr)),
+	}
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestSelftestDocument(t *testing.T) {
+	blocks, err := parser.Parse(selftestDocument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) == 0 {
+		t.Fatal("the example document has no blocks")
+	}
+	for i, b := range blocks {
+		if b.Name() == "" {
+			t.Errorf("block %d has no name", i+1)
+		}
+	}
+}
+
+func TestSelftestDocumentChecks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	checks, err := selftestDocumentChecks(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range checks {
+		if c.status != "ok" {
+			t.Errorf("%s: got %s (%s), want ok", c.feature, c.status, c.detail)
+		}
+	}
+}
+
+func TestSelftestWatch(t *testing.T) {
+	if c := selftestWatch(t.TempDir()); c.status != "ok" {
+		t.Errorf("got %s (%s), want ok", c.status, c.detail)
+	}
+}
+
+func TestSelftestPrint(t *testing.T) {
+	var buf bytes.Buffer
+	selftestCmd.SetOut(&buf)
+	t.Cleanup(func() {
+		selftestCmd.SetOut(nil)
+		printSelftest = false
+	})
+	printSelftest = true
+	if err := selftestCmd.RunE(selftestCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "# runblock self-test\n") {
+		t.Errorf("got %q", buf.String())
+	}
+}
@@ -0,0 +1,498 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listenAddr string
+	serveToken string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve MARKDOWN_FILE",
+	Short: "Serve an HTTP API to list and run code blocks",
+	Long: `serve starts an HTTP server exposing the code blocks of the file:
+
+  GET  /                       - Web UI to browse and run the code blocks
+  GET  /api/blocks             - List code blocks as JSON
+  GET  /api/document           - Document rendered as HTML
+  GET  /api/history            - Recent runs as JSON (newest first)
+  POST /api/run                - Run all code blocks
+  POST /api/blocks/{index}/run - Run the code block (0-based index)
+  GET  /metrics                - Prometheus metrics of the blocks run
+
+Runs stream their progress as Server-Sent Events (start, stdout, stderr,
+result and done). The file is re-read on every request, and only one run
+is executed at a time.
+
+When a token is set (--token or RUNBLOCK_TOKEN), requests must send it
+as 'Authorization: Bearer TOKEN' to the API. The web UI asks for it.
+/metrics does not require the token. A token is required to listen on
+an address other than a loopback address.
+
+Requests whose Host is not the listen address, or whose Origin is not
+the server itself, are rejected so that web pages cannot run the code
+blocks through the browser (cross-site requests and DNS rebinding).`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		token := serveToken
+		if token == "" {
+			token = os.Getenv("RUNBLOCK_TOKEN")
+		}
+		if token == "" {
+			if !isLoopbackAddr(listenAddr) {
+				return fmt.Errorf("a token is required to listen on %s, which is not a loopback address: set --token or RUNBLOCK_TOKEN", listenAddr)
+			}
+			fmt.Fprintln(os.Stderr, "Warning: no token is set; anyone who can reach the server can execute the code blocks")
+		}
+		ln, err := net.Listen("tcp", listenAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Serving %s on http://%s\n", args[0], ln.Addr())
+		s := newServer(args[0], token)
+		s.hosts = allowedHosts(listenAddr, ln.Addr())
+		return serve(ctx, ln, s)
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&listenAddr, "listen", "localhost:8080", "address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "token required to access the API (default: $RUNBLOCK_TOKEN)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// serve serves h on ln until ctx is done.
+func serve(ctx context.Context, ln net.Listener, h http.Handler) error {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// isLoopbackAddr reports whether the host of the listen address addr is localhost or a loopback IP address.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// allowedHosts returns the Host headers accepted by the server listening on listen (bound to addr).
+// It returns nil, accepting any Host, if listen has no host or an unspecified IP address (e.g., ":8080"),
+// since the server can then be reached by any name of the machine.
+func allowedHosts(listen string, addr net.Addr) []string {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return nil
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		return nil
+	}
+	hosts := []string{listen, addr.String()}
+	if host == "localhost" {
+		// The port of the listen address may be 0
+		_, port, _ := net.SplitHostPort(addr.String()) //nostyle:handlerrors
+		if h := net.JoinHostPort("localhost", port); h != listen {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// maxHistory is the number of runs kept in the history.
+const maxHistory = 50
+
+// server serves the code blocks of a Markdown file over HTTP.
+type server struct {
+	file    string
+	token   string
+	hosts   []string   // Host headers accepted (any if empty)
+	running sync.Mutex // Held while a run is in progress
+	mux     *http.ServeMux
+
+	mu      sync.Mutex
+	history []serveRun // Newest first
+	nextID  int
+
+	metrics *blockMetrics
+}
+
+// newServer returns a server for the file.
+func newServer(file, token string) *server {
+	s := &server{file: file, token: token, mux: http.NewServeMux(), metrics: newBlockMetrics()}
+	s.mux.HandleFunc("GET /{$}", s.handleUI)
+	s.mux.HandleFunc("GET /api/blocks", s.handleBlocks)
+	s.mux.HandleFunc("GET /api/document", s.handleDocument)
+	s.mux.HandleFunc("GET /api/history", s.handleHistory)
+	s.mux.HandleFunc("POST /api/run", s.handleRun)
+	s.mux.HandleFunc("POST /api/blocks/{index}/run", s.handleRun)
+	s.mux.Handle("GET /metrics", s.metrics)
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(s.hosts) > 0 && !slices.Contains(s.hosts, r.Host) {
+		http.Error(w, fmt.Sprintf("host %q is not allowed", r.Host), http.StatusForbidden)
+		return
+	}
+	// Browsers send Origin with cross-origin requests and with POST requests
+	if origin := r.Header.Get("Origin"); origin != "" && origin != "http://"+r.Host {
+		http.Error(w, fmt.Sprintf("origin %q is not allowed", origin), http.StatusForbidden)
+		return
+	}
+	if s.token != "" && strings.HasPrefix(r.URL.Path, "/api/") {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// serveBlock is a code block in the block list.
+type serveBlock struct {
+	Index   int      `json:"index"`
+	Line    int      `json:"line"`
+	Name    string   `json:"name"`
+	Lang    string   `json:"lang"`
+	Tags    []string `json:"tags"`
+	Command string   `json:"command"`
+	Content string   `json:"content"`
+}
+
+func (s *server) handleBlocks(w http.ResponseWriter, _ *http.Request) {
+	blocks, err := readBlocks([]string{s.file})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	list := []serveBlock{}
+	for i, b := range blocks {
+		tags := b.Tags()
+		if tags == nil {
+			tags = []string{}
+		}
+		list = append(list, serveBlock{
+			Index:   i,
+			Line:    b.Line,
+			Name:    b.Name(),
+			Lang:    b.Language,
+			Tags:    tags,
+			Command: b.Command,
+			Content: b.Content,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list) //nostyle:handlerrors
+}
+
+func (s *server) handleRun(w http.ResponseWriter, r *http.Request) {
+	index := -1
+	if v := r.PathValue("index"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			http.Error(w, fmt.Sprintf("invalid block index %q", v), http.StatusBadRequest)
+			return
+		}
+		index = i
+	}
+	if !s.running.TryLock() {
+		http.Error(w, "a run is already in progress", http.StatusConflict)
+		return
+	}
+	defer s.running.Unlock()
+
+	source, err := readSource([]string{s.file})
+	if err == nil {
+		err = verifySource(source, []string{s.file})
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	blocks, err := parseBlocks(source)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if index >= len(blocks) {
+		http.Error(w, fmt.Sprintf("block %d not found", index), http.StatusNotFound)
+		return
+	}
+	rn, err := newRunner()
+	if err == nil {
+		err = applyDocConfig(rn, source)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rn.File = s.file
+	tmpDir, err := os.MkdirTemp("", "runblock-")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create temporary directory: %v", err), http.StatusInternalServerError)
+		return
+	}
+	rn.TmpDir = tmpDir
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}()
+	if auditLogPath != "" {
+		audit, err := openAuditLog(auditLogPath, s.file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() {
+			if err := audit.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}()
+		addResultHook(rn, audit.record)
+	}
+	if index >= 0 {
+		rn.Select = func(_ parser.CodeBlock, i int) bool { return i == index }
+	}
+
+	es, err := newEventStream(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rp := newReport(s.file)
+	es.attach(rn)
+	addResultHook(rn, rp.record)
+	addResultHook(rn, s.metrics.record)
+	runErr := rn.RunAll(r.Context(), blocks)
+	rp.Duration = time.Since(rp.StartedAt)
+	done := serveDone{
+		ID:         s.addHistory(index, rp, runErr),
+		Passed:     rp.Count(statusPassed),
+		Failed:     rp.Count(statusFailed),
+		Skipped:    rp.Count(statusSkipped),
+		DurationMS: rp.Duration.Milliseconds(),
+	}
+	if runErr != nil {
+		done.Error = runErr.Error()
+	}
+	_ = es.send("done", done) //nostyle:handlerrors
+}
+
+// serveRun is a run in the history.
+type serveRun struct {
+	ID         int       `json:"id"`
+	Block      *int      `json:"block,omitempty"` // Index of the block, or nil for the whole file
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Passed     int       `json:"passed"`
+	Failed     int       `json:"failed"`
+	Skipped    int       `json:"skipped"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// addHistory adds a run of the block (-1 for the whole file) to the history and returns its ID.
+func (s *server) addHistory(index int, rp *report, err error) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	run := serveRun{
+		ID:         s.nextID,
+		StartedAt:  rp.StartedAt,
+		DurationMS: rp.Duration.Milliseconds(),
+		Passed:     rp.Count(statusPassed),
+		Failed:     rp.Count(statusFailed),
+		Skipped:    rp.Count(statusSkipped),
+	}
+	if index >= 0 {
+		run.Block = &index
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	s.history = append([]serveRun{run}, s.history[:min(len(s.history), maxHistory-1)]...)
+	return run.ID
+}
+
+func (s *server) handleHistory(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	history := append([]serveRun{}, s.history...)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(history) //nostyle:handlerrors
+}
+
+// Event payloads of a run.
+type (
+	serveStart struct {
+		Index   int    `json:"index"`
+		Name    string `json:"name"`
+		Lang    string `json:"lang"`
+		Command string `json:"command"`
+	}
+	serveOutput struct {
+		Index int    `json:"index"`
+		Data  string `json:"data"`
+	}
+	serveResult struct {
+		Index      int    `json:"index"`
+		Status     string `json:"status"`
+		ExitCode   int    `json:"exit_code"`
+		DurationMS int64  `json:"duration_ms"`
+		SkipReason string `json:"skip_reason,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}
+	serveDone struct {
+		ID         int    `json:"id"`
+		Passed     int    `json:"passed"`
+		Failed     int    `json:"failed"`
+		Skipped    int    `json:"skipped"`
+		DurationMS int64  `json:"duration_ms"`
+		Error      string `json:"error,omitempty"`
+	}
+)
+
+// eventStream writes Server-Sent Events to a response.
+type eventStream struct {
+	mu    sync.Mutex
+	w     http.ResponseWriter
+	f     http.Flusher
+	index int // Index of the running block
+}
+
+// newEventStream starts an event stream on w.
+func newEventStream(w http.ResponseWriter) (*eventStream, error) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming is not supported")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &eventStream{w: w, f: f}, nil
+}
+
+// attach streams the output and results of the runner.
+func (es *eventStream) attach(r *runner.Runner) {
+	r.Stdout = es.writer("stdout")
+	r.Stderr = es.writer("stderr")
+	r.OnStart = func(result *runner.Result) {
+		es.mu.Lock()
+		es.index = result.Index
+		es.mu.Unlock()
+		_ = es.send("start", serveStart{ //nostyle:handlerrors
+			Index:   result.Index,
+			Name:    result.Block.Name(),
+			Lang:    result.Block.Language,
+			Command: result.Command,
+		})
+	}
+	addResultHook(r, func(result *runner.Result) {
+		ev := serveResult{
+			Index:      result.Index,
+			Status:     statusPassed,
+			ExitCode:   result.ExitCode,
+			DurationMS: result.Duration.Milliseconds(),
+			SkipReason: result.SkipReason,
+		}
+		switch {
+		case result.Err != nil:
+			ev.Status = statusFailed
+			ev.Error = result.Err.Error()
+		case result.Skipped:
+			ev.Status = statusSkipped
+		}
+		_ = es.send("result", ev) //nostyle:handlerrors
+	})
+}
+
+// writer returns a writer sending its writes as events.
+func (es *eventStream) writer(event string) writerFunc {
+	return func(b []byte) (int, error) {
+		es.mu.Lock()
+		index := es.index
+		es.mu.Unlock()
+		if err := es.send(event, serveOutput{Index: index, Data: string(b)}); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+}
+
+// send writes an event with v as JSON data.
+func (es *eventStream) send(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if _, err := fmt.Fprintf(es.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	es.f.Flush()
+	return nil
+}
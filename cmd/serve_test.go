@@ -0,0 +1,370 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const serveTestDoc = "# Runbook\n\n```sh {name=hello} sh\necho hello\n```\n\n```sh sh\nexit 3\n```\n"
+
+func newTestServer(t *testing.T, doc string) *httptest.Server {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "runbook.md")
+	if err := os.WriteFile(file, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(file, "secret")
+	ts := httptest.NewUnstartedServer(s)
+	s.hosts = []string{ts.Listener.Addr().String()}
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func doRequest(t *testing.T, method, url, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() }) //nostyle:handlerrors
+	return resp
+}
+
+func TestServer_Auth(t *testing.T) {
+	ts := newTestServer(t, serveTestDoc)
+	for _, token := range []string{"", "wrong"} {
+		resp := doRequest(t, http.MethodGet, ts.URL+"/api/blocks", token)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: got status %d, want %d", token, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestServer_HostAndOrigin(t *testing.T) {
+	ts := newTestServer(t, serveTestDoc)
+	tests := []struct {
+		name   string
+		host   string
+		origin string
+		want   int
+	}{
+		{"same origin", "", ts.URL, http.StatusOK},
+		{"no origin", "", "", http.StatusOK},
+		{"cross-site request", "", "http://evil.example", http.StatusForbidden},
+		{"DNS rebinding", "evil.example:" + ts.URL[strings.LastIndex(ts.URL, ":")+1:], "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, ts.URL+"/api/blocks", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer secret")
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close() //nostyle:handlerrors
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"localhost:8080", true},
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"192.168.1.10:8080", false},
+		{"example.com:8080", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestServeCmd_TokenRequired(t *testing.T) {
+	t.Setenv("RUNBLOCK_TOKEN", "")
+	listenAddr, serveToken = "0.0.0.0:0", ""
+	t.Cleanup(func() { listenAddr = "localhost:8080" })
+	serveCmd.SetContext(t.Context())
+	err := serveCmd.RunE(serveCmd, []string{"runbook.md"})
+	if err == nil || !strings.Contains(err.Error(), "a token is required") {
+		t.Errorf("RunE() error = %v, want a token to be required", err)
+	}
+}
+
+func TestAllowedHosts(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	if got, want := allowedHosts("localhost:8080", addr), []string{"localhost:8080", "127.0.0.1:8080"}; !slices.Equal(got, want) {
+		t.Errorf("allowedHosts() = %q, want %q", got, want)
+	}
+	for _, listen := range []string{":8080", "0.0.0.0:8080"} {
+		if got := allowedHosts(listen, addr); got != nil {
+			t.Errorf("allowedHosts(%q) = %q, want nil", listen, got)
+		}
+	}
+}
+
+func TestServer_Blocks(t *testing.T) {
+	ts := newTestServer(t, serveTestDoc)
+	resp := doRequest(t, http.MethodGet, ts.URL+"/api/blocks", "secret")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d", resp.StatusCode)
+	}
+	var got []serveBlock
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(got))
+	}
+	if got[0].Name != "hello" || got[0].Line != 3 || got[0].Command != "sh" || got[0].Content != "echo hello\n" {
+		t.Errorf("unexpected block: %+v", got[0])
+	}
+}
+
+func TestServer_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	ts := newTestServer(t, serveTestDoc)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		want       []string
+	}{
+		{
+			name:       "block",
+			path:       "/api/blocks/0/run",
+			wantStatus: http.StatusOK,
+			want: []string{
+				"event: start\ndata: {\"index\":0,\"name\":\"hello\",\"lang\":\"sh\",\"command\":\"sh\"}\n\n",
+				"event: stdout\ndata: {\"index\":0,\"data\":\"hello\\n\"}\n\n",
+				"event: done\ndata: {\"id\":1,\"passed\":1,\"failed\":0,\"skipped\":0,",
+			},
+		},
+		{
+			name:       "all",
+			path:       "/api/run",
+			wantStatus: http.StatusOK,
+			want: []string{
+				"event: result\ndata: {\"index\":1,\"status\":\"failed\",\"exit_code\":3,",
+				"event: done\ndata: {\"id\":2,\"passed\":1,\"failed\":1,\"skipped\":0,",
+			},
+		},
+		{
+			name:       "not found",
+			path:       "/api/blocks/2/run",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid index",
+			path:       "/api/blocks/x/run",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, ts.URL+tt.path, "secret")
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("stream does not contain %q:\n%s", want, b)
+				}
+			}
+		})
+	}
+
+	// Metrics are served without the token
+	resp := doRequest(t, http.MethodGet, ts.URL+"/metrics", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`runblock_blocks_run_total{lang="sh",status="failed"} 1`,
+		`runblock_blocks_run_total{lang="sh",status="passed"} 2`,
+		`runblock_block_duration_seconds_count{lang="sh",status="passed"} 2`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestServer_RunTmpDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	ts := newTestServer(t, "```sh sh -c 'echo hello > {{tmpdir}}/x && cat {{tmpdir}}/x && echo dir={{tmpdir}}'\n```\n")
+	resp := doRequest(t, http.MethodPost, ts.URL+"/api/run", "secret")
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"data":"hello\n"`, `"data":"dir=` + os.TempDir(), `"passed":1,`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("stream does not contain %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestServer_RunApproval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	allowHashes = filepath.Join(dir, "allowed.txt")
+	auditLogPath = filepath.Join(dir, "audit.jsonl")
+	t.Cleanup(func() { allowHashes, auditLogPath = "", "" })
+	ts := newTestServer(t, serveTestDoc)
+
+	other := sha256.Sum256([]byte("other document"))
+	if err := os.WriteFile(allowHashes, []byte(hex.EncodeToString(other[:])+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resp := doRequest(t, http.MethodPost, ts.URL+"/api/run", "secret")
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(string(b), "document is not approved") {
+		t.Errorf("unapproved document: got status %d: %s", resp.StatusCode, b)
+	}
+	if _, err := os.Stat(auditLogPath); !os.IsNotExist(err) {
+		t.Errorf("audit log should not be written for an unapproved document: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(serveTestDoc))
+	if err := os.WriteFile(allowHashes, []byte(hex.EncodeToString(sum[:])+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resp = doRequest(t, http.MethodPost, ts.URL+"/api/run", "secret")
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("approved document: got status %d", resp.StatusCode)
+	}
+	audit, err := os.ReadFile(auditLogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(audit), "\n"); got != 2 {
+		t.Errorf("audit log has %d records, want 2:\n%s", got, audit)
+	}
+}
+
+func TestServer_UI(t *testing.T) {
+	ts := newTestServer(t, serveTestDoc)
+	resp := doRequest(t, http.MethodGet, ts.URL+"/", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	resp = doRequest(t, http.MethodGet, ts.URL+"/api/document", "secret")
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<h1>Runbook</h1>\n" +
+		"<div class=\"block\" data-index=\"0\"><div class=\"info\">sh {name=hello} sh</div><pre><code>echo hello\n</code></pre></div>\n" +
+		"<div class=\"block\" data-index=\"1\"><div class=\"info\">sh sh</div><pre><code>exit 3\n</code></pre></div>\n"
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
+
+func TestServer_History(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	ts := newTestServer(t, serveTestDoc)
+	for _, path := range []string{"/api/blocks/0/run", "/api/run"} {
+		resp := doRequest(t, http.MethodPost, ts.URL+path, "secret")
+		_, _ = io.Copy(io.Discard, resp.Body) //nostyle:handlerrors
+	}
+
+	resp := doRequest(t, http.MethodGet, ts.URL+"/api/history", "secret")
+	var got []serveRun
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d runs, want 2", len(got))
+	}
+	if got[0].ID != 2 || got[0].Block != nil || got[0].Failed != 1 {
+		t.Errorf("unexpected run: %+v", got[0])
+	}
+	if got[1].ID != 1 || got[1].Block == nil || *got[1].Block != 0 || got[1].Passed != 1 {
+		t.Errorf("unexpected run: %+v", got[1])
+	}
+}
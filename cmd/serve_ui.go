@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+//go:embed ui/index.html
+var uiHTML []byte
+
+func (s *server) handleUI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(uiHTML) //nostyle:handlerrors
+}
+
+func (s *server) handleDocument(w http.ResponseWriter, _ *http.Request) {
+	source, err := readSource([]string{s.file})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := renderDocument(&buf, source); err != nil {
+		http.Error(w, fmt.Sprintf("failed to render markdown: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = buf.WriteTo(w) //nostyle:handlerrors
+}
+
+// renderDocument renders Markdown source as HTML.
+// Raw HTML in the source is omitted, and fenced code blocks are rendered as
+// <div class="block" data-index="N"> with the index used by the API.
+func renderDocument(w io.Writer, source []byte) error {
+	md := goldmark.New(goldmark.WithRendererOptions(
+		renderer.WithNodeRenderers(util.Prioritized(&blockRenderer{}, 100)),
+	))
+	return md.Convert(source, w)
+}
+
+// blockRenderer renders fenced code blocks with their indices.
+type blockRenderer struct {
+	n int
+}
+
+func (br *blockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, br.renderFencedCodeBlock)
+}
+
+func (br *blockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	fcb, ok := n.(*ast.FencedCodeBlock)
+	if !ok {
+		return ast.WalkContinue, nil
+	}
+	var info []byte
+	if fcb.Info != nil {
+		info = fcb.Info.Segment.Value(source)
+	}
+	fmt.Fprintf(w, `<div class="block" data-index="%d"><div class="info">`, br.n)
+	template.HTMLEscape(w, info)
+	_, _ = w.WriteString(`</div><pre><code>`) //nostyle:handlerrors
+	lines := fcb.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		template.HTMLEscape(w, line.Value(source))
+	}
+	_, _ = w.WriteString("</code></pre></div>\n") //nostyle:handlerrors
+	br.n++
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	useState bool
+	force    bool
+)
+
+// stateDir is the directory execution states are stored in, relative to the working directory.
+var stateDir = filepath.Join(".runblock", "state")
+
+// cleanCmd represents the clean command
+var cleanCmd = &cobra.Command{
+	Use:   "clean [MARKDOWN_FILE]",
+	Short: "Remove the execution state recorded by --state",
+	Long: `clean removes the execution state of the file recorded by --state,
+so that all of its blocks run again. Without a file, the states of all files are removed.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return os.RemoveAll(stateDir)
+		}
+		path, err := statePath(args[0])
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.Flags().BoolVar(&useState, "state", false,
+		"record blocks that succeeded under .runblock/state and skip them while their command and content are unchanged")
+	rootCmd.Flags().BoolVar(&force, "force", false,
+		"with --state or --cache, run blocks even if they are up to date")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// runState is the execution state of a document.
+type runState struct {
+	mu     sync.Mutex
+	path   string
+	Source string              `json:"source"`
+	Blocks map[int]*blockState `json:"blocks"` // Keyed by the 0-based index of the block
+	errs   []error
+}
+
+// blockState is the execution state of a code block.
+type blockState struct {
+	Hash        string    `json:"hash"` // Hash of the command and the environment of the last success
+	SucceededAt time.Time `json:"succeeded_at"`
+}
+
+// statePath returns the path of the state file of the document file.
+func statePath(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(stateDir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadState loads the execution state of the document file (an empty state if it has not been recorded).
+func loadState(file string) (*runState, error) {
+	path, err := statePath(file)
+	if err != nil {
+		return nil, err
+	}
+	abs, _ := filepath.Abs(file) //nostyle:handlerrors
+	s := &runState{path: path, Source: abs, Blocks: map[int]*blockState{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	if s.Blocks == nil {
+		s.Blocks = map[int]*blockState{}
+	}
+	return s, nil
+}
+
+// attach skips the blocks that are up to date (unless force is set), noting them on w, and records the results of r.
+func (s *runState) attach(w io.Writer, r *runner.Runner, force bool) {
+	if !force {
+		r.UpToDate = s.upToDate
+	}
+	addResultHook(r, func(result *runner.Result) {
+		// Only blocks skipped as up to date have been hashed
+		if result.Skipped && result.Hash != "" {
+			fmt.Fprintf(w, "Block %d is up to date\n", result.Index+1)
+		}
+	})
+	addResultHook(r, s.record)
+}
+
+// upToDate reports whether the block has succeeded with the same command and environment.
+func (s *runState) upToDate(result *runner.Result) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.Blocks[result.Index]
+	return ok && b.Hash == result.Hash
+}
+
+// record records the result of a code block and saves the state.
+func (s *runState) record(result *runner.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case result.Skipped:
+		return
+	case result.Err != nil:
+		delete(s.Blocks, result.Index)
+	default:
+		s.Blocks[result.Index] = &blockState{Hash: result.Hash, SucceededAt: result.StartedAt}
+	}
+	if err := s.save(); err != nil {
+		s.errs = append(s.errs, err)
+	}
+}
+
+// save writes the state to its file atomically.
+func (s *runState) save() error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Err returns the errors occurred while saving the state.
+func (s *runState) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.errs...)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestRunState(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Chdir(t.TempDir())
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo one"},
+		{Language: "sh", Command: "echo two"},
+	}
+	run := func(blocks []parser.CodeBlock, force bool) (string, string) {
+		t.Helper()
+		state, err := loadState("runbook.md")
+		if err != nil {
+			t.Fatalf("loadState() error = %v", err)
+		}
+		var stdout, notes bytes.Buffer
+		r := runner.New("", nil)
+		r.Stdout = &stdout
+		state.attach(&notes, r, force)
+		_ = r.RunAll(t.Context(), blocks) //nostyle:handlerrors
+		if err := state.Err(); err != nil {
+			t.Fatalf("state error = %v", err)
+		}
+		return stdout.String(), notes.String()
+	}
+
+	if got, _ := run(blocks, false); got != "one\ntwo\n" {
+		t.Errorf("first run stdout = %q", got)
+	}
+	got, notes := run(blocks, false)
+	if got != "" {
+		t.Errorf("up to date blocks should be skipped, stdout = %q", got)
+	}
+	if want := "Block 1 is up to date\nBlock 2 is up to date\n"; notes != want {
+		t.Errorf("notes = %q, want %q", notes, want)
+	}
+
+	// Changed blocks run again
+	blocks[1].Command = "echo three"
+	if got, _ := run(blocks, false); got != "three\n" {
+		t.Errorf("changed block stdout = %q, want %q", got, "three\n")
+	}
+
+	// Failed blocks are not recorded
+	blocks[1].Command = "exit 1"
+	run(blocks, false)
+	blocks[1].Command = "echo three"
+	if got, _ := run(blocks, false); got != "three\n" {
+		t.Errorf("stdout after failure = %q, want %q", got, "three\n")
+	}
+
+	if got, _ := run(blocks, true); got != "one\nthree\n" {
+		t.Errorf("forced stdout = %q, want %q", got, "one\nthree\n")
+	}
+
+	path, err := statePath("runbook.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("state file is not written: %v", err)
+	}
+}
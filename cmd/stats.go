@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+	"github.com/spf13/cobra"
+)
+
+var listLangs bool
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats FILE_OR_DIR...",
+	Short: "Summarize the code blocks of Markdown files",
+	Long: `stats summarizes the code blocks of the Markdown files (and of the Markdown files
+under directories): the number of blocks per language and per heading, how many of them
+have a command with the current flags and their average size.
+
+Paths matching the patterns in .runblockignore of a directory are skipped.
+Use it to plan which languages need command mappings (-c lang:command).
+With --list-langs, only the languages are printed, one per line.
+Nothing is executed.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeMarkdownFiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		st, err := collectStats(r, args)
+		if err != nil {
+			return err
+		}
+		if listLangs {
+			for _, g := range st.langs {
+				if g.name != "" {
+					fmt.Fprintln(cmd.OutOrStdout(), g.name)
+				}
+			}
+			return nil
+		}
+		return st.write(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().BoolVar(&listLangs, "list-langs", false, "print only the languages of the code blocks, one per line")
+}
+
+// blockStats is the statistics of a group of code blocks.
+type blockStats struct {
+	name        string
+	blocks      int
+	withCommand int
+	lines       int
+	bytes       int
+}
+
+func (s *blockStats) add(block parser.CodeBlock, command bool) {
+	s.blocks++
+	if command {
+		s.withCommand++
+	}
+	s.lines += strings.Count(block.Content, "\n")
+	s.bytes += len(block.Content)
+}
+
+func (s *blockStats) avgLines() string {
+	return fmt.Sprintf("%.1f", float64(s.lines)/float64(max(s.blocks, 1)))
+}
+
+func (s *blockStats) avgBytes() string {
+	return fmt.Sprintf("%.0f", float64(s.bytes)/float64(max(s.blocks, 1)))
+}
+
+// stats is the statistics of the code blocks of Markdown files.
+type stats struct {
+	files    int
+	total    blockStats
+	langs    []*blockStats // Sorted by the number of blocks
+	headings []*blockStats // In the order of appearance
+}
+
+// collectStats collects the statistics of the code blocks of the files (and of the Markdown files under directories).
+// A block has a command if the runner resolves one for it.
+func collectStats(r *runner.Runner, args []string) (*stats, error) {
+	var files []string
+	for _, arg := range args {
+		fi, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		found, err := markdownFiles(arg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+
+	st := &stats{files: len(files)}
+	langs := map[string]*blockStats{}
+	headings := map[string]*blockStats{}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		blocks, err := parseBlocks(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := applyDocConfig(r, source); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		r.File = file
+		for i, block := range blocks {
+			res, err := r.Resolve(block, i)
+			command := err != nil || res.Source != ""
+			st.total.add(block, command)
+
+			g, ok := langs[block.Language]
+			if !ok {
+				g = &blockStats{name: block.Language}
+				langs[block.Language] = g
+				st.langs = append(st.langs, g)
+			}
+			g.add(block, command)
+
+			heading := cmp.Or(block.Heading, "(no heading)")
+			if len(files) > 1 {
+				heading = file + ": " + heading
+			}
+			h, ok := headings[heading]
+			if !ok {
+				h = &blockStats{name: heading}
+				headings[heading] = h
+				st.headings = append(st.headings, h)
+			}
+			h.add(block, command)
+		}
+	}
+	slices.SortStableFunc(st.langs, func(a, b *blockStats) int {
+		return cmp.Or(cmp.Compare(b.blocks, a.blocks), cmp.Compare(a.name, b.name))
+	})
+	return st, nil
+}
+
+// write writes the statistics as tables to w.
+func (st *stats) write(w io.Writer) error {
+	fmt.Fprintf(w, "Files:  %d\n", st.files)
+	fmt.Fprintf(w, "Blocks: %d (%d with a command, %d without)\n", st.total.blocks, st.total.withCommand, st.total.blocks-st.total.withCommand)
+	fmt.Fprintf(w, "Size:   %s lines, %s bytes per block on average\n", st.total.avgLines(), st.total.avgBytes())
+	if st.total.blocks == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nLANGUAGE\tBLOCKS\tWITH COMMAND\tWITHOUT\tAVG LINES\tAVG BYTES")
+	for _, g := range st.langs {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n", cmp.Or(g.name, "(none)"), g.blocks, g.withCommand, g.blocks-g.withCommand, g.avgLines(), g.avgBytes())
+	}
+	fmt.Fprintln(tw, "\nHEADING\tBLOCKS\tWITH COMMAND\tWITHOUT\tAVG LINES\tAVG BYTES")
+	for _, h := range st.headings {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n", h.name, h.blocks, h.withCommand, h.blocks-h.withCommand, h.avgLines(), h.avgBytes())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	doc := "# Install\n\n```sh sh\necho a\n```\n\n```console\n$ make\n```\n\n## Build\n\n```go\npackage main\n\nfunc main() {}\n```\n\n```sh\nmake build\n```\n"
+	if err := os.WriteFile(filepath.Join(dir, "doc.md"), []byte(doc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r := runner.New("", map[string]string{"go": "go run"})
+	st, err := collectStats(r, []string{filepath.Join(dir, "doc.md")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.files != 1 || st.total.blocks != 4 || st.total.withCommand != 2 {
+		t.Errorf("got %d files, %d blocks, %d with a command, want 1, 4, 2", st.files, st.total.blocks, st.total.withCommand)
+	}
+	var got []string
+	for _, g := range st.langs {
+		got = append(got, g.name)
+	}
+	if strings.Join(got, ",") != "sh,console,go" {
+		t.Errorf("got languages %v, want [sh console go]", got)
+	}
+
+	var buf bytes.Buffer
+	if err := st.write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Blocks: 4 (2 with a command, 2 without)",
+		"sh        2       1             1        1.0        9",
+		"Install  2       1             1        1.0        7",
+		"Build    2       1             1        2.0        20",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	// Directories are searched for Markdown files
+	st, err = collectStats(r, []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.files != 1 || st.total.blocks != 4 {
+		t.Errorf("got %d files and %d blocks, want 1 and 4", st.files, st.total.blocks)
+	}
+}
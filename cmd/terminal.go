@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+)
+
+// tty is the terminal controller for the prompts and the confirmations of runblock.
+var tty = newTerminal(os.Stdin, os.Stderr)
+
+// terminal serializes the interactive prompts so that they never interleave,
+// e.g., when blocks run in parallel with --parallel.
+// The prompts share a reader so that answers typed ahead are not lost between them.
+type terminal struct {
+	mu  sync.Mutex
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newTerminal(in io.Reader, out io.Writer) *terminal {
+	return &terminal{in: bufio.NewReader(in), out: out}
+}
+
+// interact calls f holding the terminal. f asks on out and reads the answers from in.
+func (t *terminal) interact(f func(in *bufio.Reader, out io.Writer) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return f(t.in, t.out)
+}
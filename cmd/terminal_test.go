@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTerminalSerializesPrompts(t *testing.T) {
+	var out bytes.Buffer
+	term := newTerminal(strings.NewReader(""), &out)
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := term.interact(func(_ *bufio.Reader, out io.Writer) error {
+				fmt.Fprintf(out, "<%d", i)
+				time.Sleep(10 * time.Millisecond)
+				fmt.Fprintf(out, "%d>", i)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	got := out.String()
+	for i := range 4 {
+		if !strings.Contains(got, fmt.Sprintf("<%d%d>", i, i)) {
+			t.Errorf("prompts interleave: %q", got)
+		}
+	}
+}
+
+func TestTerminalSharesReader(t *testing.T) {
+	var out bytes.Buffer
+	term := newTerminal(strings.NewReader("y\nn\n"), &out)
+	ask := func() error {
+		return term.interact(func(in *bufio.Reader, out io.Writer) error {
+			return confirm(in, out, "Run?")
+		})
+	}
+	if err := ask(); err != nil {
+		t.Errorf("first answer: got %v, want yes", err)
+	}
+	if err := ask(); !errors.Is(err, errNotConfirmed) {
+		t.Errorf("second answer: got %v, want %v", err, errNotConfirmed)
+	}
+}
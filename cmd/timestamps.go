@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Timestamp formats selected by --timestamps.
+const (
+	timestampsRFC3339 = "rfc3339" // Wall clock time
+	timestampsElapsed = "elapsed" // Time elapsed since the start of the run
+)
+
+var timestamps string
+
+func init() {
+	rootCmd.Flags().StringVar(&timestamps, "timestamps", "",
+		"prefix every output line with a timestamp (rfc3339|elapsed)")
+	rootCmd.Flags().Lookup("timestamps").NoOptDefVal = timestampsRFC3339
+}
+
+// newTimestamper returns a function formatting timestamps in the format.
+func newTimestamper(format string, start time.Time, now func() time.Time) (func() string, error) {
+	switch format {
+	case timestampsRFC3339:
+		return func() string {
+			return now().Format(logTimeFormat)
+		}, nil
+	case timestampsElapsed:
+		return func() string {
+			d := now().Sub(start)
+			return fmt.Sprintf("%02d:%02d:%02d.%03d",
+				int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid --timestamps %q: expected 'rfc3339' or 'elapsed'", format)
+	}
+}
+
+// stampWriter prefixes every line written to w with a timestamp.
+type stampWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	stamp     func() string
+	midOfLine bool
+}
+
+func (s *stampWriter) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for len(b) > 0 {
+		if !s.midOfLine {
+			if _, err := io.WriteString(s.w, s.stamp()+" "); err != nil {
+				return n, err
+			}
+			s.midOfLine = true
+		}
+		line := b
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line = b[:i+1]
+			s.midOfLine = false
+		}
+		m, err := s.w.Write(line)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		b = b[len(line):]
+	}
+	return n, nil
+}
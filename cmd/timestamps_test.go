@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestStampWriter(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := start
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: timestampsRFC3339,
+			want:   "2025-01-02T03:04:05.000Z hello\n2025-01-02T03:04:05.000Z world\n2025-01-02T04:05:06.789Z !",
+		},
+		{
+			format: timestampsElapsed,
+			want:   "00:00:00.000 hello\n00:00:00.000 world\n01:01:01.789 !",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			now = start
+			stamp, err := newTimestamper(tt.format, start, func() time.Time { return now })
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			w := &stampWriter{w: &buf, stamp: stamp}
+			for _, s := range []string{"hello\nwor", "ld\n"} {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			now = start.Add(time.Hour + time.Minute + time.Second + 789*time.Millisecond)
+			n, err := w.Write([]byte("!"))
+			if err != nil || n != 1 {
+				t.Fatalf("Write() = %d, %v", n, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTimestamper_Invalid(t *testing.T) {
+	if _, err := newTimestamper("unix", time.Now(), time.Now); err == nil {
+		t.Error("newTimestamper() should return error for an invalid format")
+	}
+}
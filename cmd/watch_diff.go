@@ -0,0 +1,216 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+var confirmChanges bool
+
+func init() {
+	rootCmd.Flags().BoolVar(&confirmChanges, "confirm-changes", false,
+		"in watch mode, show the diff of changed blocks and ask for confirmation before running them")
+}
+
+// Kinds of block changes between watch runs.
+const (
+	changeAdded    = "+"
+	changeRemoved  = "-"
+	changeModified = "~"
+)
+
+// blockChange is a code block added, removed or changed since the previous run.
+type blockChange struct {
+	kind  string
+	index int              // 0-based index of the block (in the previous run if removed)
+	block parser.CodeBlock // The block (as of the previous run if removed)
+	old   parser.CodeBlock // The block as of the previous run if changed
+}
+
+// watchDiff tracks the code blocks of the document across runs in watch mode,
+// so that the user sees which blocks were added, removed or changed before they re-run.
+type watchDiff struct {
+	prev []parser.CodeBlock
+	seen bool
+}
+
+// show writes the changes of the blocks since the previous run to w and returns them.
+// Nothing is written on the first run.
+func (d *watchDiff) show(w io.Writer, blocks []parser.CodeBlock) []blockChange {
+	if !d.seen {
+		return nil
+	}
+	changes := diffBlocks(d.prev, blocks)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No code blocks changed since the previous run")
+		return nil
+	}
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.kind]++
+	}
+	fmt.Fprintf(w, "Code blocks since the previous run: %d changed, %d added, %d removed\n",
+		counts[changeModified], counts[changeAdded], counts[changeRemoved])
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %s %s\n", c.kind, blockLabel(c.index, c.block))
+	}
+	return changes
+}
+
+// record records the blocks of a run as the previous ones.
+func (d *watchDiff) record(blocks []parser.CodeBlock) {
+	d.prev, d.seen = blocks, true
+}
+
+// blockLabel returns a short description of a code block for messages.
+func blockLabel(index int, block parser.CodeBlock) string {
+	label := fmt.Sprintf("block %d (line %d, %s)", index+1, block.Line, block.Language)
+	if name := block.Name(); name != "" {
+		label += " " + name
+	}
+	if len(block.HeadingPath) > 0 {
+		label += " in " + strings.Join(block.HeadingPath, " > ")
+	}
+	return label
+}
+
+// diffBlocks returns the changes from the blocks of prev to the blocks of cur.
+// Blocks with the same language, command, attributes and content are the same even if they moved.
+// Other blocks are paired as changed in order by name, or by language and headings if they are unnamed.
+func diffBlocks(prev, cur []parser.CodeBlock) []blockChange {
+	oldUsed := make([]bool, len(prev))
+	newUsed := make([]bool, len(cur))
+	for i, b := range cur {
+		for j, o := range prev {
+			if !oldUsed[j] && blockKey(o) == blockKey(b) {
+				oldUsed[j], newUsed[i] = true, true
+				break
+			}
+		}
+	}
+
+	var changes []blockChange
+	for i, b := range cur {
+		if newUsed[i] {
+			continue
+		}
+		change := blockChange{kind: changeAdded, index: i, block: b}
+		for j, o := range prev {
+			if !oldUsed[j] && sameBlock(o, b) {
+				oldUsed[j] = true
+				change.kind, change.old = changeModified, o
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	for j, o := range prev {
+		if !oldUsed[j] {
+			changes = append(changes, blockChange{kind: changeRemoved, index: j, block: o})
+		}
+	}
+	return changes
+}
+
+// blockKey returns what identifies an unchanged code block.
+func blockKey(b parser.CodeBlock) string {
+	return fmt.Sprintf("%q %q %q %q %v", b.Language, b.AltLanguages, b.Command, b.Content, b.Attributes)
+}
+
+// sameBlock reports whether a changed code block is likely a new version of the old one.
+func sameBlock(old, b parser.CodeBlock) bool {
+	if old.Name() != "" || b.Name() != "" {
+		return old.Name() == b.Name()
+	}
+	return old.Language == b.Language && slices.Equal(old.HeadingPath, b.HeadingPath)
+}
+
+// confirmBlockChanges shows the content diff of the added and changed blocks on w
+// and asks for confirmation before they run (unless --yes).
+func confirmBlockChanges(w io.Writer, args []string, changes []blockChange) error {
+	n := 0
+	for _, c := range changes {
+		if c.kind == changeRemoved {
+			continue
+		}
+		n++
+		fmt.Fprintf(w, "%s %s\n", c.kind, blockLabel(c.index, c.block))
+		for _, l := range diffLines(c.old.Content, c.block.Content) {
+			fmt.Fprintf(w, "    %s\n", l)
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return confirmOnTerminal(args, fmt.Sprintf("Run the new version of %d block(s)?", n))
+}
+
+// diffLines returns the lines of a and b prefixed with "-" if they are only in a,
+// "+" if they are only in b and " " if they are in both.
+func diffLines(a, b string) []string {
+	split := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	x, y := split(a), split(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, " "+x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+x[i])
+			i++
+		default:
+			lines = append(lines, "+"+y[j])
+			j++
+		}
+	}
+	return lines
+}
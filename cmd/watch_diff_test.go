@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestWatchDiff(t *testing.T) {
+	parse := func(s string) []parser.CodeBlock {
+		t.Helper()
+		blocks, err := parser.Parse([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blocks
+	}
+	d := &watchDiff{}
+	var buf bytes.Buffer
+	update := func(blocks []parser.CodeBlock) {
+		d.show(&buf, blocks)
+		d.record(blocks)
+	}
+
+	update(parse("# Setup\n\n```sh sh\necho a\n```\n\n```sh {name=build} sh\nmake\n```\n\n```sh sh\necho c\n```\n\n# Clean\n\n```sh sh\nrm -rf tmp\n```\n"))
+	if buf.Len() != 0 {
+		t.Errorf("first run should not show a diff: %q", buf.String())
+	}
+
+	update(parse("Intro.\n\n# Setup\n\n```sh sh\necho a\n```\n\n```sh {name=build} sh\nmake all\n```\n\n# Clean\n\n```sh sh\nrm -rf tmp dist\n```\n\n```go\npackage main\n```\n"))
+	want := `Code blocks since the previous run: 2 changed, 1 added, 1 removed
+  ~ block 2 (line 9, sh) build in Setup
+  ~ block 3 (line 15, sh) in Clean
+  + block 4 (line 19, go) in Clean
+  - block 3 (line 11, sh) in Setup
+`
+	if got := buf.String(); got != want {
+		t.Errorf("diff = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	update(parse("Edited intro.\n\n# Setup\n\n```sh sh\necho a\n```\n\n```sh {name=build} sh\nmake all\n```\n\n# Clean\n\n```sh sh\nrm -rf tmp dist\n```\n\n```go\npackage main\n```\n"))
+	if want := "No code blocks changed since the previous run\n"; buf.String() != want {
+		t.Errorf("diff = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\nd\n", "a\nc\nx\nd\ny\n")
+	want := []string{" a", "-b", " c", "+x", " d", "+y"}
+	if !slices.Equal(got, want) {
+		t.Errorf("diffLines() = %q, want %q", got, want)
+	}
+	if got := diffLines("", "rm -rf /tmp/x\n"); !slices.Equal(got, []string{"+rm -rf /tmp/x"}) {
+		t.Errorf("diffLines() of a new block = %q", got)
+	}
+}
+
+func TestConfirmBlockChanges(t *testing.T) {
+	changes := []blockChange{
+		{kind: changeModified, index: 0, block: parser.CodeBlock{Language: "sh", Line: 3, Content: "rm -rf build/\n"}, old: parser.CodeBlock{Content: "rm -rf build\n"}},
+		{kind: changeRemoved, index: 1, block: parser.CodeBlock{Language: "sh", Line: 7, Content: "echo\n"}},
+	}
+	assumeYes = true
+	t.Cleanup(func() { assumeYes = false })
+	var buf bytes.Buffer
+	if err := confirmBlockChanges(&buf, []string{"runbook.md"}, changes); err != nil {
+		t.Fatalf("confirmBlockChanges() error = %v", err)
+	}
+	if want := "~ block 1 (line 3, sh)\n    -rm -rf build\n    +rm -rf build/\n"; buf.String() != want {
+		t.Errorf("diff = %q, want %q", buf.String(), want)
+	}
+
+	// Without a terminal, the changes cannot be confirmed
+	assumeYes = false
+	if err := confirmBlockChanges(&bytes.Buffer{}, []string{"runbook.md"}, changes); err == nil {
+		t.Error("confirmBlockChanges() should return error without a terminal")
+	}
+	if err := confirmBlockChanges(&bytes.Buffer{}, []string{"runbook.md"}, changes[1:]); err != nil {
+		t.Errorf("removed blocks should not need confirmation: %v", err)
+	}
+}
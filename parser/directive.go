@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// AttrWorkdir is the attribute setting the working directory of a code block (e.g., {workdir=services/api}).
+//
+// The workdir directive, an HTML comment on its own lines (<!-- runblock:workdir services/api -->),
+// sets it for the following code blocks under the same heading, including its subsections,
+// until the next directive or the next heading of the same or a higher level.
+// A directive in a subsection takes precedence over the one of the enclosing section until the subsection ends.
+// The attribute of a block takes precedence over the directive.
+const AttrWorkdir = "workdir"
+
+// directivePrefix is the prefix of the names of directives in HTML comments.
+const directivePrefix = "runblock:"
+
+var directiveRe = regexp.MustCompile(`(?s)\A<!--\s*` + directivePrefix + `([a-z-]+)(?:\s+(.*?))?\s*-->\z`)
+
+// directive returns the name and the argument of the directive in an HTML comment block.
+func directive(n *ast.HTMLBlock, source []byte) (name, arg string, ok bool) {
+	if n.HTMLBlockType != ast.HTMLBlockType2 {
+		return "", "", false
+	}
+	var text strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		text.Write(line.Value(source))
+	}
+	if n.HasClosure() {
+		text.Write(n.ClosureLine.Value(source))
+	}
+	m := directiveRe.FindStringSubmatch(strings.TrimSpace(text.String()))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// workdirs tracks the workdir directives of the sections of a document.
+type workdirs struct {
+	dirs  [7]string // Directive of each heading level (0 before the first heading)
+	level int       // Level of the current heading
+}
+
+// heading starts a section of the level, which ends the directives of the sections of the same or a deeper level.
+func (w *workdirs) heading(level int) {
+	clear(w.dirs[level:])
+	w.level = level
+}
+
+// set sets the directive of the current section.
+func (w *workdirs) set(dir string) {
+	w.dirs[w.level] = dir
+}
+
+// current returns the working directory given by the directives to a code block in the current section.
+func (w *workdirs) current() string {
+	for i := w.level; i >= 0; i-- {
+		if w.dirs[i] != "" {
+			return w.dirs[i]
+		}
+	}
+	return ""
+}
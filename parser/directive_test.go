@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import "testing"
+
+func TestParse_WorkdirDirective(t *testing.T) {
+	source := "```sh\nroot\n```\n" +
+		"# Services\n\n<!-- runblock:workdir services -->\n\n```sh\nservices\n```\n" +
+		"## API\n\n```sh\ninherited\n```\n\n" +
+		"<!--\n  runblock:workdir services/api\n-->\n\n```sh\napi\n```\n\n" +
+		"```sh {workdir=tools}\nattribute\n```\n" +
+		"## Web\n\n```sh\nresumed\n```\n\n<!-- runblock:workdir . -->\n\n```sh\nreset\n```\n" +
+		"# Docs\n\n<!-- not a directive -->\n\n```sh\nended\n```\n"
+	blocks, err := Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"root\n":      "",
+		"services\n":  "services",
+		"inherited\n": "services",
+		"api\n":       "services/api",
+		"attribute\n": "tools",
+		"resumed\n":   "services",
+		"reset\n":     ".",
+		"ended\n":     "",
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(want))
+	}
+	for _, b := range blocks {
+		if got := b.Attributes[AttrWorkdir]; got != want[b.Content] {
+			t.Errorf("block %q: got workdir %q, want %q", b.Content, got, want[b.Content])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import "bytes"
+
+// FrontMatter returns the YAML front matter at the start of Markdown source without its delimiters
+// (--- lines), and the byte offset just after the closing delimiter line (0 if there is none).
+func FrontMatter(source []byte) ([]byte, int) {
+	rest, ok := bytes.CutPrefix(source, []byte("---\n"))
+	if !ok {
+		if rest, ok = bytes.CutPrefix(source, []byte("---\r\n")); !ok {
+			return nil, 0
+		}
+	}
+	start := len(source) - len(rest)
+	for offset := start; offset < len(source); {
+		end := bytes.IndexByte(source[offset:], '\n')
+		next := offset + end + 1
+		if end < 0 {
+			next = len(source)
+		}
+		line := bytes.TrimRight(source[offset:next], "\r\n")
+		if string(line) == "---" || string(line) == "..." {
+			return source[start:offset], next
+		}
+		offset = next
+	}
+	return nil, 0
+}
+
+// maskFrontMatter returns source with the front matter blanked out, keeping its line breaks
+// so that the lines and offsets of the rest of the document do not change.
+func maskFrontMatter(source []byte) []byte {
+	_, end := FrontMatter(source)
+	if end == 0 {
+		return source
+	}
+	masked := bytes.Clone(source)
+	for i := range end {
+		if masked[i] != '\n' && masked[i] != '\r' {
+			masked[i] = ' '
+		}
+	}
+	return masked
+}
@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import "testing"
+
+func TestFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantEnd int
+	}{
+		{"front matter", "---\ntitle: x\n---\n# Doc\n", "title: x\n", 17},
+		{"dots", "---\ntitle: x\n...\n", "title: x\n", 17},
+		{"crlf", "---\r\ntitle: x\r\n---\r\n", "title: x\r\n", 20},
+		{"empty", "---\n---\n", "", 8},
+		{"no newline at end", "---\ntitle: x\n---", "title: x\n", 16},
+		{"unclosed", "---\ntitle: x\n", "", 0},
+		{"not at the start", "\n---\ntitle: x\n---\n", "", 0},
+		{"none", "# Doc\n", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, end := FrontMatter([]byte(tt.source))
+			if string(got) != tt.want || end != tt.wantEnd {
+				t.Errorf("got (%q, %d), want (%q, %d)", got, end, tt.want, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestParse_FrontMatter(t *testing.T) {
+	source := "---\nrunblock:\n  default_command: sh\n---\n\n```sh\necho hi\n```\n"
+	blocks, err := Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("got %d blocks, want 1", len(blocks))
+	}
+	if blocks[0].Heading != "" {
+		t.Errorf("got heading %q, want none", blocks[0].Heading)
+	}
+	if blocks[0].Line != 6 || blocks[0].Offset != 41 {
+		t.Errorf("got line %d at offset %d, want line 6 at offset 41", blocks[0].Line, blocks[0].Offset)
+	}
+}
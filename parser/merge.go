@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MergeAdjacent merges consecutive code blocks with the same language, command and attributes
+// into single blocks, so that a script split by prose for readability runs as one unit.
+// The merged block spans from the opening fence of the first block to the closing fence of the last.
+func MergeAdjacent(blocks []CodeBlock) []CodeBlock {
+	var merged []CodeBlock
+	for _, b := range blocks {
+		if n := len(merged); n > 0 && mergeable(merged[n-1], b) {
+			last := &merged[n-1]
+			if last.Content != "" && !strings.HasSuffix(last.Content, "\n") {
+				last.Content += "\n"
+			}
+			last.Content += b.Content
+			last.EndLine = b.EndLine
+			last.EndOffset = b.EndOffset
+			continue
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
+
+// mergeable reports whether b can be merged into a.
+func mergeable(a, b CodeBlock) bool {
+	return a.Language == b.Language &&
+		a.Command == b.Command &&
+		slices.Equal(a.AltLanguages, b.AltLanguages) &&
+		maps.Equal(a.Attributes, b.Attributes)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import (
+	"testing"
+)
+
+func TestMergeAdjacent(t *testing.T) {
+	source := []byte("```sh\necho 1\n```\n\nThen:\n\n```sh\necho 2\n```\n\n```go\npackage main\n```\n\n```sh {name=x}\necho 3\n```\n\n```sh {name=x}\necho 4\n```\n")
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := MergeAdjacent(blocks)
+	want := []struct {
+		lang      string
+		content   string
+		line, end int
+	}{
+		{"sh", "echo 1\necho 2\n", 1, 9},
+		{"go", "package main\n", 11, 13},
+		{"sh", "echo 3\necho 4\n", 15, 21},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("MergeAdjacent() got %d blocks, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		b := got[i]
+		if b.Language != w.lang || b.Content != w.content || b.Line != w.line || b.EndLine != w.end {
+			t.Errorf("blocks[%d] = %q %q lines %d-%d, want %q %q lines %d-%d", i, b.Language, b.Content, b.Line, b.EndLine, w.lang, w.content, w.line, w.end)
+		}
+	}
+	if got[0].EndOffset != blocks[1].EndOffset {
+		t.Errorf("EndOffset = %d, want %d", got[0].EndOffset, blocks[1].EndOffset)
+	}
+}
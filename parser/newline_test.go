@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package parser
+
+import (
+	"testing"
+)
+
+func TestNormalizeNewlines(t *testing.T) {
+	source := []byte("```sh\r\necho 1\r\necho 2\r\n```\r\n\r\n```sh\necho 3\n```\n")
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := NormalizeNewlines(blocks)
+	want := []string{"echo 1\necho 2\n", "echo 3\n"}
+	if len(got) != len(want) {
+		t.Fatalf("NormalizeNewlines() got %d blocks, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Content != w {
+			t.Errorf("blocks[%d].Content = %q, want %q", i, got[i].Content, w)
+		}
+	}
+}
@@ -22,6 +22,8 @@ THE SOFTWARE.
 package parser
 
 import (
+	"bytes"
+	"strconv"
 	"strings"
 
 	"github.com/yuin/goldmark"
@@ -31,24 +33,81 @@ import (
 
 // CodeBlock represents a fenced code block extracted from Markdown.
 type CodeBlock struct {
-	Language string // Language identifier (e.g., "go", "python")
-	Command  string // Command to execute (e.g., "/path/to/cmd {{lang}} {{content}}")
-	Content  string // Content of the code block
+	Language     string            // Language identifier (e.g., "go", "python")
+	AltLanguages []string          // Additional identifiers of a comma separated language list (e.g., "jsonc" of "json,jsonc")
+	Command      string            // Command to execute (e.g., "/path/to/cmd {{lang}} {{content}}")
+	Content      string            // Content of the code block
+	Attributes   map[string]string // Attributes in braces after the language (e.g., {env.FOO=bar})
+	Line         int               // 1-based line number of the opening fence
+	EndLine      int               // 1-based line number of the closing fence
+	Offset       int               // Byte offset of the start of the opening fence line
+	EndOffset    int               // Byte offset just after the closing fence line
+	Heading      string            // Text of the nearest heading before the code block as written in the source
+	HeadingPath  []string          // Texts of the headings enclosing the code block, outermost first
+}
+
+// Languages returns the language identifier followed by the additional ones.
+func (b CodeBlock) Languages() []string {
+	return append([]string{b.Language}, b.AltLanguages...)
+}
+
+// AttrName is the attribute naming a code block (e.g., {name=build}).
+const AttrName = "name"
+
+// Name returns the name of the code block given by the name attribute.
+func (b CodeBlock) Name() string {
+	return b.Attributes[AttrName]
+}
+
+// AttrTags is the attribute tagging a code block with comma separated tags (e.g., {tags=ci,slow}).
+const AttrTags = "tags"
+
+// Tags returns the tags of the code block given by the tags attribute.
+func (b CodeBlock) Tags() []string {
+	var tags []string
+	for _, t := range strings.Split(b.Attributes[AttrTags], ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
 }
 
 // Parse parses Markdown source and extracts fenced code blocks.
 func Parse(source []byte) ([]CodeBlock, error) { //nostyle:repetition
+	// The front matter is not a part of the document (it would be a thematic break and a heading)
+	source = maskFrontMatter(source)
 	md := goldmark.New()
 	reader := text.NewReader(source)
 	doc := md.Parser().Parse(reader)
 
-	var blocks []CodeBlock
+	var (
+		blocks   []CodeBlock
+		heading  string
+		headings [6]string // Current heading of each level
+		dirs     workdirs
+	)
 
 	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
+		if h, ok := n.(*ast.Heading); ok {
+			heading = headingText(h, source)
+			headings[h.Level-1] = heading
+			clear(headings[h.Level:])
+			dirs.heading(h.Level)
+			return ast.WalkSkipChildren, nil
+		}
+
+		if hb, ok := n.(*ast.HTMLBlock); ok {
+			if name, arg, ok := directive(hb, source); ok && name == AttrWorkdir {
+				dirs.set(arg)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
 		fcb, ok := n.(*ast.FencedCodeBlock)
 		if !ok {
 			return ast.WalkContinue, nil
@@ -60,7 +119,15 @@ func Parse(source []byte) ([]CodeBlock, error) { //nostyle:repetition
 			info = string(fcb.Info.Segment.Value(source))
 		}
 
-		lang, cmd := ParseInfoString(info)
+		lang, attrs, cmd := parseInfo(info)
+		if dir := dirs.current(); dir != "" {
+			if _, ok := attrs[AttrWorkdir]; !ok {
+				if attrs == nil {
+					attrs = map[string]string{}
+				}
+				attrs[AttrWorkdir] = dir
+			}
+		}
 
 		// Extract content from lines
 		var content strings.Builder
@@ -70,10 +137,34 @@ func Parse(source []byte) ([]CodeBlock, error) { //nostyle:repetition
 			content.Write(line.Value(source))
 		}
 
+		// Locate the fences
+		line := lineAt(source, fcb.Pos())
+		endLine := line + 1
+		if lines.Len() > 0 {
+			endLine = lineAt(source, lines.At(lines.Len()-1).Stop-1) + 1
+		}
+
+		// A comma separated list gives alternative identifiers (e.g., "bash,sh")
+		lang, alts, _ := strings.Cut(lang, ",")
+		var altLangs []string
+		for _, l := range strings.Split(alts, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				altLangs = append(altLangs, l)
+			}
+		}
+
 		blocks = append(blocks, CodeBlock{
-			Language: lang,
-			Command:  cmd,
-			Content:  content.String(),
+			Language:     lang,
+			AltLanguages: altLangs,
+			Command:      cmd,
+			Content:      content.String(),
+			Attributes:   attrs,
+			Line:         line,
+			EndLine:      endLine,
+			Offset:       lineOffset(source, line),
+			EndOffset:    lineOffset(source, endLine+1),
+			Heading:      heading,
+			HeadingPath:  headingPath(headings[:]),
 		})
 
 		return ast.WalkContinue, nil
@@ -86,25 +177,212 @@ func Parse(source []byte) ([]CodeBlock, error) { //nostyle:repetition
 	return blocks, nil
 }
 
+// headingText returns the text of a heading as written in the source.
+func headingText(h *ast.Heading, source []byte) string {
+	var text strings.Builder
+	lines := h.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		text.Write(line.Value(source))
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// headingPath returns the non-empty headings of the levels, or nil if there are none.
+func headingPath(headings []string) []string {
+	var path []string
+	for _, h := range headings {
+		if h != "" {
+			path = append(path, h)
+		}
+	}
+	return path
+}
+
+// lineAt returns the 1-based line number of the byte offset in source.
+func lineAt(source []byte, offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	return bytes.Count(source[:min(offset, len(source))], []byte("\n")) + 1
+}
+
+// lineOffset returns the byte offset of the start of the 1-based line in source.
+// It returns len(source) if source has fewer lines.
+func lineOffset(source []byte, line int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		i := bytes.IndexByte(source[offset:], '\n')
+		if i < 0 {
+			return len(source)
+		}
+		offset += i + 1
+	}
+	return offset
+}
+
 // ParseInfoString parses the info string of a fenced code block.
 // It returns the language identifier and the command (if any).
-// Format: "language [command]"
+// Format: "language [{attributes}] [command]"
 // Example: "go /usr/bin/gofmt {{content}}" -> ("go", "/usr/bin/gofmt {{content}}")
 func ParseInfoString(info string) (language, command string) { //nostyle:repetition
+	language, _, command = parseInfo(info)
+	return language, command
+}
+
+// parseInfo parses the info string of a fenced code block into
+// the language identifier, the attributes and the command.
+func parseInfo(info string) (language string, attrs map[string]string, command string) {
 	info = strings.TrimSpace(info)
 	if info == "" {
-		return "", ""
+		return "", nil, ""
 	}
 
 	// Split on first space to separate language from command
 	idx := strings.Index(info, " ")
 	if idx < 0 {
 		// No space, only language
-		return info, ""
+		return info, nil, ""
 	}
 
 	language = info[:idx]
 	command = strings.TrimSpace(info[idx+1:])
 
-	return language, command
+	// Attributes are enclosed in braces right after the language.
+	// Braces holding other tokens are a command (e.g., a shell group "{ cat; }").
+	if strings.HasPrefix(command, "{") && !strings.HasPrefix(command, "{{") {
+		end := closingBrace(command)
+		if end > 0 && isAttributes(command[1:end]) {
+			attrs = ParseAttributes(command[1:end])
+			command = strings.TrimSpace(command[end+1:])
+		}
+	}
+
+	return language, attrs, command
+}
+
+// isAttributes reports whether every token of s is a key=value pair or a bare key.
+func isAttributes(s string) bool {
+	for _, token := range splitFields(s) {
+		key, _, _ := strings.Cut(token, "=")
+		if !isAttributeKey(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAttributeKey reports whether s is a valid attribute key (e.g., "name", "env.FOO", "expect-stdout~").
+func isAttributeKey(s string) bool {
+	s = strings.TrimSuffix(s, "~")
+	if s == "" || s[0] == '.' || s[0] == '-' {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '_' || c == '.' || c == '-' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// closingBrace returns the index of the brace closing the one at the beginning of s, or -1.
+// Braces inside quotes are ignored.
+func closingBrace(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ParseAttributes parses whitespace separated key=value pairs.
+// Values may be quoted with double or single quotes. A key without value is set to "true".
+// Example: `env.FOO=bar name="my block"` -> {"env.FOO": "bar", "name": "my block"}
+func ParseAttributes(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, token := range splitFields(s) {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok {
+			attrs[token] = "true"
+			continue
+		}
+		attrs[key] = unquote(value)
+	}
+	return attrs
+}
+
+// splitFields splits s on whitespace outside quotes and braces.
+func splitFields(s string) []string {
+	var (
+		fields []string
+		cur    strings.Builder
+		quote  byte
+		depth  int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(s) {
+				cur.WriteByte(c)
+				i++
+				c = s[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t'):
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+// unquote removes surrounding quotes from an attribute value.
+func unquote(v string) string {
+	if len(v) < 2 {
+		return v
+	}
+	switch {
+	case v[0] == '"' && v[len(v)-1] == '"':
+		if u, err := strconv.Unquote(v); err == nil {
+			return u
+		}
+		return v[1 : len(v)-1]
+	case v[0] == '\'' && v[len(v)-1] == '\'':
+		return v[1 : len(v)-1]
+	}
+	return v
 }
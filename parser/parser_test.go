@@ -22,6 +22,7 @@ THE SOFTWARE.
 package parser
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -240,3 +241,190 @@ func TestParse_CodeBlockWithTemplateVariables(t *testing.T) {
 		t.Errorf("blocks[0].Command = %q, want %q", blocks[0].Command, "/path/to/cmd {{lang}} {{content}}")
 	}
 }
+
+func TestParse_Attributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		info        string
+		wantLang    string
+		wantCommand string
+		wantAttrs   map[string]string
+	}{
+		{
+			name:        "attributes and command",
+			info:        "sh {env.FOO=bar env.BAZ=qux} echo $FOO",
+			wantLang:    "sh",
+			wantCommand: "echo $FOO",
+			wantAttrs:   map[string]string{"env.FOO": "bar", "env.BAZ": "qux"},
+		},
+		{
+			name:        "quoted values",
+			info:        `sh {name="my block" msg='a } b' flag} cat`,
+			wantLang:    "sh",
+			wantCommand: "cat",
+			wantAttrs:   map[string]string{"name": "my block", "msg": "a } b", "flag": "true"},
+		},
+		{
+			name:        "attributes only",
+			info:        "sh {env.FOO=bar}",
+			wantLang:    "sh",
+			wantCommand: "",
+			wantAttrs:   map[string]string{"env.FOO": "bar"},
+		},
+		{
+			name:        "template is not attributes",
+			info:        "sh {{lang}}",
+			wantLang:    "sh",
+			wantCommand: "{{lang}}",
+			wantAttrs:   nil,
+		},
+		{
+			name:        "shell group is not attributes",
+			info:        "sh { cat; }",
+			wantLang:    "sh",
+			wantCommand: "{ cat; }",
+			wantAttrs:   nil,
+		},
+		{
+			name:        "shell group with redirect is not attributes",
+			info:        "sh { echo start; cat; } 2>&1",
+			wantLang:    "sh",
+			wantCommand: "{ echo start; cat; } 2>&1",
+			wantAttrs:   nil,
+		},
+		{
+			name:        "unclosed brace",
+			info:        "sh {env.FOO=bar",
+			wantLang:    "sh",
+			wantCommand: "{env.FOO=bar",
+			wantAttrs:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := []byte("```" + tt.info + "\ncontent\n```\n")
+			blocks, err := Parse(source)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(blocks) != 1 {
+				t.Fatalf("Parse() got %d blocks, want 1", len(blocks))
+			}
+			if blocks[0].Language != tt.wantLang {
+				t.Errorf("Language = %q, want %q", blocks[0].Language, tt.wantLang)
+			}
+			if blocks[0].Command != tt.wantCommand {
+				t.Errorf("Command = %q, want %q", blocks[0].Command, tt.wantCommand)
+			}
+			if len(blocks[0].Attributes) != len(tt.wantAttrs) {
+				t.Fatalf("Attributes = %v, want %v", blocks[0].Attributes, tt.wantAttrs)
+			}
+			for k, v := range tt.wantAttrs {
+				if got := blocks[0].Attributes[k]; got != v {
+					t.Errorf("Attributes[%q] = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestCodeBlock_NameAndTags(t *testing.T) {
+	blocks, err := Parse([]byte("```sh {name=build tags=\"ci, slow,\"} sh\n```\n```sh\n```\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := blocks[0].Name(); got != "build" {
+		t.Errorf("Name() = %q, want %q", got, "build")
+	}
+	if got := blocks[0].Tags(); len(got) != 2 || got[0] != "ci" || got[1] != "slow" {
+		t.Errorf("Tags() = %v, want [ci slow]", got)
+	}
+	if got := blocks[1].Name(); got != "" {
+		t.Errorf("Name() = %q, want empty", got)
+	}
+	if got := blocks[1].Tags(); got != nil {
+		t.Errorf("Tags() = %v, want nil", got)
+	}
+}
+
+func TestParse_LineNumbers(t *testing.T) {
+	source := []byte("# Title\n\n```sh\necho 1\necho 2\n```\n\n- item\n\n  ```\n  indented\n  ```\n\n```go\n```\n")
+
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := [][2]int{{3, 6}, {10, 12}, {14, 15}}
+	if len(blocks) != len(want) {
+		t.Fatalf("Parse() got %d blocks, want %d", len(blocks), len(want))
+	}
+	for i, w := range want {
+		if blocks[i].Line != w[0] || blocks[i].EndLine != w[1] {
+			t.Errorf("blocks[%d] lines = %d-%d, want %d-%d", i, blocks[i].Line, blocks[i].EndLine, w[0], w[1])
+		}
+	}
+	wantOffsets := [][2]int{{9, 33}, {42, 65}, {66, 76}}
+	for i, w := range wantOffsets {
+		if blocks[i].Offset != w[0] || blocks[i].EndOffset != w[1] {
+			t.Errorf("blocks[%d] offsets = %d-%d, want %d-%d", i, blocks[i].Offset, blocks[i].EndOffset, w[0], w[1])
+		}
+	}
+}
+
+func TestParse_MultipleLanguages(t *testing.T) {
+	source := []byte("```json,jsonc {name=conf} cat\n{}\n```\n\n```bash, sh\necho\n```\n")
+
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("Parse() got %d blocks, want 2", len(blocks))
+	}
+	if got, want := blocks[0].Languages(), []string{"json", "jsonc"}; !slices.Equal(got, want) {
+		t.Errorf("blocks[0].Languages() = %v, want %v", got, want)
+	}
+	if blocks[0].Language != "json" || blocks[0].Command != "cat" || blocks[0].Name() != "conf" {
+		t.Errorf("blocks[0] = %+v", blocks[0])
+	}
+	if got := blocks[1].Languages(); !slices.Equal(got, []string{"bash"}) {
+		t.Errorf("blocks[1].Languages() = %v, want [bash]", got)
+	}
+}
+
+func TestParse_Heading(t *testing.T) {
+	source := []byte("```sh\necho 0\n```\n\n# Title\n\n```sh\necho 1\n```\n\nSet up `db`\n---\n\n> ```sh\n> echo 2\n> ```\n")
+
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []string{"", "Title", "Set up `db`"}
+	if len(blocks) != len(want) {
+		t.Fatalf("Parse() got %d blocks, want %d", len(blocks), len(want))
+	}
+	for i, w := range want {
+		if blocks[i].Heading != w {
+			t.Errorf("blocks[%d].Heading = %q, want %q", i, blocks[i].Heading, w)
+		}
+	}
+}
+
+func TestParse_HeadingPath(t *testing.T) {
+	source := []byte("# A\n\n## B\n\n### C\n\n```sh\n```\n\n## D\n\n```sh\n```\n\n#### E\n\n```sh\n```\n")
+
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := [][]string{{"A", "B", "C"}, {"A", "D"}, {"A", "D", "E"}}
+	if len(blocks) != len(want) {
+		t.Fatalf("Parse() got %d blocks, want %d", len(blocks), len(want))
+	}
+	for i, w := range want {
+		if !slices.Equal(blocks[i].HeadingPath, w) {
+			t.Errorf("blocks[%d].HeadingPath = %q, want %q", i, blocks[i].HeadingPath, w)
+		}
+	}
+}
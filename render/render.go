@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+// Package render renders the results of code blocks back into their Markdown document.
+//
+// The document is kept as it is, and the result of each executed code block is
+// annotated after its closing fence: a status badge, and its output in collapsible
+// sections (expanded for failed blocks), so that a run can be published as a document.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/runblock/runner"
+)
+
+// Statuses of code blocks.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// badges maps statuses to the marks of their badges.
+var badges = map[string]string{
+	StatusPassed:  "✅",
+	StatusFailed:  "❌",
+	StatusSkipped: "⏭️",
+}
+
+// Badge returns the mark of the status (e.g., ✅ for passed).
+func Badge(status string) string {
+	return badges[status]
+}
+
+// Status returns the status of the result of a code block.
+func Status(result *runner.Result) string {
+	switch {
+	case result.Err != nil:
+		return StatusFailed
+	case result.Skipped:
+		return StatusSkipped
+	default:
+		return StatusPassed
+	}
+}
+
+// Markdown writes source with the result of each code block annotated after the block.
+// source must be the document the blocks of the results were parsed from. Code blocks without
+// a result are left as they are, and the last result is used for blocks with more than one.
+func Markdown(w io.Writer, source []byte, results []*runner.Result) error {
+	after := map[int]*runner.Result{} // Results by the line of their closing fences
+	for _, result := range results {
+		after[result.Block.EndLine] = result
+	}
+	lines := strings.SplitAfter(string(source), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		b.WriteString(line)
+		result, ok := after[i+1]
+		if !ok {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(Annotation(result))
+		if i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Annotation returns the Markdown annotating the result of a code block:
+// a status badge, and its error and output in collapsible sections (expanded if the block failed).
+func Annotation(result *runner.Result) string {
+	status := Status(result)
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s %s**", Badge(status), status)
+	switch status {
+	case StatusSkipped:
+		if result.SkipReason != "" {
+			fmt.Fprintf(&b, " (%s)", result.SkipReason)
+		}
+	default:
+		fmt.Fprintf(&b, " (exit code %d, %s)", result.ExitCode, result.Duration.Round(time.Millisecond))
+	}
+	b.WriteString("\n")
+	if result.Err != nil {
+		fmt.Fprintf(&b, "\nError: %s\n", strings.TrimSpace(result.Err.Error()))
+	}
+	for _, out := range []struct{ name, s string }{{"stdout", result.Stdout}, {"stderr", result.Stderr}} {
+		if out.s == "" {
+			continue
+		}
+		open := ""
+		if status == StatusFailed {
+			open = " open"
+		}
+		fmt.Fprintf(&b, "\n<details%s><summary>%s</summary>\n\n%s\n</details>\n", open, out.name, Fenced("", out.s))
+	}
+	return b.String()
+}
+
+// Fenced returns s in a fenced code block with the language, with a fence longer than any backtick run in s.
+func Fenced(lang, s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return fence + lang + "\n" + s + fence
+}
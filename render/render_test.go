@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package render
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/runner"
+)
+
+func TestMarkdown(t *testing.T) {
+	source := []byte("# Setup\n\n```sh echo\nhello\n```\nNext step.\n\n```sh false\n```\n\n```text\nnot run\n```\n")
+	blocks, err := parser.Parse(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := []*runner.Result{
+		{Index: 0, Block: blocks[0], Duration: 12 * time.Millisecond, Stdout: "hello\n"},
+		{Index: 1, Block: blocks[1], ExitCode: 1, Duration: 3 * time.Millisecond, Stderr: "```\n", Err: errors.New("exit status 1")},
+	}
+	var buf bytes.Buffer
+	if err := Markdown(&buf, source, results); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Setup\n\n```sh echo\nhello\n```\n\n" +
+		"**✅ passed** (exit code 0, 12ms)\n\n<details><summary>stdout</summary>\n\n```\nhello\n```\n</details>\n\n" +
+		"Next step.\n\n```sh false\n```\n\n" +
+		"**❌ failed** (exit code 1, 3ms)\n\nError: exit status 1\n\n<details open><summary>stderr</summary>\n\n````\n```\n````\n</details>\n\n" +
+		"```text\nnot run\n```\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAnnotation_Skipped(t *testing.T) {
+	got := Annotation(&runner.Result{Skipped: true, SkipReason: "no command specified"})
+	if want := "**⏭️ skipped** (no command specified)\n"; got != want {
+		t.Errorf("Annotation() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+// Package runblock runs the code blocks of Markdown documents like the runblock command.
+//
+// It wires the parser and runner packages together with the defaults of the command
+// (a temporary directory per run, language aliases, block selection and stopping at the
+// first failure), so that embedding runblock does not require configuring them manually.
+// The module root is the command itself, so the package is imported as
+// github.com/k1LoW/runblock/runblock.
+package runblock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/render"
+	"github.com/k1LoW/runblock/runner"
+)
+
+// Option configures a run.
+type Option func(*config) error
+
+// config is the configuration of a run.
+type config struct {
+	defaultCommand    string
+	commands          map[string]string
+	aliases           []string
+	names             []string
+	langs             []string
+	skipLangs         []string
+	keepGoing         bool
+	stdout            io.Writer
+	stderr            io.Writer
+	env               []string
+	timeout           time.Duration
+	parallel          map[string]int
+	policy            string
+	policyAbort       bool
+	normalizeNewlines bool
+	mergeAdjacent     bool
+	captureOutput     bool
+}
+
+// WithDefaultCommand sets the command for code blocks without a command (--default-command).
+func WithDefaultCommand(cmd string) Option {
+	return func(c *config) error {
+		c.defaultCommand = cmd
+		return nil
+	}
+}
+
+// WithCommand sets the command for code blocks of the language (--command lang:cmd).
+func WithCommand(lang, cmd string) Option {
+	return func(c *config) error {
+		if lang == "" {
+			return errors.New("language of the command is empty")
+		}
+		if c.commands == nil {
+			c.commands = map[string]string{}
+		}
+		c.commands[lang] = cmd
+		return nil
+	}
+}
+
+// WithAlias adds equivalent language identifiers such as "shell=sh=bash" (--alias).
+func WithAlias(spec string) Option {
+	return func(c *config) error {
+		c.aliases = append(c.aliases, spec)
+		return nil
+	}
+}
+
+// WithNames runs only the code blocks with the names (--name).
+func WithNames(names ...string) Option {
+	return func(c *config) error {
+		c.names = append(c.names, names...)
+		return nil
+	}
+}
+
+// WithLangs runs only the code blocks with the languages (--lang).
+func WithLangs(langs ...string) Option {
+	return func(c *config) error {
+		c.langs = append(c.langs, langs...)
+		return nil
+	}
+}
+
+// WithSkipLangs never runs the code blocks with the languages (--skip-lang).
+func WithSkipLangs(langs ...string) Option {
+	return func(c *config) error {
+		c.skipLangs = append(c.skipLangs, langs...)
+		return nil
+	}
+}
+
+// WithKeepGoing runs all code blocks even if some of them fail (--exit-policy all).
+func WithKeepGoing() Option {
+	return func(c *config) error {
+		c.keepGoing = true
+		return nil
+	}
+}
+
+// WithOutput sets the writers of the output of the code blocks (os.Stdout and os.Stderr by default).
+func WithOutput(stdout, stderr io.Writer) Option {
+	return func(c *config) error {
+		c.stdout, c.stderr = stdout, stderr
+		return nil
+	}
+}
+
+// WithEnv sets the environment the block processes start with instead of os.Environ().
+func WithEnv(env []string) Option {
+	return func(c *config) error {
+		c.env = env
+		return nil
+	}
+}
+
+// WithTimeout aborts the run after the duration; teardown and always=true blocks still run (--timeout).
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		c.timeout = d
+		return nil
+	}
+}
+
+// WithParallel runs code blocks of the language concurrently up to n (--parallel lang=n).
+func WithParallel(lang string, n int) Option {
+	return func(c *config) error {
+		if n < 1 {
+			return fmt.Errorf("invalid parallelism %s=%d: must be at least 1", lang, n)
+		}
+		if c.parallel == nil {
+			c.parallel = map[string]int{}
+		}
+		c.parallel[lang] = n
+		return nil
+	}
+}
+
+// WithPolicy evaluates the CEL policy expression per block; denied blocks abort the run if abort is true,
+// and are skipped otherwise (--policy and --policy-action).
+func WithPolicy(expr string, abort bool) Option {
+	return func(c *config) error {
+		if err := runner.CheckPolicy(expr); err != nil {
+			return err
+		}
+		c.policy, c.policyAbort = expr, abort
+		return nil
+	}
+}
+
+// WithNormalizeNewlines converts CRLF line endings in block content to LF (--normalize-newlines).
+func WithNormalizeNewlines() Option {
+	return func(c *config) error {
+		c.normalizeNewlines = true
+		return nil
+	}
+}
+
+// WithMergeAdjacent merges consecutive blocks with the same language, command and attributes (--merge-adjacent).
+func WithMergeAdjacent() Option {
+	return func(c *config) error {
+		c.mergeAdjacent = true
+		return nil
+	}
+}
+
+// WithCaptureOutput captures the output of the code blocks into their results in the report.
+func WithCaptureOutput() Option {
+	return func(c *config) error {
+		c.captureOutput = true
+		return nil
+	}
+}
+
+// Report is the result of a run.
+type Report struct {
+	File      string           // Path of the document ("-" for a source without a file)
+	StartedAt time.Time        // When the run started
+	Duration  time.Duration    // How long the run took
+	Results   []*runner.Result // Results of the code blocks in the order they finished
+
+	source []byte
+}
+
+// Count returns the number of code blocks with the status (render.StatusPassed, StatusFailed or StatusSkipped).
+func (rp *Report) Count(status string) int {
+	n := 0
+	for _, result := range rp.Results {
+		if render.Status(result) == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Markdown writes the document with the result of each code block annotated after it (see render.Markdown).
+func (rp *Report) Markdown(w io.Writer) error {
+	return render.Markdown(w, rp.source, rp.Results)
+}
+
+// RunFile runs the code blocks of the Markdown file at path.
+// The report is returned with the error of the run unless the run could not start (e.g., a parse error).
+func RunFile(ctx context.Context, path string, opts ...Option) (*Report, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, path, source, opts)
+}
+
+// RunSource runs the code blocks of the Markdown source.
+// The report is returned with the error of the run unless the run could not start (e.g., a parse error).
+func RunSource(ctx context.Context, source []byte, opts ...Option) (*Report, error) {
+	return run(ctx, "-", source, opts)
+}
+
+// run runs the code blocks of the source of file.
+func run(ctx context.Context, file string, source []byte, opts []Option) (_ *Report, err error) {
+	c := &config{stdout: os.Stdout, stderr: os.Stderr}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	blocks, err := parser.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse markdown: %w", err)
+	}
+	if c.normalizeNewlines {
+		blocks = parser.NormalizeNewlines(blocks)
+	}
+	if c.mergeAdjacent {
+		blocks = parser.MergeAdjacent(blocks)
+	}
+
+	aliases, err := runner.ParseAliases(c.aliases)
+	if err != nil {
+		return nil, err
+	}
+	r := runner.New(c.defaultCommand, c.commands)
+	r.Stdout, r.Stderr = c.stdout, c.stderr
+	r.Aliases = aliases
+	r.Env = c.env
+	r.Parallel = c.parallel
+	r.Policy, r.PolicyAbort = c.policy, c.policyAbort
+	r.KeepGoing = c.keepGoing
+	r.CaptureOutput = c.captureOutput
+	r.File = file
+	r.Select = func(block parser.CodeBlock, _ int) bool {
+		return (len(c.names) == 0 || slices.Contains(c.names, block.Name())) &&
+			(len(c.langs) == 0 || aliases.MatchBlock(block, c.langs)) &&
+			(len(c.skipLangs) == 0 || !aliases.MatchBlock(block, c.skipLangs))
+	}
+
+	// Blocks exchange files through the temporary directory of the run
+	tmpDir, err := os.MkdirTemp("", "runblock-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	r.TmpDir = tmpDir
+	defer func() {
+		err = errors.Join(err, os.RemoveAll(tmpDir))
+	}()
+
+	rp := &Report{File: file, StartedAt: time.Now(), source: source}
+	var mu sync.Mutex
+	r.OnResult = func(result *runner.Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		rp.Results = append(rp.Results, result)
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	err = r.RunAll(ctx, blocks)
+	rp.Duration = time.Since(rp.StartedAt)
+	return rp, err
+}
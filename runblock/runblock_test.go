@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runblock
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/render"
+)
+
+func TestRunSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	source := []byte("```sh {name=greet} sh\necho hello\n```\n\n```sh {name=fail} sh\nexit 3\n```\n\n```sh {name=after} sh\necho after\n```\n")
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantErr    bool
+		wantOut    string
+		wantPassed int
+		wantFailed int
+	}{
+		{"stops at the first failure", nil, true, "hello\n", 1, 1},
+		{"keep going", []Option{WithKeepGoing()}, true, "hello\nafter\n", 2, 1},
+		{"names", []Option{WithNames("greet", "after")}, false, "hello\nafter\n", 2, 0},
+		{"skip langs", []Option{WithSkipLangs("shell"), WithAlias("shell=sh")}, false, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			opts := append([]Option{WithOutput(&stdout, &stderr)}, tt.opts...)
+			rp, err := RunSource(context.Background(), source, opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err %v, want error %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.wantOut {
+				t.Errorf("got stdout %q, want %q", got, tt.wantOut)
+			}
+			if got := rp.Count(render.StatusPassed); got != tt.wantPassed {
+				t.Errorf("got %d passed, want %d", got, tt.wantPassed)
+			}
+			if got := rp.Count(render.StatusFailed); got != tt.wantFailed {
+				t.Errorf("got %d failed, want %d", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestRunSource_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	var stdout bytes.Buffer
+	rp, err := RunSource(context.Background(), []byte("```sh sh\nexec sleep 10\n```\n"), WithOutput(&stdout, &stdout), WithTimeout(100*time.Millisecond))
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if rp.Duration > 5*time.Second {
+		t.Errorf("got duration %s, want the run to be aborted", rp.Duration)
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	var stdout bytes.Buffer
+	rp, err := RunFile(context.Background(), "../testdata/basic.md", WithOutput(&stdout, &stdout), WithCaptureOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "hello world") {
+		t.Errorf("got stdout %q, want it to contain %q", stdout.String(), "hello world")
+	}
+	var md bytes.Buffer
+	if err := rp.Markdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), render.Badge(render.StatusPassed)) {
+		t.Errorf("got %q, want the blocks annotated", md.String())
+	}
+}
+
+func TestRunFile_NotFound(t *testing.T) {
+	if _, err := RunFile(context.Background(), "testdata/missing.md"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrArtifacts is the attribute listing comma separated glob patterns of files
+// collected after the block runs (e.g., artifacts="dist/*,plot.png").
+const AttrArtifacts = "artifacts"
+
+// unsafeDirCharsReg matches characters replaced in the names of artifact directories.
+var unsafeDirCharsReg = regexp.MustCompile(`[^-_.a-zA-Z0-9]+`)
+
+// ArtifactsDirName returns the name of the directory of the artifacts of a code block
+// (e.g., "block-1" or "block-2-build" for a block named build).
+func ArtifactsDirName(block parser.CodeBlock, index int) string {
+	name := fmt.Sprintf("block-%d", index+1)
+	if n := unsafeDirCharsReg.ReplaceAllString(block.Name(), "_"); n != "" {
+		name += "-" + n
+	}
+	return name
+}
+
+// collectArtifacts copies the files matching the artifacts attribute of a code block
+// into its directory under ArtifactsDir and returns the paths of the copies.
+// Relative patterns match from the working directory of the block (see WorkDir), and the paths relative to it are kept;
+// absolute paths are copied by their base names.
+func (r *Runner) collectArtifacts(block parser.CodeBlock, index int) ([]string, error) {
+	v := block.Attributes[AttrArtifacts]
+	if r.ArtifactsDir == "" || v == "" {
+		return nil, nil
+	}
+	dir := filepath.Join(r.ArtifactsDir, ArtifactsDirName(block, index))
+	wd := WorkDir(r.File, block)
+	var copied []string
+	for _, pattern := range strings.Split(v, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		base := ""
+		if !filepath.IsAbs(pattern) {
+			base = wd
+		}
+		matches, err := filepath.Glob(filepath.Join(base, pattern))
+		if err != nil {
+			return copied, fmt.Errorf("invalid %s pattern %q: %w", AttrArtifacts, pattern, err)
+		}
+		for _, m := range matches {
+			rel := m
+			if base != "" {
+				rel, _ = filepath.Rel(base, m) //nostyle:handlerrors
+			}
+			if filepath.IsAbs(rel) || strings.HasPrefix(filepath.Clean(rel), "..") {
+				rel = filepath.Base(m)
+			}
+			err := filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() || !d.Type().IsRegular() {
+					return err
+				}
+				dst := filepath.Join(dir, rel, strings.TrimPrefix(path, m))
+				if err := copyFile(path, dst); err != nil {
+					return err
+				}
+				copied = append(copied, dst)
+				return nil
+			})
+			if err != nil {
+				return copied, fmt.Errorf("failed to collect artifact %s: %w", m, err)
+			}
+		}
+	}
+	return copied, nil
+}
+
+// copyFile copies the regular file src to dst, creating the parent directories of dst.
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }() //nostyle:handlerrors
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRun_Artifacts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Chdir(t.TempDir())
+	artifacts := t.TempDir()
+
+	var got *Result
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		ArtifactsDir: artifacts,
+		OnResult:     func(result *Result) { got = result },
+	}
+	block := parser.CodeBlock{
+		Language:   "sh",
+		Command:    "sh -c 'mkdir -p dist/sub && echo a > dist/a.txt && echo b > dist/sub/b.txt && echo c > c.log'",
+		Attributes: map[string]string{parser.AttrName: "build all", AttrArtifacts: "dist, *.log, missing/*"},
+	}
+	if err := r.Run(context.Background(), block, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(artifacts, "block-2-build_all")
+	want := []string{
+		filepath.Join(dir, "dist", "a.txt"),
+		filepath.Join(dir, "dist", "sub", "b.txt"),
+		filepath.Join(dir, "c.log"),
+	}
+	if !slices.Equal(got.Artifacts, want) {
+		t.Errorf("Artifacts = %q, want %q", got.Artifacts, want)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "dist", "sub", "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "b\n" {
+		t.Errorf("got %q, want %q", b, "b\n")
+	}
+}
+
+func TestRun_ArtifactsOfFailedBlock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Chdir(t.TempDir())
+	artifacts := t.TempDir()
+
+	var got *Result
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		ArtifactsDir: artifacts,
+		OnResult:     func(result *Result) { got = result },
+	}
+	block := parser.CodeBlock{
+		Language:   "sh",
+		Command:    "sh -c 'echo oops > debug.log; exit 1'",
+		Attributes: map[string]string{AttrArtifacts: "debug.log"},
+	}
+	if err := r.Run(context.Background(), block, 0); err == nil {
+		t.Fatal("Run() should return error")
+	}
+	if want := []string{filepath.Join(artifacts, "block-1", "debug.log")}; !slices.Equal(got.Artifacts, want) {
+		t.Errorf("Artifacts = %q, want %q", got.Artifacts, want)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// Attributes asserting on the result of a code block.
+const (
+	AttrExpectStdout = "expect-stdout~"
+	AttrExpectStderr = "expect-stderr~"
+	AttrAssert       = "assert"
+)
+
+// hasAssertions reports whether the code block has attributes that need captured output.
+func hasAssertions(block parser.CodeBlock) bool {
+	for _, attr := range []string{AttrExpectStdout, AttrExpectStderr, AttrAssert} {
+		if _, ok := block.Attributes[attr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAssertions evaluates the assertion attributes of a code block against its result.
+// The output is normalized first (see AttrNormalize).
+func (r *Runner) checkAssertions(block parser.CodeBlock, store map[string]any, stdout, stderr string, exitCode int) error {
+	normalize, err := r.normalizer(block)
+	if err != nil {
+		return err
+	}
+	stdout, stderr = normalize(stdout), normalize(stderr)
+	for _, e := range []struct {
+		attr   string
+		stream string
+		output string
+	}{
+		{AttrExpectStdout, "stdout", stdout},
+		{AttrExpectStderr, "stderr", stderr},
+	} {
+		pattern, ok := block.Attributes[e.attr]
+		if !ok {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid %s= pattern: %w", e.attr, err)
+		}
+		if !re.MatchString(e.output) {
+			return fmt.Errorf("%s does not match /%s/", e.stream, pattern)
+		}
+	}
+
+	expr, ok := block.Attributes[AttrAssert]
+	if !ok {
+		return nil
+	}
+	// The expression may be written as {{ ... }} like command templates
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[2 : len(expr)-2])
+	}
+
+	s := maps.Clone(store)
+	s["stdout"] = stdout
+	s["stderr"] = stderr
+	s["exit_code"] = exitCode
+	ok, err = evalBool(expr, s, r.trace())
+	if err != nil {
+		return fmt.Errorf("failed to evaluate assert: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("assertion failed: %s (exit code %d)", expr, exitCode)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRun_ExpectOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		attrs   map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "stdout matches",
+			command: "echo hello world",
+			attrs:   map[string]string{AttrExpectStdout: "^hello w"},
+			wantErr: false,
+		},
+		{
+			name:    "stdout does not match",
+			command: "echo hello world",
+			attrs:   map[string]string{AttrExpectStdout: "^world"},
+			wantErr: true,
+		},
+		{
+			name:    "stderr matches",
+			command: "echo oops >&2",
+			attrs:   map[string]string{AttrExpectStderr: "oops"},
+			wantErr: false,
+		},
+		{
+			name:    "stderr does not match",
+			command: "echo oops >&2",
+			attrs:   map[string]string{AttrExpectStderr: "fine"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			command: "echo hello",
+			attrs:   map[string]string{AttrExpectStdout: "("},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			block := parser.CodeBlock{
+				Language:   "sh",
+				Command:    tt.command,
+				Attributes: tt.attrs,
+			}
+			err := r.Run(context.Background(), block, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stdout.Len()+stderr.Len() == 0 {
+				t.Error("output was not streamed")
+			}
+		})
+	}
+}
+
+func TestRun_Assert(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		assert  string
+		wantErr bool
+	}{
+		{
+			name:    "stdout contains and success",
+			command: "echo OK",
+			assert:  `stdout.contains("OK") && exit_code == 0`,
+			wantErr: false,
+		},
+		{
+			name:    "wrapped in braces",
+			command: "echo OK",
+			assert:  `{{ stdout.contains("OK") }}`,
+			wantErr: false,
+		},
+		{
+			name:    "expected failure",
+			command: "echo failed >&2; exit 3",
+			assert:  `exit_code == 3 && stderr.contains("failed")`,
+			wantErr: false,
+		},
+		{
+			name:    "assertion fails",
+			command: "echo NG",
+			assert:  `stdout.contains("OK")`,
+			wantErr: true,
+		},
+		{
+			name:    "not a bool",
+			command: "echo OK",
+			assert:  `stdout`,
+			wantErr: true,
+		},
+		{
+			name:    "uses store values",
+			command: "echo sh",
+			assert:  `stdout.startsWith(lang) && i == 0`,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			block := parser.CodeBlock{
+				Language:   "sh",
+				Command:    tt.command,
+				Attributes: map[string]string{AttrAssert: tt.assert},
+			}
+			err := r.Run(context.Background(), block, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
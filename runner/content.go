@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrRenderContent is the attribute expanding the {{ }} expressions in the content of a code block
+// before it is passed to the command (e.g., render-content=true), so that config templates can be filled
+// from vars and secrets at run time.
+const AttrRenderContent = "render-content"
+
+// renderContent returns the code block with its content expanded if it has render-content=true.
+// The expressions see the same values as commands, except {{content}} and {{chunk}} that are the content as written.
+func (r *Runner) renderContent(block parser.CodeBlock, index int) (parser.CodeBlock, error) {
+	v, ok := block.Attributes[AttrRenderContent]
+	if !ok || platformMismatch(block) != "" {
+		return block, nil
+	}
+	render, err := strconv.ParseBool(v)
+	if err != nil {
+		return block, fmt.Errorf("invalid %s: %w", AttrRenderContent, err)
+	}
+	if !render {
+		return block, nil
+	}
+	content, err := expandTemplate(block.Content, r.templateStore(block, index, block.Content, 0), r.trace())
+	if err != nil {
+		return block, fmt.Errorf("failed to render content: %w", err)
+	}
+	block.Content = content
+	return block, nil
+}
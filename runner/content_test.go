@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRun_RenderContent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	tests := []struct {
+		name    string
+		attrs   map[string]string
+		want    string
+		wantErr string
+	}{
+		{"rendered", map[string]string{AttrRenderContent: "true"}, "cluster: staging\nlang: yaml\ntoken: ***\n", ""},
+		{"as written", nil, "cluster: {{vars.cluster}}\nlang: {{lang}}\ntoken: {{secrets.token}}\n", ""},
+		{"false", map[string]string{AttrRenderContent: "false"}, "cluster: {{vars.cluster}}\nlang: {{lang}}\ntoken: {{secrets.token}}\n", ""},
+		{"invalid", map[string]string{AttrRenderContent: "yes please"}, "", "invalid render-content"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			r := New("", nil)
+			r.Stdout = &stdout
+			r.Vars = map[string]string{"cluster": "staging"}
+			r.Secrets = map[string]string{"token": "s3cret"}
+			block := parser.CodeBlock{
+				Language:   "yaml",
+				Command:    "cat",
+				Content:    "cluster: {{vars.cluster}}\nlang: {{lang}}\ntoken: {{secrets.token}}\n",
+				Attributes: tt.attrs,
+			}
+			err := r.Run(context.Background(), block, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got err %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_RenderContentError(t *testing.T) {
+	r := New("", nil)
+	block := parser.CodeBlock{Language: "yaml", Command: "cat", Content: "{{vars.missing}}\n", Attributes: map[string]string{AttrRenderContent: "true"}}
+	if err := r.Run(context.Background(), block, 0); err == nil || !strings.Contains(err.Error(), "failed to render content") {
+		t.Errorf("got err %v, want a render error", err)
+	}
+}
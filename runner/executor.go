@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// Executor executes code blocks in place of local processes (e.g., on cloud functions or via queues).
+// Executors are registered with RegisterExecutor and selected by the use attribute or Plugins like executor plugins.
+type Executor interface {
+	// Execute executes a code block. The block fails if it returns an error; if the error has
+	// an ExitCode() int method (like *exec.ExitError), it is reported as the exit code.
+	Execute(ctx context.Context, e *Execution) error
+}
+
+// ExecutorFunc is an adapter to use an ordinary function as an Executor.
+type ExecutorFunc func(ctx context.Context, e *Execution) error
+
+// Execute calls f(ctx, e).
+func (f ExecutorFunc) Execute(ctx context.Context, e *Execution) error {
+	return f(ctx, e)
+}
+
+// Execution is an execution of a code block by an Executor.
+type Execution struct {
+	Block  parser.CodeBlock
+	Index  int
+	Input  string   // Content of the block (the chunk for split blocks)
+	Env    []string // Environment variables set for the block (CODEBLOCK_*, env.NAME attributes)
+	TmpDir string   // Temporary directory of the run
+	Dir    string   // Working directory of the block (empty for that of runblock)
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+var (
+	executorsMu sync.RWMutex
+	executors   = map[string]Executor{}
+)
+
+// RegisterExecutor makes an Executor available by the name.
+// A registered executor takes precedence over the executor plugin of the same name.
+// It panics if e is nil or an executor is already registered with the name.
+func RegisterExecutor(name string, e Executor) {
+	executorsMu.Lock()
+	defer executorsMu.Unlock()
+	if e == nil {
+		panic("runner: RegisterExecutor executor is nil")
+	}
+	if _, dup := executors[name]; dup {
+		panic("runner: RegisterExecutor called twice for executor " + name)
+	}
+	executors[name] = e
+}
+
+// lookupExecutor returns the executor registered with the name.
+func lookupExecutor(name string) (Executor, bool) {
+	executorsMu.RLock()
+	defer executorsMu.RUnlock()
+	e, ok := executors[name]
+	return e, ok
+}
+
+// useResolution returns the source and the command template for the executor selected by name:
+// the registered executor, or the executor plugin otherwise.
+func useResolution(name string) (string, string) {
+	if _, ok := lookupExecutor(name); ok {
+		return SourceExecutor, name
+	}
+	return SourcePlugin, PluginPrefix + name
+}
+
+// executeWith executes a chunk of a code block with the registered executor of the resolution
+// and returns its exit code (-1 if it is unknown).
+func (r *Runner) executeWith(ctx context.Context, block parser.CodeBlock, index int, res *Resolution, input string, outW, errW io.Writer) (int, error) {
+	e, ok := lookupExecutor(res.Command)
+	if !ok {
+		return -1, fmt.Errorf("executor %q is not registered", res.Command)
+	}
+	err := e.Execute(ctx, &Execution{
+		Block:  block,
+		Index:  index,
+		Input:  input,
+		Env:    res.Env,
+		TmpDir: r.TmpDir,
+		Dir:    res.Dir,
+		Stdout: outW,
+		Stderr: errW,
+	})
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), err
+	}
+	return -1, err
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// testExitError is an error with an exit code.
+type testExitError int
+
+func (e testExitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func (e testExitError) ExitCode() int { return int(e) }
+
+func TestRun_Executor(t *testing.T) {
+	RegisterExecutor("test-upper", ExecutorFunc(func(ctx context.Context, e *Execution) error {
+		if !slices.Contains(e.Env, "FOO=bar") {
+			return errors.New("env.FOO is not set")
+		}
+		if strings.HasPrefix(e.Input, "fail") {
+			return testExitError(3)
+		}
+		_, err := fmt.Fprintf(e.Stdout, "%d:%s", e.Index, strings.ToUpper(e.Input))
+		return err
+	}))
+
+	tests := []struct {
+		name     string
+		block    parser.CodeBlock
+		want     string
+		wantCode int
+		wantErr  bool
+	}{
+		{"use attribute", parser.CodeBlock{Language: "txt", Content: "hello\n", Attributes: map[string]string{"use": "test-upper", "env.FOO": "bar"}}, "1:HELLO\n", 0, false},
+		{"language executor", parser.CodeBlock{Language: "upper", Content: "hi\n", Attributes: map[string]string{"env.FOO": "bar"}}, "1:HI\n", 0, false},
+		{"exit code", parser.CodeBlock{Language: "upper", Content: "fail\n", Attributes: map[string]string{"env.FOO": "bar"}}, "", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr, Plugins: map[string]string{"upper": "test-upper"}}
+			var result *Result
+			r.OnResult = func(res *Result) { result = res }
+			err := r.Run(context.Background(), tt.block, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+			if result.ExitCode != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", result.ExitCode, tt.wantCode)
+			}
+		})
+	}
+
+	res, err := (&Runner{}).Resolve(parser.CodeBlock{Attributes: map[string]string{"use": "test-upper"}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Source != SourceExecutor || res.Command != "test-upper" {
+		t.Errorf("resolution = %s %q, want %s %q", res.Source, res.Command, SourceExecutor, "test-upper")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// celFunctions returns the helper functions available in templates and expressions:
+//
+//	uuid()                    - random UUID (version 4), e.g., for unique resource names
+//	now()                     - current time
+//	format_time(t)            - t formatted in RFC 3339
+//	format_time(t, layout)    - t formatted with the Go time layout (e.g., "20060102-150405")
+//	slug(s)                   - s as a lowercase slug for file names (e.g., "Set up the DB" -> "set-up-the-db")
+//	shquote(s)                - s quoted for POSIX shells (e.g., "it's" -> 'it'\''s')
+//	psquote(s)                - s quoted for PowerShell (e.g., "it's" -> 'it''s')
+//	jsonquote(s)              - s as a JSON string literal, quotes included (e.g., for JSON payloads)
+func celFunctions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("uuid",
+			cel.Overload("uuid", nil, cel.StringType,
+				cel.FunctionBinding(func(...ref.Val) ref.Val {
+					return types.String(newUUID())
+				}),
+			),
+		),
+		cel.Function("now",
+			cel.Overload("now", nil, cel.TimestampType,
+				cel.FunctionBinding(func(...ref.Val) ref.Val {
+					return types.Timestamp{Time: time.Now()}
+				}),
+			),
+		),
+		cel.Function("format_time",
+			cel.Overload("format_time_timestamp", []*cel.Type{cel.TimestampType}, cel.StringType,
+				cel.UnaryBinding(func(t ref.Val) ref.Val {
+					return types.String(t.(types.Timestamp).Format(time.RFC3339))
+				}),
+			),
+			cel.Overload("format_time_timestamp_string", []*cel.Type{cel.TimestampType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(func(t, layout ref.Val) ref.Val {
+					return types.String(t.(types.Timestamp).Format(string(layout.(types.String))))
+				}),
+			),
+		),
+		cel.Function("slug",
+			cel.Overload("slug_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(func(s ref.Val) ref.Val {
+					return types.String(Slug(string(s.(types.String))))
+				}),
+			),
+		),
+		cel.Function("shquote",
+			cel.Overload("shquote_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(func(s ref.Val) ref.Val {
+					return types.String(ShellQuote(string(s.(types.String))))
+				}),
+			),
+		),
+		cel.Function("psquote",
+			cel.Overload("psquote_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(func(s ref.Val) ref.Val {
+					return types.String(powerShellQuote(string(s.(types.String))))
+				}),
+			),
+		),
+		cel.Function("jsonquote",
+			cel.Overload("jsonquote_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(func(s ref.Val) ref.Val {
+					return types.String(jsonQuote(string(s.(types.String))))
+				}),
+			),
+		),
+	}
+}
+
+// Slug converts s into a lowercase slug safe for file names.
+// Letters and digits are kept, runs of other characters become a single hyphen
+// and Markdown emphasis and code marks are dropped (e.g., "Set up `db`!" -> "set-up-db").
+func Slug(s string) string {
+	var b strings.Builder
+	sep := false
+	for _, c := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			if sep && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			sep = false
+			b.WriteRune(c)
+		case c == '`' || c == '*' || c == '\'':
+		default:
+			sep = true
+		}
+	}
+	return b.String()
+}
+
+// ShellQuote quotes s with single quotes for POSIX shells.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powerShellQuotes are the characters PowerShell treats as single quotes.
+var powerShellQuotes = strings.NewReplacer("'", "''", "\u2018", "\u2018\u2018", "\u2019", "\u2019\u2019", "\u201a", "\u201a\u201a", "\u201b", "\u201b\u201b")
+
+// powerShellQuote quotes s with single quotes for PowerShell.
+// Typographic single quotes are doubled too, since PowerShell treats them as quotes.
+func powerShellQuote(s string) string {
+	return "'" + powerShellQuotes.Replace(s) + "'"
+}
+
+// jsonQuote returns s as a JSON string without escaping HTML characters.
+func jsonQuote(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) //nostyle:handlerrors
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// newUUID returns a random UUID (version 4).
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])  //nostyle:handlerrors
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // Variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// templateString converts a value produced by a template expression to a string.
+// Timestamps are formatted in RFC 3339.
+func templateString(v any) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", v)
+}
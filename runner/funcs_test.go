@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"os/exec"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTemplateFunctions(t *testing.T) {
+	uuidReg := regexp.MustCompile(`^demo-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	tests := []struct {
+		name     string
+		template string
+		check    func(string) bool
+	}{
+		{"uuid", "demo-{{ uuid() }}", uuidReg.MatchString},
+		{"uuid is random", "{{ uuid() != uuid() }}", func(s string) bool { return s == "true" }},
+		{"now", "{{ now() }}", func(s string) bool {
+			_, err := time.Parse(time.RFC3339, s)
+			return err == nil
+		}},
+		{"format_time", "{{ format_time(timestamp('2026-01-02T03:04:05Z')) }}", func(s string) bool {
+			return s == "2026-01-02T03:04:05Z"
+		}},
+		{"format_time with layout", "{{ format_time(timestamp('2026-01-02T03:04:05Z'), '20060102-150405') }}", func(s string) bool {
+			return s == "20260102-030405"
+		}},
+		{"format_time with now", "{{ format_time(now(), '2006') }}", func(s string) bool {
+			return s == time.Now().Format("2006")
+		}},
+		{"slug", "{{ slug('Set up the `db` (v2)!') }}.md", func(s string) bool {
+			return s == "set-up-the-db-v2.md"
+		}},
+		{"shquote", `{{ shquote("it's $HOME") }}`, func(s string) bool {
+			return s == `'it'\''s $HOME'`
+		}},
+		{"psquote", `{{ psquote("it's $env:HOME ‘x’") }}`, func(s string) bool {
+			return s == `'it''s $env:HOME ‘‘x’’'`
+		}},
+		{"jsonquote", `{{ jsonquote("say \"<hi>\"\n") }}`, func(s string) bool {
+			return s == `"say \"<hi>\"\n"`
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandTemplate(tt.template, map[string]any{})
+			if err != nil {
+				t.Fatalf("ExpandTemplate() error = %v", err)
+			}
+			if !tt.check(got) {
+				t.Errorf("ExpandTemplate() = %q", got)
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Getting Started", "getting-started"},
+		{"  Install **runblock** via `go install`  ", "install-runblock-via-go-install"},
+		{"Don't panic", "dont-panic"},
+		{"snake_case & kebab-case", "snake-case-kebab-case"},
+		{"Café 日本語", "café-日本語"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.in); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	for _, s := range []string{"", "plain", "it's", `a "b" $c \d`, "multi\nline\n", "'''"} {
+		out, err := exec.Command("sh", "-c", "printf '%s' "+ShellQuote(s)).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != s {
+			t.Errorf("ShellQuote(%q) evaluated to %q", s, out)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// BlockID returns a stable short ID of a code block in the document file.
+// It is a hash of the (cleaned) path of the file, the headings enclosing the block and its content,
+// so it does not change when the block moves around within its section or other blocks are added.
+func BlockID(file string, block parser.CodeBlock) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, filepath.ToSlash(filepath.Clean(file))) //nostyle:handlerrors
+	for _, heading := range block.HeadingPath {
+		_, _ = io.WriteString(h, "\x00"+heading) //nostyle:handlerrors
+	}
+	_, _ = io.WriteString(h, "\x00\x00"+block.Content) //nostyle:handlerrors
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
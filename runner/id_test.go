@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestBlockID(t *testing.T) {
+	block := parser.CodeBlock{Content: "make test\n", HeadingPath: []string{"Setup", "Test"}, Line: 10}
+	id := BlockID("docs/runbook.md", block)
+	if len(id) != 12 {
+		t.Errorf("BlockID() = %q, want 12 hex digits", id)
+	}
+
+	moved := block
+	moved.Line = 42
+	if got := BlockID("./docs/runbook.md", moved); got != id {
+		t.Errorf("BlockID() of the moved block = %q, want %q", got, id)
+	}
+
+	for name, other := range map[string]string{
+		"file":    BlockID("docs/other.md", block),
+		"content": BlockID("docs/runbook.md", parser.CodeBlock{Content: "make lint\n", HeadingPath: block.HeadingPath}),
+		"heading": BlockID("docs/runbook.md", parser.CodeBlock{Content: block.Content, HeadingPath: []string{"Setup"}}),
+	} {
+		if other == id {
+			t.Errorf("BlockID() with another %s should differ", name)
+		}
+	}
+}
+
+func TestRun_BlockID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	var stdout bytes.Buffer
+	r := &Runner{Stdout: &stdout, File: "runbook.md"}
+	block := parser.CodeBlock{Language: "sh", Command: `sh -c 'echo {{id}} $CODEBLOCK_ID'`, Content: "echo\n"}
+	if err := r.Run(t.Context(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	id := BlockID("runbook.md", block)
+	if got, want := stdout.String(), id+" "+id+"\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// ErrContentInterpolation is returned when a command interpolates the content of a block into the shell unquoted.
+var ErrContentInterpolation = errors.New("content is interpolated into the shell command unquoted")
+
+// rawVariables are the variables holding the content of a block.
+var rawVariables = []string{"content", "chunk"}
+
+// rawFields are the fields of variables holding the output of a block.
+var rawFields = map[string][]string{"prev": {"stdout", "stderr"}}
+
+// safeFunctions returns the functions whose results can be interpolated into commands run by the shell sh.
+// jsonquote is never safe: its result is double-quoted, and $(...) and backticks are expanded in double quotes.
+func safeFunctions(sh string) []string {
+	switch strings.TrimSuffix(strings.ToLower(filepath.Base(sh)), ".exe") {
+	case "pwsh", "powershell":
+		return []string{"psquote", "slug"}
+	case "cmd":
+		return []string{"slug"}
+	default:
+		return []string{"shquote", "slug"}
+	}
+}
+
+// checkInterpolation returns ErrContentInterpolation if an expression of the command template
+// produces a string with the content of the block that is not quoted for the shell sh (e.g., "cat {{content}}").
+// Expressions producing other types (e.g., {{ size(content) }}) are fine.
+// Inside quotes of the template, the quoting functions do not protect the content (e.g., "sh -c 'echo {{ shquote(content) }}'").
+func checkInterpolation(template string, store map[string]any, sh string) error {
+	env, err := createCELEnv(store)
+	if err != nil {
+		return fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	escape := byte('\\')
+	if slices.Contains(safeFunctions(sh), "psquote") {
+		escape = '`'
+	}
+	var literal strings.Builder
+	pos := 0
+	for _, m := range celExprReg.FindAllStringSubmatchIndex(template, -1) {
+		literal.WriteString(template[pos:m[0]])
+		pos = m[1]
+		expr := strings.TrimSpace(template[m[2]:m[3]])
+		ast, issues := env.Compile(expr)
+		if issues != nil && issues.Err() != nil {
+			// Reported on expansion
+			continue
+		}
+		safe := safeFunctions(sh)
+		if rawInterpolation(ast, safe) {
+			return fmt.Errorf("%w in '{{%s}}': pass the content via stdin or a file under {{tmpdir}}, or quote it (e.g., {{ %s(%s) }})",
+				ErrContentInterpolation, expr, safe[0], expr)
+		}
+		if inQuotes(literal.String(), escape) && rawInterpolation(ast, []string{"slug"}) {
+			return fmt.Errorf("%w in '{{%s}}': the quoted content must not be placed inside quotes of the command",
+				ErrContentInterpolation, expr)
+		}
+	}
+	return nil
+}
+
+// inQuotes reports whether the end of the command line s is inside single or double quotes.
+// escape is the character escaping the next one outside single quotes (\ for POSIX shells, ` for PowerShell).
+func inQuotes(s string, escape byte) bool {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == escape && quote != '\'':
+			i++
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case c == quote:
+			quote = 0
+		}
+	}
+	return quote != 0
+}
+
+// rawInterpolation reports whether a content variable (or an output field) reaches the result of the expression
+// only through strings (or lists and maps holding them), without passing one of the safe functions.
+// Output fields are dynamically typed.
+func rawInterpolation(ast *cel.Ast, safe []string) bool {
+	native := ast.NativeRep()
+	var sources []celast.NavigableExpr
+	for _, e := range celast.MatchDescendants(celast.NavigateAST(native), func(e celast.NavigableExpr) bool {
+		switch e.Kind() {
+		case celast.IdentKind:
+			return slices.Contains(rawVariables, e.AsIdent())
+		case celast.SelectKind:
+			sel := e.AsSelect()
+			op := sel.Operand()
+			return op.Kind() == celast.IdentKind && slices.Contains(rawFields[op.AsIdent()], sel.FieldName())
+		default:
+			return false
+		}
+	}) {
+		sources = append(sources, e)
+	}
+	for _, source := range sources {
+		raw := true
+		for e := source; ; {
+			if !holdsString(native.GetType(e.ID())) {
+				raw = false
+				break
+			}
+			if e.Kind() == celast.CallKind && slices.Contains(safe, e.AsCall().FunctionName()) {
+				raw = false
+				break
+			}
+			parent, ok := e.Parent()
+			if !ok {
+				break
+			}
+			e = parent
+		}
+		if raw {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsString reports whether a value of type t may be or contain a string,
+// so that the content can be taken out of it again (e.g., {{ [content][0] }}).
+func holdsString(t *cel.Type) bool {
+	switch t.Kind() {
+	case types.StringKind, types.DynKind, types.AnyKind:
+		return true
+	case types.ListKind, types.MapKind:
+		return slices.ContainsFunc(t.Parameters(), holdsString)
+	default:
+		return false
+	}
+}
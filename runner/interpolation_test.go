@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestCheckInterpolation(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{"sh", false},
+		{"cat {{content}}", true},
+		{"echo '{{ chunk }}'", true},
+		{`echo {{ "-- " + content }}`, true},
+		{`echo {{ lang == "sh" ? content : "" }}`, true},
+		{"echo {{ string(content) }}", true},
+		{"echo {{ shquote(content) }}", false},
+		{`echo {{ shquote("-- " + content) }}`, false},
+		{`pwsh -c {{ psquote(content) }}`, true},
+		{"echo {{ jsonquote(content) }}", true},
+		{"echo {{ shquote(jsonquote(content)) }}", false},
+		{"echo {{ size(content) }}", false},
+		{`echo {{ content.contains("x") }}`, false},
+		{`echo {{ content == "" ? "empty" : "filled" }}`, false},
+		{"touch {{ slug(content) }}", false},
+		{"echo {{lang}} {{i}}", false},
+		{"echo {{ prev.stdout }}", true},
+		{"echo {{ prev.stderr + 'x' }}", true},
+		{"echo {{ shquote(prev.stdout) }}", false},
+		{`{{ prev.exit_code == 0 ? "echo continue" : "" }}`, false},
+		{"echo {{ unknown }}", false}, // Reported on expansion
+		{`sh -c "echo {{ shquote(content) }}"`, true},
+		{"sh -c 'echo {{ shquote(content) }}'", true},
+		{`echo "{{lang}}" '{{i}}' {{ shquote(content) }}`, false},
+		{`echo \"{{ shquote(content) }}`, false},
+		{`echo "{{ slug(content) }}"`, false},
+		{`echo "{{ size(content) }}"`, false},
+		{"echo {{ [content][0] }}", true},
+		{"echo {{ [content] }}", true},
+		{"echo {{ [[content]][0][0] }}", true},
+		{"echo {{ [shquote(content)][0] }}", false},
+		{"echo {{ size([content]) }}", false},
+	}
+	store := (&Runner{}).templateStore(parser.CodeBlock{Language: "sh", Content: "x"}, 0, "x", 0)
+	for _, tt := range tests {
+		err := checkInterpolation(tt.template, store, "/bin/sh")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkInterpolation(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrContentInterpolation) {
+			t.Errorf("checkInterpolation(%q) error = %v, want ErrContentInterpolation", tt.template, err)
+		}
+	}
+}
+
+func TestCheckInterpolation_Shells(t *testing.T) {
+	tests := []struct {
+		sh       string
+		template string
+		wantErr  bool
+	}{
+		{"/bin/bash", "echo {{ shquote(content) }}", false},
+		{"/bin/bash", "echo {{ psquote(content) }}", true},
+		{"pwsh", "echo {{ psquote(content) }}", false},
+		{"pwsh", "echo {{ shquote(content) }}", true},
+		{"powershell.exe", "echo {{ psquote(content) }}", false},
+		{"cmd", "echo {{ psquote(content) }}", true},
+		{"cmd", "echo {{ slug(content) }}", false},
+		{"pwsh", "echo {{ jsonquote(content) }}", true},
+		{"pwsh", `pwsh -c "echo {{ psquote(content) }}"`, true},
+		{"pwsh", "echo `\"{{ psquote(content) }}", false},
+	}
+	store := (&Runner{}).templateStore(parser.CodeBlock{Language: "sh", Content: "x"}, 0, "x", 0)
+	for _, tt := range tests {
+		if err := checkInterpolation(tt.template, store, tt.sh); (err != nil) != tt.wantErr {
+			t.Errorf("checkInterpolation(%q) with %s error = %v, wantErr %v", tt.template, tt.sh, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRawInterpolation_Map(t *testing.T) {
+	// Map literals cannot be written between {{ }}, but the expressions are checked the same way
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`{"a": content}.a`, true},
+		{`{"a": content}["a"]`, true},
+		{`{content: 1}`, true},
+		{`{"a": shquote(content)}.a`, false},
+		{`size({"a": content})`, false},
+	}
+	store := (&Runner{}).templateStore(parser.CodeBlock{Language: "sh", Content: "x"}, 0, "x", 0)
+	env, err := createCELEnv(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		ast, issues := env.Compile(tt.expr)
+		if issues != nil && issues.Err() != nil {
+			t.Fatalf("failed to compile %q: %v", tt.expr, issues.Err())
+		}
+		if got := rawInterpolation(ast, safeFunctions("/bin/sh")); got != tt.want {
+			t.Errorf("rawInterpolation(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestResolve_ContentInterpolation(t *testing.T) {
+	block := parser.CodeBlock{Language: "sh", Command: "echo {{content}}", Content: "hi; rm -rf /\n"}
+	if _, err := (&Runner{}).Resolve(block, 0); !errors.Is(err, ErrContentInterpolation) {
+		t.Errorf("Resolve() error = %v, want ErrContentInterpolation", err)
+	}
+	res, err := (&Runner{RawContent: true}).Resolve(block, 0)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if want := "echo hi; rm -rf /"; res.Command != want {
+		t.Errorf("Command = %q, want %q", res.Command, want)
+	}
+}
+
+func TestResolve_JSONQuoteInterpolation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+	// $(...) is expanded in the double quotes of jsonquote
+	block := parser.CodeBlock{Language: "sh", Command: "echo {{ jsonquote(content) }}", Content: "$(echo INJECTED)"}
+	if _, err := (&Runner{}).Resolve(block, 0); !errors.Is(err, ErrContentInterpolation) {
+		t.Errorf("Resolve() error = %v, want ErrContentInterpolation", err)
+	}
+}
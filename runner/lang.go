@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// Aliases maps language identifiers to the groups of identifiers equivalent to them.
+type Aliases map[string][]string
+
+// ParseAliases parses groups of equivalent language identifiers such as "js=javascript" or "shell=sh=bash".
+// Groups sharing an identifier are merged.
+func ParseAliases(specs []string) (Aliases, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	a := Aliases{}
+	for _, spec := range specs {
+		langs := strings.Split(spec, "=")
+		if len(langs) < 2 || slices.Contains(langs, "") {
+			return nil, fmt.Errorf("invalid alias format %q: expected 'lang=alias[=alias...]'", spec)
+		}
+		var group []string
+		for _, lang := range langs {
+			for _, l := range append([]string{lang}, a[lang]...) {
+				if !slices.Contains(group, l) {
+					group = append(group, l)
+				}
+			}
+		}
+		for _, lang := range group {
+			a[lang] = group
+		}
+	}
+	return a, nil
+}
+
+// Languages returns lang followed by the identifiers equivalent to it.
+func (a Aliases) Languages(lang string) []string {
+	langs := []string{lang}
+	for _, l := range a[lang] {
+		if l != lang {
+			langs = append(langs, l)
+		}
+	}
+	return langs
+}
+
+// Match reports whether lang is one of langs or equivalent to one of them.
+func (a Aliases) Match(lang string, langs []string) bool {
+	for _, l := range a.Languages(lang) {
+		if slices.Contains(langs, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchBlock reports whether any of the languages of a code block is one of langs or equivalent to one of them.
+func (a Aliases) MatchBlock(block parser.CodeBlock, langs []string) bool {
+	for _, lang := range block.Languages() {
+		if a.Match(lang, langs) {
+			return true
+		}
+	}
+	return false
+}
+
+// languageCommand returns the command for the languages of a code block from Commands,
+// looking up each of its languages and then their aliases in order.
+func (r *Runner) languageCommand(block parser.CodeBlock) string {
+	for _, lang := range block.Languages() {
+		for _, l := range r.Aliases.Languages(lang) {
+			if c := r.Commands[l]; c != "" {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+// defaultApplies reports whether DefaultCommand applies to a code block.
+func (r *Runner) defaultApplies(block parser.CodeBlock) bool {
+	if len(r.DefaultLangs) == 0 {
+		return true
+	}
+	return r.Aliases.MatchBlock(block, r.DefaultLangs)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestParseAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		lang    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "pair", specs: []string{"js=javascript"}, lang: "javascript", want: []string{"javascript", "js"}},
+		{name: "group", specs: []string{"shell=sh=bash"}, lang: "sh", want: []string{"sh", "shell", "bash"}},
+		{name: "merged groups", specs: []string{"sh=bash", "shell=sh"}, lang: "bash", want: []string{"bash", "shell", "sh"}},
+		{name: "unknown", specs: []string{"yml=yaml"}, lang: "go", want: []string{"go"}},
+		{name: "no alias", specs: []string{"sh"}, wantErr: true},
+		{name: "empty", specs: []string{"sh="}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseAliases(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAliases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := a.Languages(tt.lang); !slices.Equal(got, tt.want) {
+				t.Errorf("Languages(%q) = %v, want %v", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_Aliases(t *testing.T) {
+	aliases, err := ParseAliases([]string{"js=javascript", "yml=yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New("", map[string]string{"javascript": "node", "yml": "yamllint -"})
+	r.Aliases = aliases
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"js", "node"},
+		{"javascript", "node"},
+		{"yaml", "yamllint -"},
+	}
+	for _, tt := range tests {
+		res, err := r.Resolve(parser.CodeBlock{Language: tt.lang}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Command != tt.want {
+			t.Errorf("command for %q = %q, want %q", tt.lang, res.Command, tt.want)
+		}
+	}
+}
+
+func TestResolve_MultipleLanguages(t *testing.T) {
+	aliases, err := ParseAliases([]string{"shell=sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New("", map[string]string{"jsonc": "jq .", "shell": "sh"})
+	r.Aliases = aliases
+
+	tests := []struct {
+		block parser.CodeBlock
+		want  string
+	}{
+		{parser.CodeBlock{Language: "json", AltLanguages: []string{"jsonc"}}, "jq ."},
+		{parser.CodeBlock{Language: "bash", AltLanguages: []string{"sh"}}, "sh"},
+		{parser.CodeBlock{Language: "json"}, ""},
+	}
+	for _, tt := range tests {
+		res, err := r.Resolve(tt.block, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Command != tt.want {
+			t.Errorf("command for %v = %q, want %q", tt.block.Languages(), res.Command, tt.want)
+		}
+	}
+}
+
+func TestResolve_DefaultLangs(t *testing.T) {
+	aliases, err := ParseAliases([]string{"shell=sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New("bash", map[string]string{"yaml": "yamllint -"})
+	r.Aliases = aliases
+	r.DefaultLangs = []string{"sh", "bash"}
+
+	tests := []struct {
+		block    parser.CodeBlock
+		want     string
+		wantSkip bool
+	}{
+		{block: parser.CodeBlock{Language: "bash"}, want: "bash"},
+		{block: parser.CodeBlock{Language: "shell"}, want: "bash"},
+		{block: parser.CodeBlock{Language: "yaml"}, want: "yamllint -"},
+		{block: parser.CodeBlock{Language: "yml"}, wantSkip: true},
+		{block: parser.CodeBlock{Language: "yml", Command: "cat"}, want: "cat"},
+	}
+	for _, tt := range tests {
+		res, err := r.Resolve(tt.block, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Skip != tt.wantSkip || res.Command != tt.want {
+			t.Errorf("%q: command = %q, skip = %v, want %q, %v", tt.block.Language, res.Command, res.Skip, tt.want, tt.wantSkip)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// loggerKey is the context key of the logger.
+type loggerKey struct{}
+
+// discardLogger is the logger used when none is given.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// WithLogger returns a copy of ctx carrying the logger. Runners log debug events of the blocks
+// run with the context (resolution, expansion, exec and exit) to it, in preference to Logger.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// logger returns the logger of ctx, Logger or a logger discarding the events.
+// The values of secrets are masked in the events.
+func (r *Runner) logger(ctx context.Context) *slog.Logger {
+	l, ok := ctx.Value(loggerKey{}).(*slog.Logger)
+	if !ok || l == nil {
+		l = r.Logger
+	}
+	if l == nil {
+		return discardLogger
+	}
+	if len(r.secretValues()) == 0 {
+		return l
+	}
+	return slog.New(&maskHandler{Handler: l.Handler(), mask: r.Mask})
+}
+
+// maskHandler masks the values of secrets in the messages and the attributes of the records.
+type maskHandler struct {
+	slog.Handler
+	mask func(string) string
+}
+
+func (h *maskHandler) Handle(ctx context.Context, rec slog.Record) error {
+	masked := slog.NewRecord(rec.Time, rec.Level, h.mask(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(h.maskAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, masked)
+}
+
+func (h *maskHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		masked = append(masked, h.maskAttr(a))
+	}
+	return &maskHandler{Handler: h.Handler.WithAttrs(masked), mask: h.mask}
+}
+
+func (h *maskHandler) WithGroup(name string) slog.Handler {
+	return &maskHandler{Handler: h.Handler.WithGroup(name), mask: h.mask}
+}
+
+// maskAttr returns a with the values of secrets masked. Values other than strings and groups
+// (e.g., errors) are masked in their string form.
+func (h *maskHandler) maskAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.mask(v.String()))
+	case slog.KindGroup:
+		attrs := make([]any, 0, len(v.Group()))
+		for _, ga := range v.Group() {
+			attrs = append(attrs, h.maskAttr(ga))
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		if v.Any() == nil {
+			return a
+		}
+		return slog.String(a.Key, h.mask(fmt.Sprint(v.Any())))
+	default:
+		return a
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRun_Logger(t *testing.T) {
+	var logs, ctxLogs bytes.Buffer
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+	block := parser.CodeBlock{Language: "sh", Command: "exit {{i}}", Line: 7}
+
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, want := range []string{
+		`msg="command resolved" index=0 lang=sh line=7 source="info string" template="exit {{i}}"`,
+		`msg="command expanded" index=0 lang=sh line=7 template="exit {{i}}" command="exit 0"`,
+		`msg=exec index=0 lang=sh line=7 source="info string" command="exit 0" chunk=0`,
+		`msg=exit index=0 lang=sh line=7 exit_code=0`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs do not contain %q:\n%s", want, logs.String())
+		}
+	}
+
+	// The logger of the context takes precedence
+	logs.Reset()
+	ctx := WithLogger(context.Background(), slog.New(slog.NewTextHandler(&ctxLogs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if err := r.Run(ctx, block, 1); err == nil {
+		t.Fatal("Run() should return error")
+	}
+	if logs.Len() > 0 {
+		t.Errorf("Logger should not be used: %s", logs.String())
+	}
+	if want := "exit_code=1"; !strings.Contains(ctxLogs.String(), want) {
+		t.Errorf("logs do not contain %q:\n%s", want, ctxLogs.String())
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// RunFunc executes a code block and returns its result (never nil).
+type RunFunc func(ctx context.Context, block parser.CodeBlock, index int) *Result
+
+// Middleware wraps the execution of code blocks to compose cross-cutting concerns such as
+// retries, metrics and policies. It can call next any number of times or not at all.
+type Middleware func(next RunFunc) RunFunc
+
+// Use adds middleware wrapping the execution of every code block.
+// The middleware added first is the outermost. OnResult is called once with the result
+// returned by the outermost middleware.
+func (r *Runner) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// chain wraps fn in the middleware of the Runner.
+func (r *Runner) chain(fn RunFunc) RunFunc {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		fn = r.middleware[i](fn)
+	}
+	return fn
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRunner_Use(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(ctx context.Context, block parser.CodeBlock, index int) *Result {
+				calls = append(calls, name+" before")
+				result := next(ctx, block, index)
+				calls = append(calls, name+" after")
+				return result
+			}
+		}
+	}
+	// retry runs a failed block once more
+	retry := func(next RunFunc) RunFunc {
+		return func(ctx context.Context, block parser.CodeBlock, index int) *Result {
+			result := next(ctx, block, index)
+			if result.Err != nil {
+				block.Command = "exit 0"
+				result = next(ctx, block, index)
+			}
+			return result
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr}
+	r.Use(trace("outer"), trace("inner"))
+	r.Use(retry)
+	results := 0
+	r.OnResult = func(*Result) { results++ }
+
+	if err := r.Run(context.Background(), parser.CodeBlock{Language: "sh", Command: "exit 1"}, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"outer before", "inner before", "inner after", "outer after"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if results != 1 {
+		t.Errorf("OnResult called %d times, want 1", results)
+	}
+}
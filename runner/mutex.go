@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrMutex is the attribute naming locks a code block holds while it runs, separated by commas
+// (e.g., mutex=staging-db). Blocks sharing a name never run concurrently, even in parallel groups.
+const AttrMutex = "mutex"
+
+// mutexes is the named locks of the blocks of a run.
+type mutexes struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock acquires the locks named by the mutex attribute of a code block and returns a function releasing them.
+// The locks are acquired in the order of their names, so blocks holding several of them do not deadlock.
+func (m *mutexes) lock(block parser.CodeBlock) func() {
+	var names []string
+	for name := range strings.SplitSeq(block.Attributes[AttrMutex], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	held := make([]*sync.Mutex, 0, len(names))
+	for _, name := range names {
+		m.mu.Lock()
+		if m.locks == nil {
+			m.locks = map[string]*sync.Mutex{}
+		}
+		l, ok := m.locks[name]
+		if !ok {
+			l = &sync.Mutex{}
+			m.locks[name] = l
+		}
+		m.mu.Unlock()
+		l.Lock()
+		held = append(held, l)
+	}
+	return func() {
+		for _, l := range slices.Backward(held) {
+			l.Unlock()
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRunAll_Mutex(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	// Each block fails if another block using the same resource is running
+	tests := []struct {
+		name      string
+		resources []string
+		mutex     bool
+		wantErr   bool
+	}{
+		{"same name", []string{"db", "db", "db"}, true, false},
+		{"several names", []string{"db,cache", "cache", "db"}, true, false},
+		{"without mutex", []string{"db", "db", "db"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blocks []parser.CodeBlock
+			for _, res := range tt.resources {
+				attrs := map[string]string{"env.RESOURCES": res}
+				if tt.mutex {
+					attrs[AttrMutex] = res
+				}
+				blocks = append(blocks, parser.CodeBlock{
+					Language:   "sh",
+					Command:    "sh -c 'for d in $(echo $RESOURCES | tr , \" \"); do mkdir {{tmpdir}}/$d || exit 1; done; sleep 0.2; for d in $(echo $RESOURCES | tr , \" \"); do rmdir {{tmpdir}}/$d; done'",
+					Attributes: attrs,
+				})
+			}
+			r := New("", nil)
+			r.Stdout, r.Stderr = io.Discard, io.Discard
+			r.Parallel = map[string]int{"sh": 3}
+			r.KeepGoing = true
+			r.TmpDir = t.TempDir()
+			if err := r.RunAll(context.Background(), blocks); (err != nil) != tt.wantErr {
+				t.Errorf("got err %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMutexes_Unlock(t *testing.T) {
+	m := &mutexes{}
+	block := parser.CodeBlock{Attributes: map[string]string{AttrMutex: "b, a,b"}}
+	unlock := m.lock(block)
+	if len(m.locks) != 2 {
+		t.Errorf("got %d locks, want 2", len(m.locks))
+	}
+	unlock()
+	// The locks are released, so they can be acquired again
+	m.lock(block)()
+	m.lock(parser.CodeBlock{})()
+}
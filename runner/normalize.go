@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrNormalize is the attribute listing comma separated normalizers applied to the output of a code block
+// before it is compared by expect-stdout~, expect-stderr~ and assert (e.g., normalize=ansi,timestamps).
+// The normalizers are added to those of the Runner; "none" disables them.
+const AttrNormalize = "normalize"
+
+// AttrReplacePrefix is the prefix of attributes replacing the matches of a regular expression in the output
+// of a code block with the placeholder <NAME> before it is compared (e.g., replace.ID="req-[0-9a-f]+" for <ID>).
+const AttrReplacePrefix = "replace."
+
+// Normalizers of output.
+const (
+	NormalizeANSI       = "ansi"       // Strip ANSI escape sequences (colors, cursor movements, ...)
+	NormalizeTimestamps = "timestamps" // Replace dates (with or without times) and times of day with <TIMESTAMP>
+	NormalizeUUIDs      = "uuids"      // Replace UUIDs with <UUID>
+	NormalizeSpace      = "space"      // Collapse runs of spaces and tabs, and trim trailing whitespace of lines and the output
+	normalizeNone       = "none"
+)
+
+var (
+	ansiRe      = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+	timestampRe = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?)?\b|\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`)
+	uuidRe      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	spaceRe     = regexp.MustCompile(`[ \t]+`)
+)
+
+// ParseNormalizers parses comma separated normalizers.
+func ParseNormalizers(s string) ([]string, error) {
+	var names []string
+	for n := range strings.SplitSeq(s, ",") {
+		n = strings.TrimSpace(n)
+		switch n {
+		case "":
+			continue
+		case NormalizeANSI, NormalizeTimestamps, NormalizeUUIDs, NormalizeSpace, normalizeNone:
+			names = append(names, n)
+		default:
+			return nil, fmt.Errorf("unknown normalizer %q (ansi, timestamps, uuids, space or none)", n)
+		}
+	}
+	return names, nil
+}
+
+// ParseReplacement parses a replacement of the matches of a regular expression with the placeholder <NAME> (format: NAME=REGEXP).
+func ParseReplacement(s string) (string, string, error) {
+	name, expr, ok := strings.Cut(s, "=")
+	if !ok || name == "" || expr == "" {
+		return "", "", fmt.Errorf("invalid replacement %q (format: NAME=REGEXP)", s)
+	}
+	if _, err := regexp.Compile(expr); err != nil {
+		return "", "", fmt.Errorf("invalid replacement %s: %w", name, err)
+	}
+	return name, expr, nil
+}
+
+// normalizer returns the function normalizing the output of a code block for comparisons
+// with the normalizers and the replacements of the Runner and the block.
+func (r *Runner) normalizer(block parser.CodeBlock) (func(string) string, error) {
+	names, err := ParseNormalizers(block.Attributes[AttrNormalize])
+	if err != nil {
+		return nil, err
+	}
+	replacements := map[string]string{}
+	if !slices.Contains(names, normalizeNone) {
+		names = append(slices.Clone(r.Normalizers), names...)
+		maps.Copy(replacements, r.Replacements)
+	}
+	for k, expr := range block.Attributes {
+		if name, ok := strings.CutPrefix(k, AttrReplacePrefix); ok {
+			replacements[name] = expr
+		}
+	}
+
+	var steps []func(string) string
+	if slices.Contains(names, NormalizeANSI) {
+		steps = append(steps, func(s string) string { return ansiRe.ReplaceAllString(s, "") })
+	}
+	// Replacements may match parts of timestamps and UUIDs, so they come first
+	keys := make([]string, 0, len(replacements))
+	for name := range replacements {
+		keys = append(keys, name)
+	}
+	slices.Sort(keys)
+	for _, name := range keys {
+		re, err := regexp.Compile(replacements[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s%s= pattern: %w", AttrReplacePrefix, name, err)
+		}
+		placeholder := "<" + name + ">"
+		steps = append(steps, func(s string) string { return re.ReplaceAllLiteralString(s, placeholder) })
+	}
+	if slices.Contains(names, NormalizeTimestamps) {
+		steps = append(steps, func(s string) string { return timestampRe.ReplaceAllLiteralString(s, "<TIMESTAMP>") })
+	}
+	if slices.Contains(names, NormalizeUUIDs) {
+		steps = append(steps, func(s string) string { return uuidRe.ReplaceAllLiteralString(s, "<UUID>") })
+	}
+	if slices.Contains(names, NormalizeSpace) {
+		steps = append(steps, collapseSpace)
+	}
+	return func(s string) string {
+		for _, step := range steps {
+			s = step(s)
+		}
+		return s
+	}, nil
+}
+
+// collapseSpace collapses runs of spaces and tabs into a space and trims trailing whitespace of the lines and s.
+func collapseSpace(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(spaceRe.ReplaceAllString(l, " "), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestNormalizer(t *testing.T) {
+	const output = "\x1b[32mok\x1b[0m  request req-1a2b\tat 2024-05-01T12:34:56.789Z  \n" +
+		"id 123e4567-E89B-12d3-a456-426614174000 took 12:00:01\r\n\n"
+	tests := []struct {
+		name         string
+		normalizers  []string
+		replacements map[string]string
+		attrs        map[string]string
+		want         string
+	}{
+		{"none", nil, nil, nil, output},
+		{
+			"all",
+			[]string{NormalizeANSI, NormalizeTimestamps, NormalizeUUIDs, NormalizeSpace},
+			map[string]string{"REQ": `req-[0-9a-f]+`},
+			nil,
+			"ok request <REQ> at <TIMESTAMP>\nid <UUID> took <TIMESTAMP>",
+		},
+		{
+			"attributes",
+			nil,
+			nil,
+			map[string]string{AttrNormalize: "ansi, uuids", AttrReplacePrefix + "REQ": `req-[0-9a-f]+`},
+			"ok  request <REQ>\tat 2024-05-01T12:34:56.789Z  \nid <UUID> took 12:00:01\r\n\n",
+		},
+		{
+			"attributes are added",
+			[]string{NormalizeANSI},
+			nil,
+			map[string]string{AttrNormalize: "space"},
+			"ok request req-1a2b at 2024-05-01T12:34:56.789Z\nid 123e4567-E89B-12d3-a456-426614174000 took 12:00:01",
+		},
+		{
+			"none disables those of the runner",
+			[]string{NormalizeANSI, NormalizeSpace},
+			map[string]string{"REQ": `req-[0-9a-f]+`},
+			map[string]string{AttrNormalize: "none,uuids"},
+			"\x1b[32mok\x1b[0m  request req-1a2b\tat 2024-05-01T12:34:56.789Z  \nid <UUID> took 12:00:01\r\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Runner{Normalizers: tt.normalizers, Replacements: tt.replacements}
+			normalize, err := r.normalizer(parser.CodeBlock{Attributes: tt.attrs})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := normalize(output); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizerInvalid(t *testing.T) {
+	r := &Runner{}
+	for _, attrs := range []map[string]string{
+		{AttrNormalize: "ansi,colors"},
+		{AttrReplacePrefix + "ID": "("},
+	} {
+		if _, err := r.normalizer(parser.CodeBlock{Attributes: attrs}); err == nil {
+			t.Errorf("%v: want error", attrs)
+		}
+	}
+	for _, s := range []string{"ID", "=x", "ID=", "ID=("} {
+		if _, _, err := ParseReplacement(s); err == nil {
+			t.Errorf("ParseReplacement(%q): want error", s)
+		}
+	}
+	name, expr, err := ParseReplacement("ID=a=b")
+	if err != nil || name != "ID" || expr != "a=b" {
+		t.Errorf("got (%q, %q, %v), want (ID, a=b, nil)", name, expr, err)
+	}
+}
+
+func TestRun_Normalize(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	block := parser.CodeBlock{
+		Language: "sh",
+		Command:  `printf '\033[1mdeployed\033[0m   at %s\n' "$(date -u +%Y-%m-%dT%H:%M:%SZ)"`,
+		Attributes: map[string]string{
+			AttrExpectStdout: `^deployed at <TIMESTAMP>$`,
+			AttrAssert:       `stdout == "deployed at <TIMESTAMP>"`,
+		},
+	}
+	r := &Runner{Stdout: io.Discard, Stderr: io.Discard}
+	if err := r.Run(context.Background(), block, 0); err == nil {
+		t.Fatal("want error without normalizers")
+	}
+	r.Normalizers = []string{NormalizeANSI, NormalizeSpace}
+	block.Attributes[AttrNormalize] = NormalizeTimestamps
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatal(err)
+	}
+}
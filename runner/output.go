@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// binaryPreviewSize is the number of bytes shown as a hex preview of binary output.
+const binaryPreviewSize = 64
+
+// outputGuard caps the output of a stream and replaces binary output with a notice.
+type outputGuard struct {
+	w         io.Writer
+	name      string // Stream name (stdout or stderr)
+	limit     int64  // Maximum number of bytes written (0 means no limit)
+	detect    bool   // Whether binary output is detected
+	checked   bool
+	binary    bool
+	preview   []byte
+	written   int64
+	total     int64
+	truncated bool
+}
+
+// newOutputGuard returns w wrapped by an outputGuard, or nil if no guard is needed.
+func newOutputGuard(w io.Writer, name string, limit int64, detect bool) *outputGuard {
+	if limit <= 0 && !detect {
+		return nil
+	}
+	return &outputGuard{w: w, name: name, limit: limit, detect: detect}
+}
+
+// Write writes p to the underlying writer within the limit.
+// It always reports len(p) bytes written so that the command is not interrupted.
+func (g *outputGuard) Write(p []byte) (int, error) {
+	n := len(p)
+	g.total += int64(n)
+
+	if g.detect && !g.checked {
+		g.checked = true
+		g.binary = isBinary(p)
+	}
+	if g.binary {
+		if len(g.preview) < binaryPreviewSize {
+			g.preview = append(g.preview, p[:min(len(p), binaryPreviewSize-len(g.preview))]...)
+		}
+		return n, nil
+	}
+
+	if g.limit > 0 {
+		remaining := g.limit - g.written
+		if remaining <= 0 {
+			g.truncated = true
+			return n, nil
+		}
+		if int64(len(p)) > remaining {
+			p = p[:remaining]
+			g.truncated = true
+		}
+	}
+	written, err := g.w.Write(p)
+	g.written += int64(written)
+	if err != nil {
+		return written, err
+	}
+	return n, nil
+}
+
+// finish writes a notice about suppressed or truncated output.
+func (g *outputGuard) finish() {
+	switch {
+	case g.binary:
+		fmt.Fprintf(g.w, "[runblock] binary %s suppressed (%d bytes). First bytes:\n%s", g.name, g.total, hex.Dump(g.preview))
+	case g.truncated:
+		fmt.Fprintf(g.w, "\n[runblock] %s truncated: %d of %d bytes shown\n", g.name, g.written, g.total)
+	}
+}
+
+// isBinary reports whether the beginning of an output looks like binary data.
+func isBinary(p []byte) bool {
+	if len(p) > 512 {
+		p = p[:512]
+	}
+	if bytes.IndexByte(p, 0) >= 0 {
+		return true
+	}
+	// Ignore a multi-byte character cut at the end of the chunk
+	for i := 0; i < utf8.UTFMax && len(p) > 0 && !utf8.Valid(p); i++ {
+		p = p[:len(p)-1]
+	}
+	return !utf8.Valid(p)
+}
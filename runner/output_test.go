@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestOutputGuard(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int64
+		detect bool
+		writes []string
+		want   string
+	}{
+		{
+			name:   "under limit",
+			limit:  10,
+			writes: []string{"hello"},
+			want:   "hello",
+		},
+		{
+			name:   "truncated across writes",
+			limit:  8,
+			writes: []string{"hello", " world"},
+			want:   "hello wo\n[runblock] stdout truncated: 8 of 11 bytes shown\n",
+		},
+		{
+			name:   "text is not binary",
+			detect: true,
+			writes: []string{"こんにちは"[:7]},
+			want:   "こんにちは"[:7],
+		},
+		{
+			name:   "binary",
+			detect: true,
+			writes: []string{"\x00\x01\x02", "abc"},
+			want:   "[runblock] binary stdout suppressed (6 bytes). First bytes:\n00000000  00 01 02 61 62 63                                 |...abc|\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			g := newOutputGuard(&buf, "stdout", tt.limit, tt.detect)
+			for _, w := range tt.writes {
+				n, err := g.Write([]byte(w))
+				if err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				if n != len(w) {
+					t.Errorf("Write() = %d, want %d", n, len(w))
+				}
+			}
+			g.finish()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_MaxOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout:    &stdout,
+		Stderr:    &stderr,
+		MaxOutput: 4,
+	}
+	block := parser.CodeBlock{Language: "text", Command: "cat", Content: "0123456789"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := stdout.String(); !strings.HasPrefix(got, "0123\n[runblock] stdout truncated") {
+		t.Errorf("stdout = %q", got)
+	}
+}
+
+func TestRun_CombineOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout:        &stdout,
+		Stderr:        &stderr,
+		CombineOutput: true,
+	}
+	block := parser.CodeBlock{Language: "sh", Command: "echo 1; echo 2 >&2; echo 3; echo 4 >&2"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := stdout.String(), "1\n2\n3\n4\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	if got := stderr.String(); got != "" {
+		t.Errorf("stderr = %q, want empty", got)
+	}
+}
+
+func TestRun_CaptureOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	var got *Result
+	r := &Runner{
+		Stdout:        &stdout,
+		Stderr:        &stderr,
+		CaptureOutput: true,
+		OnResult:      func(result *Result) { got = result },
+	}
+	block := parser.CodeBlock{Language: "sh", Command: "echo out; echo err >&2"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got.Stdout != "out\n" || got.Stderr != "err\n" {
+		t.Errorf("captured stdout = %q, stderr = %q", got.Stdout, got.Stderr)
+	}
+	// Output is still streamed
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Errorf("streamed stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
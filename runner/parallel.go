@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// parallelGroup returns the language group of a code block in Parallel and its maximum concurrency
+// (0 if the block runs alone). The languages of the block and their aliases are looked up in order.
+func (r *Runner) parallelGroup(block parser.CodeBlock) (string, int) {
+	for _, lang := range block.Languages() {
+		for _, l := range r.Aliases.Languages(lang) {
+			if n, ok := r.Parallel[l]; ok && n > 0 {
+				return l, n
+			}
+		}
+	}
+	return "", 0
+}
+
+// runDeferred runs a code block concurrently with others. Its output is buffered, and the output
+// and the hooks are replayed under mu when the block finishes, so that the output of concurrent
+// blocks is not interleaved and the hooks see one block at a time.
+func (r *Runner) runDeferred(ctx context.Context, block parser.CodeBlock, index int, mu *sync.Mutex) *Result {
+	var stdout, stderr bytes.Buffer
+	rc := *r
+	rc.Stdout, rc.Stderr, rc.WriterFor = &stdout, &stderr, nil
+	rc.OnStart, rc.OnResult = nil, nil
+	result := rc.run(ctx, block, index, 0)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if r.OnStart != nil && !result.StartedAt.IsZero() {
+		r.OnStart(result)
+	}
+	outW, errW := r.writers(block, index)
+	_, _ = stdout.WriteTo(outW) //nostyle:handlerrors
+	_, _ = stderr.WriteTo(errW) //nostyle:handlerrors
+	if r.OnResult != nil {
+		r.OnResult(result)
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrPipeTo is the attribute streaming the stdout of the block into the stdin of a later block
+// with the name (e.g., pipe-to=count). Both blocks run concurrently like a shell pipeline.
+const AttrPipeTo = "pipe-to"
+
+// PipeTargets returns the indexes of the blocks receiving the stdout of the blocks with the pipe-to attribute,
+// keyed by the indexes of the sending blocks. A block receiving a pipe must be a later block of the same stage,
+// and cannot send a pipe itself, receive more than one pipe, or have the stdin or split attribute.
+func PipeTargets(blocks []parser.CodeBlock) (map[int]int, error) {
+	targets := map[int]int{}
+	received := map[int]int{}
+	for i, block := range blocks {
+		name, ok := block.Attributes[AttrPipeTo]
+		if !ok {
+			continue
+		}
+		j := -1
+		for k := i + 1; k < len(blocks); k++ {
+			if blocks[k].Name() == name {
+				j = k
+				break
+			}
+		}
+		if name == "" || j < 0 {
+			return nil, fmt.Errorf("code block %d: %s %q does not name a later block", i+1, AttrPipeTo, name)
+		}
+		target := blocks[j]
+		from, _ := BlockStage(block) //nostyle:handlerrors
+		to, _ := BlockStage(target)  //nostyle:handlerrors
+		switch {
+		case from != to:
+			return nil, fmt.Errorf("code block %d: cannot pipe to block %d of another stage", i+1, j+1)
+		case received[j] > 0:
+			return nil, fmt.Errorf("code block %d: block %d already receives the pipe of block %d", i+1, j+1, received[j])
+		}
+		for _, attr := range []string{AttrPipeTo, AttrStdin, AttrSplit} {
+			if _, ok := target.Attributes[attr]; ok {
+				return nil, fmt.Errorf("code block %d: cannot pipe to block %d with the %s attribute", i+1, j+1, attr)
+			}
+		}
+		targets[i] = j
+		received[j] = i + 1
+	}
+	return targets, nil
+}
+
+// runPipe runs the block at i with its stdout streamed into the stdin of the block at j, which runs concurrently.
+// The output and the hooks of the receiving block are replayed when both blocks finish,
+// so that the hooks see one block at a time.
+func (r *Runner) runPipe(ctx context.Context, blocks []parser.CodeBlock, i, j int, pause time.Duration) (*Result, *Result) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		err = fmt.Errorf("failed to create pipe: %w", err)
+		return &Result{Index: i, Block: blocks[i], ExitCode: -1, Err: err}, &Result{Index: j, Block: blocks[j], ExitCode: -1, Err: err}
+	}
+
+	var stdout, stderr bytes.Buffer
+	rc := *r
+	rc.Stdout, rc.Stderr, rc.WriterFor = &stdout, &stderr, nil
+	rc.OnStart, rc.OnResult = nil, nil
+	rc.stdin = pr
+	done := make(chan *Result)
+	go func() {
+		result := rc.run(ctx, blocks[j], j, 0)
+		// The sender gets EPIPE if it writes after the receiver has finished (e.g., head)
+		_ = pr.Close() //nostyle:handlerrors
+		done <- result
+	}()
+
+	sc := *r
+	_, errW := r.writers(blocks[i], i)
+	sc.Stdout, sc.Stderr, sc.WriterFor = pw, errW, nil
+	sc.rawStdout = true // The receiver gets the output as is
+	src := sc.run(ctx, blocks[i], i, pause)
+	// The receiver reads EOF once the sender has finished
+	_ = pw.Close() //nostyle:handlerrors
+	dst := <-done
+
+	if r.OnStart != nil && !dst.StartedAt.IsZero() {
+		r.OnStart(dst)
+	}
+	outW, errW := r.writers(blocks[j], j)
+	_, _ = stdout.WriteTo(outW) //nostyle:handlerrors
+	_, _ = stderr.WriteTo(errW) //nostyle:handlerrors
+	if r.OnResult != nil {
+		r.OnResult(dst)
+	}
+	return src, dst
+}
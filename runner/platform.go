@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrOS is the attribute listing the operating systems a code block runs on, separated by commas
+// (e.g., os=darwin,windows). Blocks for other operating systems are skipped, so per-OS variants of
+// a step can sit side by side. The names are those of GOOS (linux, darwin, windows, ...).
+const AttrOS = "os"
+
+// AttrArch is the attribute listing the architectures a code block runs on, separated by commas
+// (e.g., arch=amd64,arm64). The names are those of GOARCH.
+const AttrArch = "arch"
+
+// platformMismatch returns why a code block does not run on the current platform ("" if it does).
+func platformMismatch(block parser.CodeBlock) string {
+	if v, ok := block.Attributes[AttrOS]; ok && !matchPlatform(v, runtime.GOOS) {
+		return fmt.Sprintf("for %s=%s (running on %s)", AttrOS, v, runtime.GOOS)
+	}
+	if v, ok := block.Attributes[AttrArch]; ok && !matchPlatform(v, runtime.GOARCH) {
+		return fmt.Sprintf("for %s=%s (running on %s)", AttrArch, v, runtime.GOARCH)
+	}
+	return ""
+}
+
+// matchPlatform reports whether the comma-separated list contains the name of the platform.
+func matchPlatform(list, name string) bool {
+	return slices.ContainsFunc(strings.Split(list, ","), func(s string) bool {
+		return strings.EqualFold(strings.TrimSpace(s), name)
+	})
+}
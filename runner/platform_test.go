@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRunAll_OS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	other := "plan9"
+	source := "```sh {os=" + runtime.GOOS + "} sh\necho native\n```\n\n" +
+		"```sh {os=" + other + " requires=runblock-missing-tool} sh\necho other\n```\n\n" +
+		"```sh {os=\"" + other + ", " + strings.ToUpper(runtime.GOOS) + "\"} sh\necho listed\n```\n"
+	blocks, err := parser.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	var skipped []string
+	r := New("", nil)
+	r.Stdout = &stdout
+	r.OnResult = func(result *Result) {
+		if result.Skipped {
+			skipped = append(skipped, result.SkipReason)
+		}
+	}
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "native\nlisted\n"; got != want {
+		t.Errorf("got stdout %q, want %q", got, want)
+	}
+	want := "for os=" + other + " (running on " + runtime.GOOS + ")"
+	if len(skipped) != 1 || skipped[0] != want {
+		t.Errorf("got skip reasons %q, want [%q]", skipped, want)
+	}
+}
+
+func TestRunAll_Arch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	source := "```sh {arch=" + runtime.GOARCH + "} sh -c 'echo {{os}}/{{arch}}'\n```\n\n" +
+		"```sh {arch=wasm} sh\necho wasm\n```\n"
+	blocks, err := parser.Parse([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	var skipped []string
+	r := New("", nil)
+	r.Stdout = &stdout
+	r.OnResult = func(result *Result) {
+		if result.Skipped {
+			skipped = append(skipped, result.SkipReason)
+		}
+	}
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), runtime.GOOS+"/"+runtime.GOARCH+"\n"; got != want {
+		t.Errorf("got stdout %q, want %q", got, want)
+	}
+	want := "for arch=wasm (running on " + runtime.GOARCH + ")"
+	if len(skipped) != 1 || skipped[0] != want {
+		t.Errorf("got skip reasons %q, want [%q]", skipped, want)
+	}
+}
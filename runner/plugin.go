@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrUse is the attribute selecting the executor plugin of a block (e.g., use=bigquery runs runblock-exec-bigquery).
+const AttrUse = "use"
+
+// PluginPrefix is the prefix of the executables of executor plugins.
+const PluginPrefix = "runblock-exec-"
+
+// PluginRequest is the descriptor of a code block passed to an executor plugin as JSON on stdin.
+type PluginRequest struct {
+	Index      int               `json:"index"`
+	Line       int               `json:"line"`
+	Lang       string            `json:"lang"`
+	Content    string            `json:"content"` // The chunk for split blocks
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	TmpDir     string            `json:"tmpdir,omitempty"`
+}
+
+// plugin returns the executor plugin of a code block: the use attribute, or the plugin
+// for its languages in Plugins looked up like Commands. It also reports whether the
+// plugin is given by the block itself.
+func (r *Runner) plugin(block parser.CodeBlock) (string, bool) {
+	if name := block.Attributes[AttrUse]; name != "" {
+		return name, true
+	}
+	for _, lang := range block.Languages() {
+		for _, l := range r.Aliases.Languages(lang) {
+			if name := r.Plugins[l]; name != "" {
+				return name, false
+			}
+		}
+	}
+	return "", false
+}
+
+// pluginInput returns the JSON descriptor of a chunk of a code block for its executor plugin.
+func (r *Runner) pluginInput(block parser.CodeBlock, index int, chunk string) (string, error) {
+	b, err := json.Marshal(PluginRequest{
+		Index:      index,
+		Line:       block.Line,
+		Lang:       block.Language,
+		Content:    chunk,
+		Attributes: block.Attributes,
+		Env:        BlockEnv(block),
+		TmpDir:     r.TmpDir,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// lookPlugin returns the path of the executable of an executor plugin.
+func lookPlugin(command string) (string, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", fmt.Errorf("executor plugin %s not found in PATH: %w", command, err)
+	}
+	return path, nil
+}
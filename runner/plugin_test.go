@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestRun_Plugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"cat"), []byte("#!/bin/sh\ncat\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name  string
+		block parser.CodeBlock
+	}{
+		{"use attribute", parser.CodeBlock{Language: "sql", Command: "ignored", Content: "select 1;\n", Line: 3, Attributes: map[string]string{"use": "cat", "env.DB": "test"}}},
+		{"language plugin", parser.CodeBlock{Language: "bq", Content: "select 1;\n", Line: 3, Attributes: map[string]string{"env.DB": "test"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr, Plugins: map[string]string{"bq": "cat"}}
+			if err := r.Run(context.Background(), tt.block, 2); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var got PluginRequest
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("plugin input is not JSON: %v: %q", err, stdout.String())
+			}
+			if got.Index != 2 || got.Line != 3 || got.Lang != tt.block.Language || got.Content != "select 1;\n" || got.Env["DB"] != "test" {
+				t.Errorf("plugin input = %+v", got)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr}
+	if err := r.Run(context.Background(), parser.CodeBlock{Language: "sql", Attributes: map[string]string{"use": "missing"}}, 0); err == nil {
+		t.Error("Run() should return error for a missing plugin")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/google/cel-go/cel"
+	"github.com/k1LoW/runblock/parser"
+)
+
+// ErrPolicyDenied is returned when the policy denies a code block and PolicyAbort is set.
+var ErrPolicyDenied = errors.New("denied by policy")
+
+// evalPolicy evaluates the policy expression for a resolved code block.
+// In addition to the template variables, the policy can use
+// command (expanded command), source (command source), attrs (attributes) and env_list (added environment).
+func (r *Runner) evalPolicy(block parser.CodeBlock, res *Resolution) (bool, error) {
+	attrs := map[string]string{}
+	maps.Copy(attrs, block.Attributes)
+
+	store := maps.Clone(res.store)
+	store["command"] = res.Command
+	store["source"] = res.Source
+	store["attrs"] = attrs
+	store["env_list"] = res.Env
+
+	allowed, err := evalBool(r.Policy, store, r.trace())
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+	return allowed, nil
+}
+
+// CheckPolicy compiles the policy expression without evaluating it and
+// returns an error if it is invalid or does not evaluate to a bool.
+func CheckPolicy(policy string) error {
+	store := map[string]any{
+		"lang":     "",
+		"content":  "",
+		"i":        0,
+		"env":      map[string]string{},
+		"tmpdir":   "",
+		"chunk":    "",
+		"chunk_i":  0,
+		"heading":  "",
+		"slug":     "",
+		"id":       "",
+		"prev":     map[string]any{},
+		"vars":     map[string]string{},
+		"secrets":  map[string]string{},
+		"os":       "",
+		"arch":     "",
+		"command":  "",
+		"source":   "",
+		"attrs":    map[string]string{},
+		"env_list": []string{},
+	}
+	env, err := createCELEnv(store)
+	if err != nil {
+		return err
+	}
+	ast, issues := env.Compile(policy)
+	if issues != nil && issues.Err() != nil {
+		return fmt.Errorf("policy compilation error: %w", issues.Err())
+	}
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return fmt.Errorf("policy must evaluate to bool, got %s", t)
+	}
+	return nil
+}
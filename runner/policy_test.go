@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestResolve_Policy(t *testing.T) {
+	policy := `!command.contains("rm ") && (lang == "sh" || ("trusted" in attrs && attrs["trusted"] == "true"))`
+
+	tests := []struct {
+		name     string
+		block    parser.CodeBlock
+		abort    bool
+		wantSkip bool
+		wantErr  bool
+	}{
+		{
+			name:  "allowed",
+			block: parser.CodeBlock{Language: "sh", Command: "echo hello"},
+		},
+		{
+			name:  "allowed by attribute",
+			block: parser.CodeBlock{Language: "python", Command: "python3", Attributes: map[string]string{"trusted": "true"}},
+		},
+		{
+			name:     "denied and skipped",
+			block:    parser.CodeBlock{Language: "sh", Command: "rm -rf /tmp/x"},
+			wantSkip: true,
+		},
+		{
+			name:    "denied and aborted",
+			block:   parser.CodeBlock{Language: "python", Command: "python3"},
+			abort:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Runner{Policy: policy, PolicyAbort: tt.abort}
+			res, err := r.Resolve(tt.block, 0)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPolicyDenied) {
+					t.Errorf("Resolve() error = %v, want ErrPolicyDenied", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if res.Skip != tt.wantSkip {
+				t.Errorf("Skip = %v, want %v", res.Skip, tt.wantSkip)
+			}
+		})
+	}
+}
+
+func TestRun_PolicyDenied(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Policy: `lang != "sh"`,
+	}
+	block := parser.CodeBlock{Language: "sh", Command: "echo should not run"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := stdout.String(); got != "" {
+		t.Errorf("stdout = %q, want empty", got)
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{policy: `lang != "sh"`},
+		{policy: `!command.contains("rm -rf") && ("trusted" in attrs)`},
+		{policy: `env_list.size() < 10`},
+		{policy: `lang +`, wantErr: true},
+		{policy: `unknown == "x"`, wantErr: true},
+		{policy: `lang`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			if err := CheckPolicy(tt.policy); (err != nil) != tt.wantErr {
+				t.Errorf("CheckPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// lastResult is the result of the last executed block in RunAll, exposed to templates as {{prev}}.
+type lastResult struct {
+	mu      sync.Mutex
+	capture bool // Whether the output of blocks is captured for prev.stdout and prev.stderr
+	store   map[string]any
+}
+
+// newLastResult returns the last result of a run of the blocks.
+// The output of blocks is only captured if a command may refer to prev.
+func newLastResult(r *Runner, blocks []parser.CodeBlock) *lastResult {
+	templates := []string{r.DefaultCommand}
+	for _, cmd := range r.Commands {
+		templates = append(templates, cmd)
+	}
+	for _, block := range blocks {
+		templates = append(templates, block.Command)
+	}
+	capture := false
+	for _, t := range templates {
+		if strings.Contains(t, "prev") {
+			capture = true
+			break
+		}
+	}
+	return &lastResult{capture: capture}
+}
+
+// record records the result of an executed block with its output.
+func (l *lastResult) record(result *Result, stdout, stderr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store = map[string]any{
+		"i":           result.Index,
+		"lang":        result.Block.Language,
+		"name":        result.Block.Name(),
+		"exit_code":   result.ExitCode,
+		"duration_ms": result.Duration.Milliseconds(),
+		"stdout":      stdout,
+		"stderr":      stderr,
+	}
+}
+
+// prevStore returns the value of {{prev}}: the result of the last executed block,
+// or an index of -1 if no block has been executed.
+func (r *Runner) prevStore() map[string]any {
+	if r.last != nil {
+		r.last.mu.Lock()
+		defer r.last.mu.Unlock()
+		if r.last.store != nil {
+			return r.last.store
+		}
+	}
+	return map[string]any{
+		"i":           -1,
+		"lang":        "",
+		"name":        "",
+		"exit_code":   0,
+		"duration_ms": int64(0),
+		"stdout":      "",
+		"stderr":      "",
+	}
+}
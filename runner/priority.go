@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrNice is the attribute specifying the niceness of the block process (e.g., nice=10).
+const AttrNice = "nice"
+
+// niceness returns the niceness of the process of a code block (0 leaves it unchanged).
+func (r *Runner) niceness(block parser.CodeBlock) (int, error) {
+	nice := r.Nice
+	if v, ok := block.Attributes[AttrNice]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", AttrNice, err)
+		}
+		nice = n
+	}
+	if nice < -20 || nice > 19 {
+		return 0, fmt.Errorf("invalid %s %d: must be between -20 and 19", AttrNice, nice)
+	}
+	return nice, nil
+}
+
+// start starts the command with the niceness.
+// If the priority cannot be applied, the process is killed.
+func start(cmd *exec.Cmd, nice int) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if nice == 0 {
+		return nil
+	}
+	if err := setPriority(cmd.Process.Pid, nice); err != nil {
+		_ = cmd.Process.Kill() //nostyle:handlerrors
+		_ = cmd.Wait()         //nostyle:handlerrors
+		return fmt.Errorf("failed to set priority: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestPrepareProcess(t *testing.T) {
+	cmd := exec.Command("cmd", "/c", `echo "hello world" | findstr hello`)
+	prepareProcess(cmd)
+	if want := `cmd /s /c "echo "hello world" | findstr hello"`; cmd.SysProcAttr == nil || cmd.SysProcAttr.CmdLine != want {
+		t.Errorf("command line = %v, want %q", cmd.SysProcAttr, want)
+	}
+	if cmd.Cancel == nil {
+		t.Error("cancel should kill the process tree")
+	}
+}
+
+func TestRun_ExitCode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr}
+	if err := r.Run(context.Background(), parser.CodeBlock{Language: "bat", Command: "exit 0"}, 0); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if err := r.Run(context.Background(), parser.CodeBlock{Language: "bat", Command: "exit 3"}, 0); err == nil {
+		t.Error("Run() should return error")
+	}
+}
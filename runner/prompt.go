@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrPromptPrefix is the prefix of the attributes declaring inputs asked before the run and available as
+// {{vars.NAME}}: prompt.NAME="message" asks for NAME, prompt.NAME.default=value gives its default and
+// prompt.NAME.secret=true hides the answer.
+const AttrPromptPrefix = "prompt."
+
+// Prompt is an input asked before the run.
+type Prompt struct {
+	Name    string // Name of the variable (vars.NAME)
+	Message string // Message shown when asking (the name if empty)
+	Default string // Value used when the answer is empty
+	Secret  bool   // Whether the answer is hidden while typing
+}
+
+// vars returns the value of {{vars}}.
+func (r *Runner) vars() map[string]string {
+	if r.Vars == nil {
+		return map[string]string{}
+	}
+	return r.Vars
+}
+
+// BlockPrompts returns the inputs declared by the prompt.NAME attributes of a code block, sorted by name.
+func BlockPrompts(block parser.CodeBlock) ([]Prompt, error) {
+	prompts := map[string]*Prompt{}
+	get := func(name string) *Prompt {
+		p, ok := prompts[name]
+		if !ok {
+			p = &Prompt{Name: name}
+			prompts[name] = p
+		}
+		return p
+	}
+	for k, v := range block.Attributes {
+		key, ok := strings.CutPrefix(k, AttrPromptPrefix)
+		if !ok {
+			continue
+		}
+		name, field, _ := strings.Cut(key, ".")
+		if name == "" {
+			return nil, fmt.Errorf("invalid attribute %s: name is empty", k)
+		}
+		switch field {
+		case "":
+			get(name).Message = v
+		case "default":
+			get(name).Default = v
+		case "secret":
+			secret, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid attribute %s: %w", k, err)
+			}
+			get(name).Secret = secret
+		default:
+			return nil, fmt.Errorf("invalid attribute %s: unknown field %q (default or secret)", k, field)
+		}
+	}
+	list := make([]Prompt, 0, len(prompts))
+	for _, p := range prompts {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
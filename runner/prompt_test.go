@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestBlockPrompts(t *testing.T) {
+	tests := []struct {
+		name    string
+		attrs   map[string]string
+		want    []Prompt
+		wantErr bool
+	}{
+		{
+			"prompts",
+			map[string]string{"prompt.user": "User", "prompt.pass": "Password", "prompt.pass.secret": "true", "prompt.user.default": "admin", "env.X": "y"},
+			[]Prompt{{Name: "pass", Message: "Password", Secret: true}, {Name: "user", Message: "User", Default: "admin"}},
+			false,
+		},
+		{"none", nil, []Prompt{}, false},
+		{"unknown field", map[string]string{"prompt.user.hint": "x"}, nil, true},
+		{"invalid secret", map[string]string{"prompt.user.secret": "maybe"}, nil, true},
+		{"empty name", map[string]string{"prompt.": "x"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BlockPrompts(parser.CodeBlock{Attributes: tt.attrs})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Vars(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	var stdout bytes.Buffer
+	r := New("", nil)
+	r.Stdout = &stdout
+	r.Vars = map[string]string{"cluster": "staging"}
+	block := parser.CodeBlock{Language: "sh", Command: "echo {{ shquote(vars.cluster) }}"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "staging\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// rateUnits are the units accepted by ParseRate in addition to durations (e.g., 10/30s).
+var rateUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour,
+}
+
+// Limiter is a token bucket limiting how often block processes are started.
+// It is safe for concurrent use, so one Limiter is shared by blocks running in parallel.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Time to refill one token
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewLimiter creates a Limiter allowing n starts per the duration, up to n at once.
+func NewLimiter(n int, per time.Duration) *Limiter {
+	return &Limiter{
+		interval: per / time.Duration(n),
+		burst:    float64(n),
+		tokens:   float64(n),
+		last:     time.Now(),
+	}
+}
+
+// ParseRate parses a rate in the format N/UNIT (e.g., 10/min, 2/s, 5/30s) into a Limiter.
+func ParseRate(s string) (*Limiter, error) {
+	ns, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %q: expected N/UNIT (e.g., 10/min)", s)
+	}
+	n, err := strconv.Atoi(ns)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid rate %q: the number must be a positive integer", s)
+	}
+	per, ok := rateUnits[unit]
+	if !ok {
+		per, err = time.ParseDuration(unit)
+		if err != nil || per <= 0 {
+			return nil, fmt.Errorf("invalid rate %q: unknown unit %q", s, unit)
+		}
+	}
+	return NewLimiter(n, per), nil
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	l.last = now
+	// Reserve a token; a negative balance is the time the caller has to wait
+	l.tokens--
+	d := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, d); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// waitRate waits for the limiters of a code block: those of its tags in TagRateLimits,
+// or RateLimit if none of its tags has one.
+func (r *Runner) waitRate(ctx context.Context, block parser.CodeBlock) error {
+	var limiters []*Limiter
+	for _, tag := range block.Tags() {
+		if l, ok := r.TagRateLimits[tag]; ok {
+			limiters = append(limiters, l)
+		}
+	}
+	if len(limiters) == 0 && r.RateLimit != nil {
+		limiters = append(limiters, r.RateLimit)
+	}
+	for _, l := range limiters {
+		if err := l.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
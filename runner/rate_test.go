@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate         string
+		wantInterval time.Duration
+		wantErr      bool
+	}{
+		{"10/min", 6 * time.Second, false},
+		{"2/s", 500 * time.Millisecond, false},
+		{"1/hour", time.Hour, false},
+		{"5/30s", 6 * time.Second, false},
+		{"10", 0, true},
+		{"0/s", 0, true},
+		{"x/s", 0, true},
+		{"10/week", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rate, func(t *testing.T) {
+			l, err := ParseRate(tt.rate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && l.interval != tt.wantInterval {
+				t.Errorf("interval = %v, want %v", l.interval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestRun_RateLimit(t *testing.T) {
+	// Two blocks start at once and the third waits for a token
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout:        &stdout,
+		Stderr:        &stderr,
+		RateLimit:     NewLimiter(2, 200*time.Millisecond),
+		TagRateLimits: map[string]*Limiter{"api": NewLimiter(1, time.Hour)},
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "exit 0"},
+		{Language: "sh", Command: "exit 0"},
+		{Language: "sh", Command: "exit 0"},
+	}
+	start := time.Now()
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if d := time.Since(start); d < 80*time.Millisecond {
+		t.Errorf("RunAll() took %v, want about 100ms", d)
+	}
+
+	// A tag with its own limiter does not use RateLimit, and waiting is canceled with the context
+	tagged := parser.CodeBlock{Language: "sh", Command: "exit 0", Attributes: map[string]string{"tags": "api"}}
+	if err := r.Run(context.Background(), tagged, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := r.Run(ctx, tagged, 1); err == nil {
+		t.Error("Run() should return error when the rate limit wait is canceled")
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrRequires is the attribute listing the tools and versions a code block requires,
+// separated by commas (e.g., requires=go>=1.22,node>=20). Requirements are checked before any block runs.
+const AttrRequires = "requires"
+
+// ErrRequirement is returned when a required tool is missing or too old.
+var ErrRequirement = errors.New("requirement not met")
+
+// probeTimeout is the timeout of probing the version of a tool.
+const probeTimeout = 10 * time.Second
+
+var (
+	versionSpecRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+	versionRe     = regexp.MustCompile(`[0-9]+(\.[0-9]+)+|[0-9]+`)
+)
+
+// Requirement is a tool and, optionally, a constraint on its version.
+type Requirement struct {
+	Tool    string // Executable of the tool
+	Op      string // Comparison operator (>=, >, <=, < or =), or "" if any version is fine
+	Version string // Version compared with the version of the tool
+}
+
+func (req Requirement) String() string {
+	return req.Tool + req.Op + req.Version
+}
+
+// ParseRequirements parses comma-separated requirements such as "go>=1.22,node>=20,jq".
+// "=" matches the given components only, so node=20 is met by node 20.1.0.
+func ParseRequirements(spec string) ([]Requirement, error) {
+	var reqs []Requirement
+	for s := range strings.SplitSeq(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		req := Requirement{Tool: s}
+		if i := strings.IndexAny(s, "<>="); i >= 0 {
+			req.Tool, req.Op, req.Version = s[:i], s[i:], ""
+			for _, op := range []string{">=", "<=", ">", "<", "="} {
+				if v, ok := strings.CutPrefix(req.Op, op); ok {
+					req.Op, req.Version = op, strings.TrimSpace(v)
+					break
+				}
+			}
+			if !versionSpecRe.MatchString(req.Version) {
+				return nil, fmt.Errorf("invalid requirement %q: version must be numbers separated by dots", s)
+			}
+		}
+		req.Tool = strings.TrimSpace(req.Tool)
+		if req.Tool == "" {
+			return nil, fmt.Errorf("invalid requirement %q: tool is empty", s)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// BlockRequirements returns the requirements of the requires attribute of a code block.
+func BlockRequirements(block parser.CodeBlock) ([]Requirement, error) {
+	spec, ok := block.Attributes[AttrRequires]
+	if !ok {
+		return nil, nil
+	}
+	reqs, err := ParseRequirements(spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%s requires at least one tool", AttrRequires)
+	}
+	return reqs, nil
+}
+
+// CompareVersions compares dot-separated numeric versions, treating missing components as 0.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i]) //nostyle:handlerrors
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i]) //nostyle:handlerrors
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Met reports whether version satisfies the requirement.
+func (req Requirement) Met(version string) bool {
+	switch req.Op {
+	case "":
+		return true
+	case "=":
+		// Compare only the components of the requirement
+		n := strings.Count(req.Version, ".") + 1
+		parts := strings.SplitN(version, ".", n+1)
+		return CompareVersions(strings.Join(parts[:min(n, len(parts))], "."), req.Version) == 0
+	}
+	c := CompareVersions(version, req.Version)
+	switch req.Op {
+	case ">=":
+		return c >= 0
+	case ">":
+		return c > 0
+	case "<=":
+		return c <= 0
+	default:
+		return c < 0
+	}
+}
+
+// ProbeVersion returns the version of a tool reported by 'tool --version' (or 'tool version',
+// for tools such as go that have no --version flag).
+func ProbeVersion(ctx context.Context, tool string) (string, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed", tool)
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	for _, arg := range []string{"--version", "version"} {
+		out, err := exec.CommandContext(ctx, path, arg).CombinedOutput()
+		if err != nil {
+			continue
+		}
+		if v := versionRe.FindString(string(out)); v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("failed to get the version of %s with '%s --version'", tool, tool)
+}
+
+// checkRequirements checks the requirements of the runner and of the selected blocks for the current platform
+// before any of them run.
+// All unmet requirements are reported at once.
+func (r *Runner) checkRequirements(ctx context.Context, blocks []parser.CodeBlock, selected func(int) bool) error {
+	versions := map[string]string{}
+	probeErrs := map[string]error{}
+	checked := map[string]bool{}
+	var errs []error
+	check := func(where string, req Requirement) {
+		if checked[req.String()] {
+			return
+		}
+		checked[req.String()] = true
+		if req.Op == "" {
+			if _, err := exec.LookPath(req.Tool); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s requires %s, but it is not installed", ErrRequirement, where, req.Tool))
+			}
+			return
+		}
+		v, ok := versions[req.Tool]
+		if !ok {
+			var err error
+			v, err = ProbeVersion(ctx, req.Tool)
+			versions[req.Tool], probeErrs[req.Tool] = v, err
+		}
+		if err := probeErrs[req.Tool]; err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s requires %s, but %v", ErrRequirement, where, req, err))
+			return
+		}
+		if !req.Met(v) {
+			errs = append(errs, fmt.Errorf("%w: %s requires %s, but found %s %s", ErrRequirement, where, req, req.Tool, v))
+		}
+	}
+	for _, req := range r.Requires {
+		check("the document", req)
+	}
+	for i, block := range blocks {
+		if !selected(i) || platformMismatch(block) != "" {
+			continue
+		}
+		reqs, err := BlockRequirements(block)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("code block %d: %w", i+1, err))
+			continue
+		}
+		for _, req := range reqs {
+			check(fmt.Sprintf("code block %d", i+1), req)
+		}
+	}
+	return errors.Join(errs...)
+}
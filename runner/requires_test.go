@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []Requirement
+		wantErr bool
+	}{
+		{"go>=1.22,node>=20", []Requirement{{"go", ">=", "1.22"}, {"node", ">=", "20"}}, false},
+		{" jq , python3 < 4 ", []Requirement{{"jq", "", ""}, {"python3", "<", "4"}}, false},
+		{"node=20", []Requirement{{"node", "=", "20"}}, false},
+		{"", nil, false},
+		{"go>=latest", nil, true},
+		{">=1.22", nil, true},
+		{"go>=", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRequirements(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirementMet(t *testing.T) {
+	tests := []struct {
+		req     string
+		version string
+		want    bool
+	}{
+		{"go>=1.22", "1.22.0", true},
+		{"go>=1.22", "1.21.9", false},
+		{"go>=1.22", "1.100", true},
+		{"node>20", "20.0.1", true},
+		{"node>20", "20", false},
+		{"node<=20", "20.5.0", false},
+		{"node<21", "20.5.0", true},
+		{"node=20", "20.1.0", true},
+		{"node=20.1", "20.10.0", false},
+		{"jq", "1.6", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.req+" "+tt.version, func(t *testing.T) {
+			reqs, err := ParseRequirements(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reqs[0].Met(tt.version); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAll_Requires(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	// The go tool running the tests is always installed
+	tests := []struct {
+		name     string
+		requires []Requirement
+		source   string
+		wantErr  string
+	}{
+		{"met", nil, "```sh {requires=go>=1.0,sh} sh\necho ran\n```\n", ""},
+		{"too old", nil, "```sh {requires=go>=999} sh\necho ran\n```\n", "code block 1 requires go>=999, but found go"},
+		{"missing", nil, "```sh {requires=runblock-missing-tool>=1} sh\necho ran\n```\n", "runblock-missing-tool is not installed"},
+		{"document", []Requirement{{"go", "<", "1"}}, "```sh sh\necho ran\n```\n", "the document requires go<1"},
+		{"invalid", nil, "```sh {requires=go>=x} sh\necho ran\n```\n", "invalid requirement"},
+		{"unselected", nil, "```sh sh\necho ran\n```\n\n```sh {name=skip requires=go>=999} sh\necho skipped\n```\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := parser.Parse([]byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var stdout bytes.Buffer
+			r := New("", nil)
+			r.Stdout = &stdout
+			r.Requires = tt.requires
+			r.Select = func(block parser.CodeBlock, _ int) bool { return block.Name() != "skip" }
+			err = r.RunAll(context.Background(), blocks)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := stdout.String(); got != "ran\n" {
+					t.Errorf("got stdout %q, want %q", got, "ran\n")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got err %v, want it to contain %q", err, tt.wantErr)
+			}
+			if tt.name != "invalid" && !errors.Is(err, ErrRequirement) {
+				t.Errorf("got err %v, want ErrRequirement", err)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("got stdout %q, want no block to run", stdout.String())
+			}
+		})
+	}
+}
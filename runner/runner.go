@@ -22,27 +22,84 @@ THE SOFTWARE.
 package runner
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"regexp"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/sandbox"
 )
 
 // Runner executes commands for code blocks.
 type Runner struct {
 	DefaultCommand string
 	Commands       map[string]string // language -> command
+	Plugins        map[string]string // language -> executor plugin (runblock-exec-<plugin>)
+	Aliases        Aliases           // Equivalent language identifiers used to look up Commands
+	DefaultLangs   []string          // If set, DefaultCommand applies only to blocks with these languages
+	HonorShebang   bool              // If true, blocks without a command whose content starts with #! are executed as scripts
+	Parallel       map[string]int    // Maximum number of concurrent blocks per language (blocks of other languages run alone)
 	Stdout         io.Writer
 	Stderr         io.Writer
+	WriterFor      WriterFunc                       // If set, used instead of Stdout and Stderr for the output of each block
+	Env            []string                         // If not nil, the environment block processes start with instead of os.Environ()
+	EnvFunc        EnvFunc                          // If set, returns the environment each block process starts with (takes precedence over Env)
+	Trace          io.Writer                        // If set, template evaluations are logged to Trace
+	Logger         *slog.Logger                     // If set, debug events of the blocks are logged to Logger (see WithLogger)
+	OnStart        func(*Result)                    // If set, called before the command of a code block is started
+	OnResult       func(*Result)                    // If set, called with the result of every code block
+	Policy         string                           // CEL expression deciding whether a block may be executed
+	PolicyAbort    bool                             // If true, a denied block aborts the run instead of being skipped
+	RawContent     bool                             // If true, commands may interpolate {{content}} and {{chunk}} into the shell unquoted
+	MaxOutput      int64                            // Maximum bytes of output streamed per block and stream (0 means no limit)
+	DetectBinary   bool                             // If true, binary output is replaced with a notice and a hex preview
+	CombineOutput  bool                             // If true, stderr is merged into stdout in the order it was written
+	Interval       time.Duration                    // Pause between block executions
+	KeepGoing      bool                             // If true, RunAll runs all blocks even if some of them fail
+	KeepSiblings   bool                             // If true, a failure lets concurrent blocks in flight finish instead of canceling them
+	Requires       []Requirement                    // Tools required by the document in addition to the requires attributes of the blocks
+	Vars           map[string]string                // Values of {{vars.NAME}} (e.g., the answers to prompts)
+	Secrets        map[string]string                // Values of {{secrets.NAME}}, masked as *** in the output and the commands of results
+	Normalizers    []string                         // Normalizers of the output of every block compared by expect-stdout~, expect-stderr~ and assert (see AttrNormalize)
+	Replacements   map[string]string                // Regular expressions replaced with <NAME> in the output of every block before it is compared
+	Select         func(parser.CodeBlock, int) bool // If set, RunAll only runs blocks it returns true for
+	CaptureOutput  bool                             // If true, the output of blocks is captured into their Result
+	Nice           int                              // Niceness of block processes (0 leaves it unchanged)
+	User           string                           // If set, block processes run as the user (directly as root, via sudo otherwise)
+	ReadOnly       bool                             // If true, block processes cannot write to the filesystem except to AllowWrite (Linux only)
+	AllowWrite     []string                         // Paths block processes can write to with ReadOnly
+	ArtifactsDir   string                           // Directory the files matching the artifacts attribute are copied into
+	Resolutions    map[int]*Resolution              // If set, blocks with these indexes use the resolutions instead of resolving their commands (e.g., from a plan)
+	UpToDate       func(*Result) bool               // If set, called after resolution; blocks it returns true for are skipped as up to date
+	TmpDir         string                           // Temporary directory of the run exposed as {{tmpdir}} and CODEBLOCK_TMPDIR (writable with ReadOnly)
+	File           string                           // Path of the document the blocks come from, part of their IDs ({{id}} and CODEBLOCK_ID)
+	RateLimit      *Limiter                         // If set, every block process waits for a token (shared by concurrent blocks)
+	TagRateLimits  map[string]*Limiter              // Limiters used instead of RateLimit for blocks with the tags
+
+	middleware []Middleware // Added by Use
+	stdin      io.Reader    // If set, the stdin of the commands (the receiving end of a pipe)
+	last       *lastResult  // Result of the last executed block in RunAll
+	rawStdout  bool         // If true, secrets are not masked in stdout (the sending end of a pipe)
 }
 
+// AttrSleepBefore is the attribute specifying a pause before the block is executed (e.g., sleep-before=2s).
+const AttrSleepBefore = "sleep-before"
+
 // New creates a new Runner with the given default command and language-specific commands.
 func New(defaultCommand string, commands map[string]string) *Runner {
 	return &Runner{
@@ -53,69 +110,728 @@ func New(defaultCommand string, commands map[string]string) *Runner {
 	}
 }
 
-// Run executes the command for a code block.
+// Command sources reported by Resolve.
+const (
+	SourceBlock    = "info string"
+	SourceLanguage = "language map"
+	SourceDefault  = "default"
+	SourceShebang  = "shebang"
+	SourcePlugin   = "plugin"
+	SourceExecutor = "executor"
+)
+
+// Resolution describes how the command for a code block is resolved.
+// It is serialized into execution plans, so the fields are tagged for JSON.
+type Resolution struct {
+	Source     string   `json:"source,omitempty"`      // Where the command template came from (empty if none)
+	Template   string   `json:"template,omitempty"`    // Command template before expansion
+	Command    string   `json:"command,omitempty"`     // Fully expanded command
+	Env        []string `json:"env,omitempty"`         // Environment variables added to the process
+	Dir        string   `json:"dir,omitempty"`         // Working directory of the process (empty for that of runblock)
+	Skip       bool     `json:"skip,omitempty"`        // Whether the block is skipped
+	SkipReason string   `json:"skip_reason,omitempty"` // Why the block is skipped
+
+	store  map[string]any // Values the command template was expanded with
+	tmpDir string         // Temporary directory of the run the command was expanded with
+}
+
+// TmpDirPlaceholder stands for the temporary directory of the run in hashes and execution plans,
+// since the directory differs in every run.
+const TmpDirPlaceholder = "{{tmpdir}}"
+
+// Resolve resolves the command for a code block without executing it.
 // index is the 0-based index of the code block.
-func (r *Runner) Run(ctx context.Context, block parser.CodeBlock, index int) error {
-	// Determine command to use (priority: block command > language command > default command)
-	cmd := block.Command
-	if cmd == "" && r.Commands != nil {
-		cmd = r.Commands[block.Language]
+// If Resolutions has the index, the resolution is used as is except for the policy.
+func (r *Runner) Resolve(block parser.CodeBlock, index int) (*Resolution, error) {
+	return r.resolve(block, index, block.Content, 0)
+}
+
+// resolve resolves the command for a chunk of a code block (the whole content if it is not split).
+func (r *Runner) resolve(block parser.CodeBlock, index int, chunk string, chunkIndex int) (*Resolution, error) {
+	if reason := platformMismatch(block); reason != "" {
+		return &Resolution{Skip: true, SkipReason: reason}, nil
 	}
-	if cmd == "" {
-		cmd = r.DefaultCommand
+	if planned, ok := r.Resolutions[index]; ok {
+		res := *planned
+		res.tmpDir = r.TmpDir
+		res.store = r.templateStore(block, index, chunk, chunkIndex)
+		res.store["command"] = res.Command
+		if !res.Skip {
+			if err := r.applyPolicy(block, &res); err != nil {
+				return nil, err
+			}
+		}
+		return &res, nil
 	}
-	if cmd == "" {
+
+	res := &Resolution{tmpDir: r.TmpDir}
+
+	// Determine command to use
+	// (priority: use attribute > block command > language command > language plugin > default command > shebang)
+	plugin, own := r.plugin(block)
+	switch {
+	case own:
+		res.Source, res.Template = useResolution(plugin)
+	case block.Command != "":
+		res.Source, res.Template = SourceBlock, block.Command
+	case r.languageCommand(block) != "":
+		res.Source, res.Template = SourceLanguage, r.languageCommand(block)
+	case plugin != "":
+		res.Source, res.Template = useResolution(plugin)
+	case r.DefaultCommand != "" && r.defaultApplies(block):
+		res.Source, res.Template = SourceDefault, r.DefaultCommand
+	case r.HonorShebang && shebang(block.Content) != "":
+		res.Source, res.Template = SourceShebang, shebang(block.Content)
+	case r.DefaultCommand != "":
+		res.Skip = true
+		res.SkipReason = fmt.Sprintf("default command does not apply to language %q", block.Language)
+		return res, nil
+	default:
 		// No command specified, skip this block
-		return nil
+		res.Skip = true
+		res.SkipReason = "no command specified"
+		return res, nil
 	}
 
 	// Expand template variables
-	store := map[string]any{
+	env := BlockEnv(block)
+	res.store = r.templateStore(block, index, chunk, chunkIndex)
+	if !r.RawContent {
+		if err := checkInterpolation(res.Template, res.store, shell()); err != nil {
+			return nil, err
+		}
+	}
+	expandedCmd, err := expandTemplate(res.Template, res.store, r.trace())
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand template: %w", err)
+	}
+
+	// Skip if expanded command is empty
+	res.Command = strings.TrimSpace(expandedCmd)
+	if res.Command == "" {
+		res.Skip = true
+		res.SkipReason = "command expanded to empty string"
+		return res, nil
+	}
+	res.store["command"] = res.Command
+	res.Dir = WorkDir(r.File, block)
+
+	res.Env = []string{
+		"CODEBLOCK_LANG=" + block.Language,
+		"CODEBLOCK_CONTENT=" + block.Content,
+		fmt.Sprintf("CODEBLOCK_INDEX=%d", index),
+		"CODEBLOCK_COMMAND=" + res.Command,
+		"CODEBLOCK_ID=" + BlockID(r.File, block),
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		res.Env = append(res.Env, k+"="+env[k])
+	}
+
+	if err := r.applyPolicy(block, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Hash returns a hash of the command, the environment and the working directory of the resolution.
+// The environment includes the content of the block, so the hash changes when the block is edited.
+// The temporary directory of the run is replaced with TmpDirPlaceholder, so that blocks using it can be up to date.
+func (res *Resolution) Hash() string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, res.withoutTmpDir(res.Command)) //nostyle:handlerrors
+	for _, e := range res.Env {
+		_, _ = io.WriteString(h, "\x00"+res.withoutTmpDir(e)) //nostyle:handlerrors
+	}
+	if res.Dir != "" {
+		_, _ = io.WriteString(h, "\x00dir="+res.Dir) //nostyle:handlerrors
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// withoutTmpDir returns s with the temporary directory of the run replaced with TmpDirPlaceholder.
+func (res *Resolution) withoutTmpDir(s string) string {
+	if res.tmpDir == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, res.tmpDir, TmpDirPlaceholder)
+}
+
+// templateStore returns the values command templates of a chunk of a code block are expanded with.
+func (r *Runner) templateStore(block parser.CodeBlock, index int, chunk string, chunkIndex int) map[string]any {
+	return map[string]any{
 		"lang":    block.Language,
 		"content": block.Content,
 		"i":       index,
+		"env":     BlockEnv(block),
+		"tmpdir":  r.TmpDir,
+		"chunk":   chunk,
+		"chunk_i": chunkIndex,
+		"heading": block.Heading,
+		"slug":    Slug(block.Heading),
+		"id":      BlockID(r.File, block),
+		"prev":    r.prevStore(),
+		"vars":    r.vars(),
+		"secrets": r.secrets(),
+		"os":      runtime.GOOS,
+		"arch":    runtime.GOARCH,
+	}
+}
+
+// applyPolicy evaluates the policy for a resolved code block and marks the block as skipped if it is denied.
+func (r *Runner) applyPolicy(block parser.CodeBlock, res *Resolution) error {
+	if r.Policy == "" {
+		return nil
 	}
-	expandedCmd, err := ExpandTemplate(cmd, store)
+	allowed, err := r.evalPolicy(block, res)
 	if err != nil {
-		return fmt.Errorf("failed to expand template: %w", err)
+		return err
+	}
+	if !allowed {
+		if r.PolicyAbort {
+			return fmt.Errorf("%w: %s", ErrPolicyDenied, res.Command)
+		}
+		res.Skip = true
+		res.SkipReason = "denied by policy"
 	}
+	return nil
+}
 
-	// Skip if expanded command is empty
-	expandedCmd = strings.TrimSpace(expandedCmd)
-	if expandedCmd == "" {
-		return nil
+// AttrEnv is the attribute listing environment variables of the command separated by commas (e.g., env="FOO=bar,BAZ=qux").
+const AttrEnv = "env"
+
+// BlockEnv returns the environment variables set by the env list and env.NAME=value attributes of a code block.
+// env.NAME attributes take precedence over the list, and entries of the list without '=' are ignored.
+func BlockEnv(block parser.CodeBlock) map[string]string {
+	env := map[string]string{}
+	for _, e := range strings.Split(block.Attributes[AttrEnv], ",") {
+		if name, v, ok := strings.Cut(strings.TrimSpace(e), "="); ok && name != "" {
+			env[name] = v
+		}
 	}
+	for k, v := range block.Attributes {
+		if name, ok := strings.CutPrefix(k, "env."); ok && name != "" {
+			env[name] = v
+		}
+	}
+	return env
+}
 
-	// Build command
-	name, args, err := BuildCommand(expandedCmd)
+// Result is the result of running a code block.
+type Result struct {
+	Index      int              // 0-based index of the code block
+	Block      parser.CodeBlock // The code block
+	Command    string           // Fully expanded command (empty if skipped before expansion)
+	Skipped    bool             // Whether the block was skipped
+	SkipReason string           // Why the block was skipped
+	ExitCode   int              // Exit code of the command (-1 if it did not exit normally)
+	StartedAt  time.Time        // When the command was started
+	Duration   time.Duration    // How long the command ran
+	Stdout     string           // Captured stdout (only if CaptureOutput is set)
+	Stderr     string           // Captured stderr (only if CaptureOutput is set)
+	Artifacts  []string         // Paths of the collected artifacts
+	Hash       string           // Hash of the resolved command and environment (empty if skipped before expansion)
+	Err        error            // Error of the block (nil on success)
+}
+
+// Run executes the command for a code block.
+// index is the 0-based index of the code block.
+func (r *Runner) Run(ctx context.Context, block parser.CodeBlock, index int) error {
+	return r.run(ctx, block, index, 0).Err
+}
+
+// run executes the command for a code block and notifies its result.
+// pause is the time to wait before the command is started if the block is not skipped.
+func (r *Runner) run(ctx context.Context, block parser.CodeBlock, index int, pause time.Duration) *Result {
+	result := r.chain(func(ctx context.Context, block parser.CodeBlock, index int) *Result {
+		result := r.execute(ctx, block, index, pause)
+		if !result.StartedAt.IsZero() {
+			artifacts, err := r.collectArtifacts(block, index)
+			result.Artifacts = artifacts
+			if err != nil {
+				result.Err = errors.Join(result.Err, err)
+			}
+		}
+		return result
+	})(ctx, block, index)
+	if r.OnResult != nil {
+		r.OnResult(result)
+	}
+	return result
+}
+
+// WriterFunc returns the writers for the output of a code block (e.g., separate buffers or files per block).
+type WriterFunc func(block parser.CodeBlock, index int) (stdout, stderr io.Writer)
+
+// EnvFunc returns the environment the process of a code block starts with.
+type EnvFunc func(block parser.CodeBlock, index int) []string
+
+// environ returns the environment the process of a code block starts with,
+// before the variables of the block are added.
+func (r *Runner) environ(block parser.CodeBlock, index int) []string {
+	switch {
+	case r.EnvFunc != nil:
+		return r.EnvFunc(block, index)
+	case r.Env != nil:
+		return r.Env
+	default:
+		return os.Environ()
+	}
+}
+
+// writers returns the writers for the output of a code block.
+func (r *Runner) writers(block parser.CodeBlock, index int) (io.Writer, io.Writer) {
+	if r.WriterFor != nil {
+		return r.WriterFor(block, index)
+	}
+	return r.Stdout, r.Stderr
+}
+
+// execute executes the command for a code block and returns its result.
+func (r *Runner) execute(ctx context.Context, block parser.CodeBlock, index int, pause time.Duration) *Result {
+	result := &Result{Index: index, Block: block, ExitCode: -1}
+	log := r.logger(ctx).With(slog.Int("index", index), slog.String("lang", block.Language), slog.Int("line", block.Line))
+
+	// render-content=true fills the content before anything sees it
+	block, err := r.renderContent(block, index)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	res, err := r.Resolve(block, index)
+	if err != nil {
+		log.DebugContext(ctx, "resolution failed", slog.Any("error", err))
+		result.Err = err
+		return result
+	}
+	log.DebugContext(ctx, "command resolved", slog.String("source", res.Source), slog.String("template", res.Template),
+		slog.Bool("skip", res.Skip), slog.String("skip_reason", res.SkipReason))
+	result.Command = r.Mask(res.Command)
+	if res.Skip {
+		result.Skipped = true
+		result.SkipReason = res.SkipReason
+		return result
+	}
+	log.DebugContext(ctx, "command expanded", slog.String("template", res.Template), slog.String("command", res.Command))
+	result.Hash = res.Hash()
+	if r.UpToDate != nil && r.UpToDate(result) {
+		log.DebugContext(ctx, "block up to date", slog.String("hash", result.Hash))
+		result.Skipped = true
+		result.SkipReason = "up to date"
+		return result
+	}
+
+	nice, err := r.niceness(block)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	// Pause before the block if requested
+	if v, ok := block.Attributes[AttrSleepBefore]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			result.Err = fmt.Errorf("invalid %s: %w", AttrSleepBefore, err)
+			return result
+		}
+		pause = max(pause, d)
+	}
+	if pause > 0 {
+		if err := sleep(ctx, pause); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	// stdin= connects the stdin of the command to a file instead of the content
+	var stdin io.Reader
+	f, err := r.openStdin(block)
 	if err != nil {
-		return fmt.Errorf("failed to build command: %w", err)
+		result.Err = err
+		return result
+	}
+	if f != nil {
+		defer func() { _ = f.Close() }() //nostyle:handlerrors
+		stdin = f
+	}
+	if r.stdin != nil {
+		stdin = r.stdin
+	}
+
+	// A split block runs the command once per chunk with the chunk as its input.
+	// A file or a pipe can be read only once, so it cannot replace the chunks.
+	_, split := block.Attributes[AttrSplit]
+	chunks := []string{block.Content}
+	if split {
+		if stdin != nil {
+			result.Err = fmt.Errorf("%s cannot be used with %s", AttrStdin, AttrSplit)
+			return result
+		}
+		chunks = splitChunks(block)
+		if len(chunks) == 0 {
+			result.Skipped = true
+			result.SkipReason = "no chunks to run"
+			return result
+		}
+	}
+
+	outW, errW := r.writers(block, index)
+
+	// Cap output and suppress binary output
+	outName := "stdout"
+	if r.CombineOutput {
+		outName = "output"
+	}
+	outGuard := newOutputGuard(outW, outName, r.MaxOutput, r.DetectBinary)
+	if outGuard != nil {
+		outW = outGuard
+		defer outGuard.finish()
+	}
+	if errGuard := newOutputGuard(errW, "stderr", r.MaxOutput, r.DetectBinary); errGuard != nil {
+		errW = errGuard
+		defer errGuard.finish()
+	}
+
+	// Capture output for assertions and results while still streaming it
+	var stdout, stderr bytes.Buffer
+	assert := hasAssertions(block)
+	if assert || r.CaptureOutput || (r.last != nil && r.last.capture) {
+		outW = io.MultiWriter(outW, &stdout)
+		errW = io.MultiWriter(errW, &stderr)
+	}
+
+	// Mask secrets in everything written and captured
+	values := r.secretValues()
+	errMask := newMaskWriter(errW, values)
+	if errMask != nil {
+		errW = errMask
+	}
+	var outMask *maskWriter
+	if !r.rawStdout {
+		if outMask = newMaskWriter(outW, values); outMask != nil {
+			outW = outMask
+		}
+	}
+
+	// Wait for the rate limit before the first process; the following chunks wait in the loop
+	if err := r.waitRate(ctx, block); err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.StartedAt = time.Now()
+	if r.OnStart != nil {
+		r.OnStart(result)
+	}
+	var runErr error
+	for i, chunk := range chunks {
+		cres, input := res, chunk
+		if split {
+			cres, err = r.resolve(block, index, chunk, i)
+			if err != nil {
+				runErr = err
+				break
+			}
+			if cres.Skip {
+				continue
+			}
+			input += "\n"
+			if i > 0 {
+				if runErr = r.waitRate(ctx, block); runErr != nil {
+					break
+				}
+			}
+		}
+		if cres.Source == SourcePlugin {
+			if stdin != nil {
+				runErr = fmt.Errorf("%s cannot be used with executor plugins", AttrStdin)
+				break
+			}
+			if input, runErr = r.pluginInput(block, index, chunk); runErr != nil {
+				break
+			}
+		}
+		var exitCode int
+		log.DebugContext(ctx, "exec", slog.String("source", cres.Source), slog.String("command", cres.Command), slog.Int("chunk", i))
+		started := time.Now()
+		if cres.Source == SourceExecutor {
+			if stdin != nil {
+				b, err := io.ReadAll(stdin)
+				if err != nil {
+					runErr = fmt.Errorf("failed to read %s: %w", AttrStdin, err)
+					break
+				}
+				input = string(b)
+			}
+			exitCode, runErr = r.executeWith(ctx, block, index, cres, input, outW, errW)
+		} else {
+			exitCode, runErr = r.process(ctx, cres, input, stdin, r.environ(block, index), nice, outW, errW)
+		}
+		log.DebugContext(ctx, "exit", slog.Int("exit_code", exitCode), slog.Duration("duration", time.Since(started)),
+			slog.Int("chunk", i), slog.Any("error", runErr))
+		result.ExitCode = exitCode
+		if runErr != nil {
+			break
+		}
+	}
+	if outMask != nil {
+		outMask.flush()
+	}
+	if errMask != nil {
+		errMask.flush()
+	}
+	result.Duration = time.Since(result.StartedAt)
+	if r.last != nil {
+		r.last.record(result, stdout.String(), stderr.String())
+	}
+	if r.CaptureOutput {
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+	}
+	if !assert {
+		result.Err = runErr
+		return result
+	}
+
+	// With assert= the expression decides the result, so a non-zero exit code is not an error by itself
+	var exitErr *exec.ExitError
+	if runErr != nil && (!errors.As(runErr, &exitErr) || block.Attributes[AttrAssert] == "") {
+		result.Err = runErr
+		return result
+	}
+	result.Err = r.checkAssertions(block, res.store, stdout.String(), stderr.String(), result.ExitCode)
+	return result
+}
+
+// process runs the resolved command with input (or stdin if it is not nil) as its stdin and environ as the base of
+// its environment, and returns its exit code (-1 if it did not exit normally). A shebang script is always input.
+func (r *Runner) process(ctx context.Context, res *Resolution, input string, stdin io.Reader, environ []string, nice int, outW, errW io.Writer) (int, error) {
+	// Build command
+	var name string
+	var args []string
+	var err error
+	if res.Source == SourceShebang {
+		// The script is executed directly so that the kernel honors its shebang
+		name, err = r.writeScript(input)
+		if err != nil {
+			return -1, err
+		}
+		defer func() { _ = os.Remove(name) }() //nostyle:handlerrors
+	} else if res.Source == SourcePlugin {
+		// Executor plugins are executed directly and read the block descriptor from stdin
+		name, err = lookPlugin(res.Command)
+		if err != nil {
+			return -1, err
+		}
+	} else {
+		name, args, err = BuildCommand(res.Command)
+		if err != nil {
+			return -1, fmt.Errorf("failed to build command: %w", err)
+		}
 	}
 
 	// Execute command
 	execCmd := exec.CommandContext(ctx, name, args...)
-	execCmd.Stdin = strings.NewReader(block.Content)
-	execCmd.Stdout = r.Stdout
-	execCmd.Stderr = r.Stderr
+	execCmd.Dir = res.Dir
+	execCmd.Stdin = strings.NewReader(input)
+	if stdin != nil {
+		execCmd.Stdin = stdin
+	}
+
+	// Passing the same writer for both streams keeps their order
+	execCmd.Stdout = outW
+	execCmd.Stderr = errW
+	if r.CombineOutput {
+		execCmd.Stderr = outW
+	}
 
 	// Set environment variables
-	execCmd.Env = append(os.Environ(),
-		"CODEBLOCK_LANG="+block.Language,
-		"CODEBLOCK_CONTENT="+block.Content,
-		fmt.Sprintf("CODEBLOCK_INDEX=%d", index),
-	)
+	// The temporary directory differs in every run, so CODEBLOCK_TMPDIR is not a part of the resolution
+	// (and Hash replaces the directory in the command)
+	env := res.Env
+	allowWrite := r.AllowWrite
+	if r.TmpDir != "" {
+		env = append(slices.Clone(env), "CODEBLOCK_TMPDIR="+r.TmpDir)
+		allowWrite = append(slices.Clone(allowWrite), r.TmpDir)
+	}
+	execCmd.Env = append(slices.Clone(environ), env...)
+
+	if r.User != "" {
+		if err := runAsUser(execCmd, r.User, env); err != nil {
+			return -1, fmt.Errorf("failed to run as user %q: %w", r.User, err)
+		}
+	}
+	if r.ReadOnly {
+		if err := sandbox.ReadOnly(execCmd, allowWrite); err != nil {
+			return -1, fmt.Errorf("failed to sandbox: %w", err)
+		}
+	}
 
-	return execCmd.Run()
+	prepareProcess(execCmd)
+	err = start(execCmd, nice)
+	if err == nil {
+		err = execCmd.Wait()
+	}
+	exitCode := -1
+	if execCmd.ProcessState != nil {
+		exitCode = execCmd.ProcessState.ExitCode()
+	}
+	return exitCode, err
 }
 
 // RunAll executes commands for all code blocks.
+// Setup blocks are run first and teardown blocks last.
+// It stops at the first failure unless KeepGoing is set, in which case all failures are joined.
+// After a failure or cancellation of ctx, only the blocks that always run (teardown blocks and always=true) are run.
+// Blocks of languages in Parallel run concurrently (see runDeferred); the others run alone.
+// A block with the pipe-to attribute runs together with the block receiving its stdout (see runPipe).
+// A failure stopping the run cancels the concurrent blocks in flight unless KeepSiblings is set.
+// Their output is still shown, but their errors are not returned.
 func (r *Runner) RunAll(ctx context.Context, blocks []parser.CodeBlock) error {
-	for i, block := range blocks {
-		if err := r.Run(ctx, block, i); err != nil {
-			return fmt.Errorf("failed to execute code block %d: %w", i+1, err)
+	order, err := RunOrder(blocks)
+	if err != nil {
+		return err
+	}
+	pipes, err := PipeTargets(blocks)
+	if err != nil {
+		return err
+	}
+	selected := func(i int) bool {
+		return r.Select == nil || r.Select(blocks[i], i)
+	}
+	if err := r.checkRequirements(ctx, blocks, selected); err != nil {
+		return err
+	}
+	piped := map[int]bool{} // Receiving blocks that have run with their senders
+	r.last = newLastResult(r, blocks)
+	parCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var (
+		mu      sync.Mutex // Guards errs and stopped, and serializes deferred output and hooks
+		wg      sync.WaitGroup
+		errs    []error
+		stopped bool
+	)
+	record := func(i int, result *Result) {
+		if result.Err == nil {
+			return
+		}
+		errs = append(errs, fmt.Errorf("failed to execute code block %d: %w", i+1, result.Err))
+		if !r.KeepGoing || ctx.Err() != nil {
+			stopped = true
+			if !r.KeepSiblings {
+				cancel(errSiblingFailed)
+			}
 		}
 	}
-	return nil
+
+	sems := map[string]chan struct{}{}
+	locks := &mutexes{}
+	executed := false
+	stage := ""
+	runCtx := parCtx
+	for _, i := range order {
+		block := blocks[i]
+		if !selected(i) || piped[i] {
+			continue
+		}
+		// Stages do not overlap
+		if s, _ := BlockStage(block); s != stage { //nostyle:handlerrors
+			wg.Wait()
+			stage = s
+		}
+		group, n := r.parallelGroup(block)
+		j, pipe := pipes[i]
+		pipe = pipe && selected(j)
+		if pipe {
+			// A pipeline runs alone
+			n = 0
+		}
+		if n == 0 {
+			// A block running alone waits for the concurrent blocks, which may stop the run
+			wg.Wait()
+		}
+		mu.Lock()
+		stop := stopped
+		mu.Unlock()
+		if stop || ctx.Err() != nil {
+			if always, _ := RunsAlways(block); !always { //nostyle:handlerrors
+				continue
+			}
+			// Run cleanup even if the run has been interrupted
+			runCtx = context.WithoutCancel(ctx)
+		}
+
+		if n > 0 {
+			sem, ok := sems[group]
+			if !ok {
+				sem = make(chan struct{}, n)
+				sems[group] = sem
+			}
+			sem <- struct{}{}
+			executed = true
+			wg.Add(1)
+			go func(ctx context.Context) {
+				defer wg.Done()
+				unlock := locks.lock(block)
+				result := r.runDeferred(ctx, block, i, &mu)
+				unlock()
+				mu.Lock()
+				if !errors.Is(context.Cause(ctx), errSiblingFailed) {
+					record(i, result)
+				}
+				mu.Unlock()
+				<-sem
+			}(runCtx)
+			continue
+		}
+
+		// Pause between block executions
+		var pause time.Duration
+		if executed {
+			pause = r.Interval
+		}
+		if pipe {
+			src, dst := r.runPipe(runCtx, blocks, i, j, pause)
+			piped[j] = true
+			executed = executed || !src.Skipped || !dst.Skipped
+			mu.Lock()
+			record(i, src)
+			record(j, dst)
+			mu.Unlock()
+			continue
+		}
+		result := r.run(runCtx, block, i, pause)
+		executed = executed || !result.Skipped
+		mu.Lock()
+		record(i, result)
+		mu.Unlock()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil && len(errs) == 0 {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// errSiblingFailed is the cause of canceling the concurrent blocks in flight when a block fails.
+var errSiblingFailed = errors.New("canceled by the failure of another block")
+
+// sleep pauses for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
 
 // celExprReg is a regular expression to match {{expression}} patterns.
@@ -124,6 +840,11 @@ var celExprReg = regexp.MustCompile(`\{\{([^}]+)\}\}`)
 // ExpandTemplate expands template expressions in the format {{CEL expression}} with values from the store.
 // It supports CEL (Common Expression Language) expressions within the template.
 func ExpandTemplate(template string, store map[string]any) (string, error) {
+	return expandTemplate(template, store, nil)
+}
+
+// expandTemplate expands template expressions and logs each evaluation to trace if it is not nil.
+func expandTemplate(template string, store map[string]any, trace io.Writer) (string, error) {
 	// Create CEL environment with store variables
 	env, err := createCELEnv(store)
 	if err != nil {
@@ -136,26 +857,18 @@ func ExpandTemplate(template string, store map[string]any) (string, error) {
 		expr := strings.TrimSpace(match[2 : len(match)-2])
 
 		// Compile and evaluate CEL expression
-		ast, issues := env.Compile(expr)
-		if issues != nil && issues.Err() != nil {
-			expandErr = fmt.Errorf("template compilation error for '{{%s}}': %w", expr, issues.Err())
-			return match // Return original match on error
-		}
-
-		prg, err := env.Program(ast)
+		out, err := evalExpr(env, expr, store)
 		if err != nil {
-			expandErr = fmt.Errorf("template program creation error for '{{%s}}': %w", expr, err)
-			return match // Return original match on error
-		}
-
-		out, _, err := prg.Eval(store)
-		if err != nil {
-			expandErr = fmt.Errorf("template evaluation error for '{{%s}}': %w", expr, err)
+			expandErr = err
 			return match // Return original match on error
 		}
 
 		// Convert result to string
-		return fmt.Sprintf("%v", out.Value())
+		result := templateString(out)
+		if trace != nil {
+			traceEval(trace, expr, store, result)
+		}
+		return result
 	})
 
 	if expandErr != nil {
@@ -165,9 +878,76 @@ func ExpandTemplate(template string, store map[string]any) (string, error) {
 	return result, nil
 }
 
+// evalExpr compiles and evaluates a CEL expression with values from the store.
+func evalExpr(env *cel.Env, expr string, store map[string]any) (any, error) {
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("template compilation error for '{{%s}}': %w", expr, issues.Err())
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("template program creation error for '{{%s}}': %w", expr, err)
+	}
+
+	out, _, err := prg.Eval(store)
+	if err != nil {
+		return nil, fmt.Errorf("template evaluation error for '{{%s}}': %w", expr, err)
+	}
+
+	return out.Value(), nil
+}
+
+// evalBool evaluates a CEL expression that must produce a bool.
+func evalBool(expr string, store map[string]any, trace io.Writer) (bool, error) {
+	env, err := createCELEnv(store)
+	if err != nil {
+		return false, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	out, err := evalExpr(env, expr, store)
+	if err != nil {
+		return false, err
+	}
+	if trace != nil {
+		traceEval(trace, expr, store, fmt.Sprintf("%v", out))
+	}
+	b, ok := out.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression must evaluate to a bool, got %T: %s", out, expr)
+	}
+	return b, nil
+}
+
+// traceEval writes a CEL evaluation with the store values it saw and the produced result.
+func traceEval(w io.Writer, expr string, store map[string]any, result string) {
+	keys := make([]string, 0, len(store))
+	for k := range store {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(w, "[trace] {{%s}}\n", expr)
+	for _, k := range keys {
+		v := store[k]
+		if secrets, ok := v.(map[string]string); ok && k == "secrets" {
+			v = redactSecrets(secrets)
+		}
+		fmt.Fprintf(w, "[trace]   %s = %#v\n", k, v)
+	}
+	fmt.Fprintf(w, "[trace]   => %q\n", result)
+}
+
+// redactSecrets returns the secrets with their values replaced with ***.
+func redactSecrets(secrets map[string]string) map[string]string {
+	redacted := make(map[string]string, len(secrets))
+	for name := range secrets {
+		redacted[name] = secretMask
+	}
+	return redacted
+}
+
 // createCELEnv creates a CEL environment with all variables from the store.
 func createCELEnv(store map[string]any) (*cel.Env, error) {
-	var options []cel.EnvOption
+	options := celFunctions()
 
 	// Add each top-level store key as a CEL variable
 	for key, value := range store {
@@ -215,14 +995,24 @@ func BuildCommand(c string) (string, []string, error) {
 	}
 
 	// Wrap in shell
+	sh := shell()
 	if runtime.GOOS == "windows" {
-		return "cmd", []string{"/c", c}, nil
+		if sh == "cmd" {
+			return sh, []string{"/c", c}, nil
+		}
+		return sh, []string{"-NoProfile", "-NonInteractive", "-Command", c}, nil
 	}
-
-	sh := detectShell()
 	return sh, []string{"-c", c}, nil
 }
 
+// shell returns the shell commands with arguments are wrapped in.
+func shell() string {
+	if runtime.GOOS == "windows" {
+		return detectWindowsShell()
+	}
+	return detectShell()
+}
+
 // detectShell detects the shell to use for command execution.
 func detectShell() string {
 	sh := os.Getenv("SHELL")
@@ -232,3 +1022,12 @@ func detectShell() string {
 	// Fallback to sh
 	return "/bin/sh"
 }
+
+// detectWindowsShell detects the shell to use for command execution on Windows.
+// PowerShell (pwsh) is preferred over cmd.
+func detectWindowsShell() string {
+	if _, err := exec.LookPath("pwsh"); err == nil {
+		return "pwsh"
+	}
+	return "cmd"
+}
@@ -24,12 +24,21 @@ package runner
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
 	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/k1LoW/runblock/parser"
+	"github.com/k1LoW/runblock/sandbox"
 )
 
 func TestExpandTemplate_Simple(t *testing.T) {
@@ -191,7 +200,7 @@ func TestBuildCommand(t *testing.T) {
 			cmd:      "echo hello",
 			wantName: func() string {
 				if runtime.GOOS == "windows" {
-					return "cmd"
+					return detectWindowsShell()
 				}
 				sh := os.Getenv("SHELL")
 				if sh != "" {
@@ -201,7 +210,10 @@ func TestBuildCommand(t *testing.T) {
 			}(),
 			wantArgs: func() []string {
 				if runtime.GOOS == "windows" {
-					return []string{"/c", "echo hello"}
+					if detectWindowsShell() == "cmd" {
+						return []string{"/c", "echo hello"}
+					}
+					return []string{"-NoProfile", "-NonInteractive", "-Command", "echo hello"}
 				}
 				return []string{"-c", "echo hello"}
 			}(),
@@ -212,7 +224,7 @@ func TestBuildCommand(t *testing.T) {
 			cmd:      "cat | grep test",
 			wantName: func() string {
 				if runtime.GOOS == "windows" {
-					return "cmd"
+					return detectWindowsShell()
 				}
 				sh := os.Getenv("SHELL")
 				if sh != "" {
@@ -222,7 +234,10 @@ func TestBuildCommand(t *testing.T) {
 			}(),
 			wantArgs: func() []string {
 				if runtime.GOOS == "windows" {
-					return []string{"/c", "cat | grep test"}
+					if detectWindowsShell() == "cmd" {
+						return []string{"/c", "cat | grep test"}
+					}
+					return []string{"-NoProfile", "-NonInteractive", "-Command", "cat | grep test"}
 				}
 				return []string{"-c", "cat | grep test"}
 			}(),
@@ -254,10 +269,6 @@ func TestBuildCommand(t *testing.T) {
 }
 
 func TestRun_BasicExecution(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
 		DefaultCommand: "",
@@ -267,7 +278,7 @@ func TestRun_BasicExecution(t *testing.T) {
 
 	block := parser.CodeBlock{
 		Language: "sh",
-		Command:  "cat",
+		Command:  catCommand(t),
 		Content:  "hello world",
 	}
 
@@ -282,10 +293,6 @@ func TestRun_BasicExecution(t *testing.T) {
 }
 
 func TestRun_WithTemplateVariables(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
 		DefaultCommand: "",
@@ -311,10 +318,6 @@ func TestRun_WithTemplateVariables(t *testing.T) {
 }
 
 func TestRun_WithIndex(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
 		DefaultCommand: "",
@@ -341,10 +344,6 @@ func TestRun_WithIndex(t *testing.T) {
 }
 
 func TestRun_WithIndexEnvVar(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
 		DefaultCommand: "",
@@ -354,7 +353,7 @@ func TestRun_WithIndexEnvVar(t *testing.T) {
 
 	block := parser.CodeBlock{
 		Language: "go",
-		Command:  "sh -c 'echo $CODEBLOCK_INDEX'",
+		Command:  echoEnvCommand(t, "CODEBLOCK_INDEX"),
 		Content:  "package main",
 	}
 
@@ -369,11 +368,32 @@ func TestRun_WithIndexEnvVar(t *testing.T) {
 	}
 }
 
-func TestRun_StdinContent(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
+func TestRun_CommandEnvVar(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		DefaultCommand: echoEnvCommand(t, "CODEBLOCK_COMMAND") + " # {{lang}}",
+		Stdout:         &stdout,
+		Stderr:         &stderr,
+	}
+
+	block := parser.CodeBlock{
+		Language:   "go",
+		Content:    "package main",
+		Attributes: map[string]string{"assert": `command.endsWith(" go")`},
 	}
 
+	err := r.Run(context.Background(), block, 0)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := echoEnvCommand(t, "CODEBLOCK_COMMAND") + " # go"
+	if got := strings.TrimSpace(stdout.String()); got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestRun_StdinContent(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
 		DefaultCommand: "",
@@ -383,7 +403,7 @@ func TestRun_StdinContent(t *testing.T) {
 
 	block := parser.CodeBlock{
 		Language: "text",
-		Command:  "cat",
+		Command:  catCommand(t),
 		Content:  "line1\nline2\nline3",
 	}
 
@@ -399,10 +419,6 @@ func TestRun_StdinContent(t *testing.T) {
 }
 
 func TestRun_EnvironmentVariables(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
 		DefaultCommand: "",
@@ -412,7 +428,7 @@ func TestRun_EnvironmentVariables(t *testing.T) {
 
 	block := parser.CodeBlock{
 		Language: "go",
-		Command:  "sh -c 'echo $CODEBLOCK_LANG'",
+		Command:  echoEnvCommand(t, "CODEBLOCK_LANG"),
 		Content:  "package main",
 	}
 
@@ -428,13 +444,9 @@ func TestRun_EnvironmentVariables(t *testing.T) {
 }
 
 func TestRun_DefaultCommand(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
-		DefaultCommand: "cat",
+		DefaultCommand: catCommand(t),
 		Stdout:         &stdout,
 		Stderr:         &stderr,
 	}
@@ -508,13 +520,9 @@ func TestRun_SkipOnEmptyExpandedCommand(t *testing.T) {
 }
 
 func TestRun_ExecuteOnNonEmptyExpandedCommand(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
-		DefaultCommand: `{{ lang == "go" ? "cat" : "" }}`,
+		DefaultCommand: fmt.Sprintf(`{{ lang == "go" ? %q : "" }}`, catCommand(t)),
 		Stdout:         &stdout,
 		Stderr:         &stderr,
 	}
@@ -538,13 +546,9 @@ func TestRun_ExecuteOnNonEmptyExpandedCommand(t *testing.T) {
 }
 
 func TestRunAll(t *testing.T) {
-	if runtime.GOOS == "windows" {
-		t.Skip("skipping test on Windows")
-	}
-
 	var stdout, stderr bytes.Buffer
 	r := &Runner{
-		DefaultCommand: "cat",
+		DefaultCommand: catCommand(t),
 		Stdout:         &stdout,
 		Stderr:         &stderr,
 	}
@@ -572,3 +576,849 @@ func TestRunAll(t *testing.T) {
 		t.Errorf("stdout = %q, want %q", got, want)
 	}
 }
+
+func TestResolve(t *testing.T) {
+	r := &Runner{
+		DefaultCommand: `{{ lang == "sh" ? "sh" : "" }}`,
+		Commands:       map[string]string{"go": "gofmt"},
+	}
+
+	tests := []struct {
+		name        string
+		block       parser.CodeBlock
+		wantSource  string
+		wantCommand string
+		wantSkip    bool
+	}{
+		{
+			name:        "info string",
+			block:       parser.CodeBlock{Language: "go", Command: "echo {{i}}"},
+			wantSource:  SourceBlock,
+			wantCommand: "echo 2",
+		},
+		{
+			name:        "heading slug",
+			block:       parser.CodeBlock{Language: "go", Command: "tee {{slug}}.out # {{heading}}", Heading: "Build the App"},
+			wantSource:  SourceBlock,
+			wantCommand: "tee build-the-app.out # Build the App",
+		},
+		{
+			name:        "language map",
+			block:       parser.CodeBlock{Language: "go"},
+			wantSource:  SourceLanguage,
+			wantCommand: "gofmt",
+		},
+		{
+			name:        "default",
+			block:       parser.CodeBlock{Language: "sh"},
+			wantSource:  SourceDefault,
+			wantCommand: "sh",
+		},
+		{
+			name:       "expanded to empty",
+			block:      parser.CodeBlock{Language: "text"},
+			wantSource: SourceDefault,
+			wantSkip:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := r.Resolve(tt.block, 2)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if res.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", res.Source, tt.wantSource)
+			}
+			if res.Command != tt.wantCommand {
+				t.Errorf("Command = %q, want %q", res.Command, tt.wantCommand)
+			}
+			if res.Skip != tt.wantSkip {
+				t.Errorf("Skip = %v, want %v", res.Skip, tt.wantSkip)
+			}
+		})
+	}
+}
+
+func TestResolve_Trace(t *testing.T) {
+	var trace bytes.Buffer
+	r := &Runner{
+		DefaultCommand: `echo {{ lang == "" ? "txt" : lang }}`,
+		Trace:          &trace,
+	}
+
+	if _, err := r.Resolve(parser.CodeBlock{Language: "go", Content: "x"}, 1); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	got := trace.String()
+	for _, want := range []string{
+		`[trace] {{lang == "" ? "txt" : lang}}`,
+		`[trace]   lang = "go"`,
+		`[trace]   i = 1`,
+		`[trace]   => "go"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trace does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestBlockEnv(t *testing.T) {
+	block := parser.CodeBlock{Attributes: map[string]string{
+		"env":     "FOO=foo, BAR=a=b,INVALID,=x",
+		"env.BAZ": "baz",
+		"env.FOO": "override",
+	}}
+	want := map[string]string{"FOO": "override", "BAR": "a=b", "BAZ": "baz"}
+	if got := BlockEnv(block); !maps.Equal(got, want) {
+		t.Errorf("BlockEnv() = %v, want %v", got, want)
+	}
+}
+
+func TestResolve_EnvList(t *testing.T) {
+	block := parser.CodeBlock{
+		Language:   "sh",
+		Command:    "echo {{ env.REGION }}",
+		Attributes: map[string]string{"env": "REGION=eu-west-1,STAGE=prod"},
+	}
+	res, err := (&Runner{}).Resolve(block, 0)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if want := "echo eu-west-1"; res.Command != want {
+		t.Errorf("Command = %q, want %q", res.Command, want)
+	}
+	for _, want := range []string{"REGION=eu-west-1", "STAGE=prod"} {
+		if !slices.Contains(res.Env, want) {
+			t.Errorf("Env = %q, want it to contain %q", res.Env, want)
+		}
+	}
+}
+
+func TestRun_EnvAttributes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+
+	block := parser.CodeBlock{
+		Language:   "sh",
+		Command:    `sh -c 'echo $FOO {{ env.BAR }}'`,
+		Attributes: map[string]string{"env.FOO": "foo", "env.BAR": "bar", "name": "x"},
+	}
+
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := strings.TrimSpace(stdout.String())
+	if got != "foo bar" {
+		t.Errorf("stdout = %q, want %q", got, "foo bar")
+	}
+}
+
+func TestRunAll_Interval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+		Interval: 100 * time.Millisecond,
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "text", Content: "skipped"},
+		{Language: "sh", Command: "cat", Content: "a"},
+		{Language: "text", Content: "skipped"},
+		{Language: "sh", Command: "cat", Content: "b", Attributes: map[string]string{AttrSleepBefore: "200ms"}},
+		{Language: "sh", Command: "cat", Content: "c"},
+	}
+
+	start := time.Now()
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	elapsed := time.Since(start)
+
+	// 200ms before b (sleep-before wins over the interval) and 100ms before c
+	if elapsed < 300*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least 300ms", elapsed)
+	}
+	if got := stdout.String(); got != "abc" {
+		t.Errorf("stdout = %q, want %q", got, "abc")
+	}
+}
+
+func TestRun_InvalidSleepBefore(t *testing.T) {
+	r := &Runner{}
+	block := parser.CodeBlock{Language: "sh", Command: "true", Attributes: map[string]string{AttrSleepBefore: "soon"}}
+	if err := r.Run(context.Background(), block, 0); err == nil {
+		t.Error("Run() should return error for invalid sleep-before")
+	}
+}
+
+func TestRunAll_KeepGoing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "exit 1"},
+		{Language: "sh", Command: "echo second"},
+		{Language: "sh", Command: "exit 2"},
+	}
+
+	tests := []struct {
+		name       string
+		keepGoing  bool
+		wantStdout string
+	}{
+		{"stop at first failure", false, ""},
+		{"keep going", true, "second\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr, KeepGoing: tt.keepGoing}
+			err := r.RunAll(context.Background(), blocks)
+			if err == nil {
+				t.Fatal("RunAll() should return error")
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+			if tt.keepGoing && !strings.Contains(err.Error(), "code block 3") {
+				t.Errorf("error does not contain all failures: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunAll_Select(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Select: func(block parser.CodeBlock, _ int) bool {
+			return block.Name() == "b"
+		},
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo a {{i}}", Attributes: map[string]string{"name": "a"}},
+		{Language: "sh", Command: "echo b {{i}}", Attributes: map[string]string{"name": "b"}},
+	}
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	// The index of the block in the document is kept
+	if got := stdout.String(); got != "b 1\n" {
+		t.Errorf("stdout = %q, want %q", got, "b 1\n")
+	}
+}
+
+func TestRunAll_Stage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	tests := []struct {
+		name       string
+		blocks     []parser.CodeBlock
+		cancel     bool
+		wantStdout string
+		wantErr    bool
+	}{
+		{
+			name: "setup first and teardown last",
+			blocks: []parser.CodeBlock{
+				{Language: "sh", Command: "echo teardown", Attributes: map[string]string{"stage": "teardown"}},
+				{Language: "sh", Command: "echo main"},
+				{Language: "sh", Command: "echo setup", Attributes: map[string]string{"stage": "setup"}},
+			},
+			wantStdout: "setup\nmain\nteardown\n",
+		},
+		{
+			name: "teardown and always run after failure",
+			blocks: []parser.CodeBlock{
+				{Language: "sh", Command: "exit 1"},
+				{Language: "sh", Command: "echo skipped"},
+				{Language: "sh", Command: "echo always", Attributes: map[string]string{"always": "true"}},
+				{Language: "sh", Command: "echo teardown", Attributes: map[string]string{"stage": "teardown"}},
+			},
+			wantStdout: "always\nteardown\n",
+			wantErr:    true,
+		},
+		{
+			name: "teardown runs after interrupt",
+			blocks: []parser.CodeBlock{
+				{Language: "sh", Command: "echo main"},
+				{Language: "sh", Command: "echo teardown", Attributes: map[string]string{"stage": "teardown"}},
+			},
+			cancel:     true,
+			wantStdout: "teardown\n",
+			wantErr:    true,
+		},
+		{
+			name: "always=false teardown",
+			blocks: []parser.CodeBlock{
+				{Language: "sh", Command: "exit 1"},
+				{Language: "sh", Command: "echo teardown", Attributes: map[string]string{"stage": "teardown", "always": "false"}},
+			},
+			wantStdout: "",
+			wantErr:    true,
+		},
+		{
+			name: "invalid stage",
+			blocks: []parser.CodeBlock{
+				{Language: "sh", Command: "echo main", Attributes: map[string]string{"stage": "cleanup"}},
+			},
+			wantStdout: "",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr}
+			err := r.RunAll(ctx, tt.blocks)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func TestRun_Nice(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("skipping test on non-Linux")
+	}
+	tests := []struct {
+		name    string
+		nice    int
+		attr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "flag", nice: 5, want: "5\n"},
+		{name: "attribute overrides flag", nice: 5, attr: "10", want: "10\n"},
+		{name: "invalid attribute", attr: "low", wantErr: true},
+		{name: "out of range", nice: 20, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr, Nice: tt.nice}
+			block := parser.CodeBlock{Language: "sh", Command: "sh -c 'cut -d \" \" -f 19 /proc/$$/stat'"}
+			if tt.attr != "" {
+				block.Attributes = map[string]string{AttrNice: tt.attr}
+			}
+			err := r.Run(context.Background(), block, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("niceness = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_User(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() != 0 {
+		t.Skip("skipping test requiring root")
+	}
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr, User: "nobody"}
+	block := parser.CodeBlock{Language: "sh", Command: "sh -c 'id -un; echo $USER $CODEBLOCK_LANG'"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v: %s", err, stderr.String())
+	}
+	if got, want := stdout.String(), "nobody\nnobody sh\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	r.User = "runblock-no-such-user"
+	if err := r.Run(context.Background(), block, 0); err == nil {
+		t.Error("Run() should return error for an unknown user")
+	}
+}
+
+func TestRun_ReadOnly(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("skipping test on non-Linux")
+	}
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr, ReadOnly: true, AllowWrite: []string{filepath.Join(dir, "out")}}
+	if err := os.Mkdir(filepath.Join(dir, "out"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	block := parser.CodeBlock{Language: "sh", Command: "sh -c 'echo ok > out/a && echo ng > b'"}
+	t.Chdir(dir)
+	err := r.Run(context.Background(), block, 0)
+	if errors.Is(err, sandbox.ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err == nil {
+		t.Error("Run() should return error when writing outside of the allowed paths")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "a")); err != nil {
+		t.Errorf("allowed write failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+		t.Errorf("denied write succeeded: %v", err)
+	}
+}
+
+func TestRun_TmpDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr, TmpDir: dir}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "sh -c 'echo hello > {{tmpdir}}/x'"},
+		{Language: "sh", Command: "sh", Content: "cat \"$CODEBLOCK_TMPDIR/x\"\n"},
+	}
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if got := stdout.String(); got != "hello\n" {
+		t.Errorf("stdout = %q, want %q", got, "hello\n")
+	}
+
+	// The temporary directory does not change the hash of the resolution,
+	// even if the command uses {{tmpdir}}
+	for i, block := range blocks {
+		r.TmpDir = t.TempDir()
+		res, err := r.Resolve(block, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.TmpDir = t.TempDir()
+		res2, err := r.Resolve(block, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Hash() != res2.Hash() {
+			t.Errorf("block %d: hash should not depend on the temporary directory", i+1)
+		}
+	}
+}
+
+func TestRun_HonorShebang(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	block := parser.CodeBlock{
+		Language: "text",
+		Content:  "#!/bin/sh -e\necho \"hello from $0\" | sed 's|/.*/||'\n",
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr, TmpDir: t.TempDir()}
+	res, err := r.Resolve(block, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Skip {
+		t.Error("shebang should not be honored by default")
+	}
+
+	r.HonorShebang = true
+	res, err = r.Resolve(block, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Source != SourceShebang || res.Command != "/bin/sh -e" {
+		t.Errorf("resolution = %+v", res)
+	}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := stdout.String(); !strings.HasPrefix(got, "hello from runblock-script-") {
+		t.Errorf("stdout = %q", got)
+	}
+	entries, err := os.ReadDir(r.TmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("script is not removed: %v", entries)
+	}
+}
+
+func TestRun_Split(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	tests := []struct {
+		name       string
+		block      parser.CodeBlock
+		wantStdout string
+		wantErr    bool
+	}{
+		{
+			name: "chunks",
+			block: parser.CodeBlock{
+				Language:   "yaml",
+				Command:    "sh -c 'echo {{chunk_i}}: $(cat)'",
+				Content:    "a: 1\n---\nb: 2\n---\n",
+				Attributes: map[string]string{"split": "---"},
+			},
+			wantStdout: "0: a: 1\n1: b: 2\n",
+		},
+		{
+			name: "chunk variable",
+			block: parser.CodeBlock{
+				Language:   "sql",
+				Command:    "echo {{ shquote(chunk) }}",
+				Content:    "SELECT 1;\nSELECT 2;\n",
+				Attributes: map[string]string{"split": ";"},
+			},
+			wantStdout: "SELECT 1\nSELECT 2\n",
+		},
+		{
+			name: "stops at failing chunk",
+			block: parser.CodeBlock{
+				Language:   "sh",
+				Command:    "sh",
+				Content:    "echo 1\n\nexit 3\n\necho 2\n",
+				Attributes: map[string]string{"split": ""},
+			},
+			wantStdout: "1\n",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr}
+			err := r.Run(context.Background(), tt.block, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func TestRunAll_Parallel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo first"},
+		{Language: "go", Command: "sh -c 'sleep 0.3; echo a1; echo a2'"},
+		{Language: "go", Command: "sh -c 'sleep 0.3; echo b1; echo b2'"},
+		{Language: "go", Command: "sh -c 'sleep 0.3; echo c1; echo c2'"},
+		{Language: "sh", Command: "echo last"},
+	}
+	var stdout, stderr bytes.Buffer
+	var started, finished int
+	r := &Runner{
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+		Parallel: map[string]int{"go": 3},
+		OnStart:  func(*Result) { started++ },
+		OnResult: func(*Result) { finished++ },
+	}
+	begin := time.Now()
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 800*time.Millisecond {
+		t.Errorf("go blocks did not run concurrently: %s", elapsed)
+	}
+
+	got := stdout.String()
+	if !strings.HasPrefix(got, "first\n") || !strings.HasSuffix(got, "last\n") {
+		t.Errorf("serial blocks are out of order: %q", got)
+	}
+	for _, want := range []string{"a1\na2\n", "b1\nb2\n", "c1\nc2\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output of a block is interleaved: %q", got)
+		}
+	}
+	if started != len(blocks) || finished != len(blocks) {
+		t.Errorf("hooks called %d/%d times, want %d", started, finished, len(blocks))
+	}
+}
+
+func TestRunAll_ParallelCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "go", Command: "sleep 2"},
+		{Language: "go", Command: "sleep 0.1; exit 1"},
+		{Language: "go", Command: "sleep 2"},
+		{Language: "sh", Command: "echo not run"},
+	}
+	tests := []struct {
+		keepSiblings bool
+		wantSlow     bool
+	}{
+		{false, false},
+		{true, true},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		r := &Runner{
+			Stdout:       &stdout,
+			Stderr:       &stderr,
+			Parallel:     map[string]int{"go": 3},
+			KeepSiblings: tt.keepSiblings,
+		}
+		begin := time.Now()
+		err := r.RunAll(context.Background(), blocks)
+		if err == nil {
+			t.Fatal("RunAll() should return error")
+		}
+		if slow := time.Since(begin) > 1500*time.Millisecond; slow != tt.wantSlow {
+			t.Errorf("KeepSiblings = %v: took %s", tt.keepSiblings, time.Since(begin))
+		}
+		if got := strings.Count(err.Error(), "failed to execute code block"); got != 1 {
+			t.Errorf("KeepSiblings = %v: errors of canceled blocks should not be returned: %v", tt.keepSiblings, err)
+		}
+		if strings.Contains(stdout.String(), "not run") {
+			t.Errorf("KeepSiblings = %v: blocks after the failure should not run", tt.keepSiblings)
+		}
+	}
+}
+
+func TestRunAll_WriterFor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	var stdout, stderr bytes.Buffer
+	outs := map[int]*bytes.Buffer{}
+	var mu sync.Mutex
+	r := &Runner{
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+		Parallel: map[string]int{"par": 2},
+		WriterFor: func(block parser.CodeBlock, index int) (io.Writer, io.Writer) {
+			mu.Lock()
+			defer mu.Unlock()
+			outs[index] = &bytes.Buffer{}
+			return outs[index], outs[index]
+		},
+	}
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo one"},
+		{Language: "par", Command: "echo two; echo err >&2"},
+		{Language: "par", Command: "echo three"},
+	}
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	for i, want := range []string{"one\n", "two\nerr\n", "three\n"} {
+		if got := outs[i].String(); got != want {
+			t.Errorf("output of block %d = %q, want %q", i, got, want)
+		}
+	}
+	if stdout.Len() > 0 || stderr.Len() > 0 {
+		t.Errorf("Stdout and Stderr should not be written: %q %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestRun_Env(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Setenv("RUNBLOCK_TEST_INHERITED", "inherited")
+
+	block := parser.CodeBlock{Language: "sh", Command: `echo "${RUNBLOCK_TEST_INHERITED:-none} ${ONLY:-none} $CODEBLOCK_INDEX"`}
+	tests := []struct {
+		name    string
+		env     []string
+		envFunc EnvFunc
+		want    string
+	}{
+		{"inherit", nil, nil, "inherited none 1\n"},
+		{"env", []string{"ONLY=env"}, nil, "none env 1\n"},
+		{"empty env", []string{}, nil, "none none 1\n"},
+		{"env func", []string{"ONLY=env"}, func(block parser.CodeBlock, index int) []string {
+			return []string{fmt.Sprintf("ONLY=%s-%d", block.Language, index)}
+		}, "none sh-1 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr, Env: tt.env, EnvFunc: tt.envFunc}
+			if err := r.Run(context.Background(), block, 1); err != nil {
+				t.Fatalf("Run() error = %v: %s", err, stderr.String())
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.csv"), []byte("alice\nbob\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		block   parser.CodeBlock
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "relative to the document",
+			block: parser.CodeBlock{Language: "sh", Command: "wc -l", Content: "ignored\n", Attributes: map[string]string{"stdin": "users.csv"}},
+			want:  "2",
+		},
+		{
+			name:    "missing file",
+			block:   parser.CodeBlock{Language: "sh", Command: "cat", Attributes: map[string]string{"stdin": "missing.csv"}},
+			wantErr: true,
+		},
+		{
+			name:    "with split",
+			block:   parser.CodeBlock{Language: "sh", Command: "cat", Content: "a\n\nb\n", Attributes: map[string]string{"stdin": "users.csv", "split": ""}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &stderr, File: filepath.Join(dir, "README.md")}
+			err := r.Run(context.Background(), tt.block, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.TrimSpace(stdout.String()); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAll_Pipe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "printf 'b\\na\\n'; echo sent >&2", Attributes: map[string]string{"pipe-to": "sort"}},
+		{Language: "sh", Command: "echo middle"},
+		{Language: "sh", Command: "sort", Content: "ignored\n", Attributes: map[string]string{"name": "sort"}},
+	}
+	var stdout, stderr bytes.Buffer
+	var order []int
+	r := &Runner{
+		Stdout:   &stdout,
+		Stderr:   &stderr,
+		OnResult: func(result *Result) { order = append(order, result.Index) },
+	}
+	if err := r.RunAll(context.Background(), blocks); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if got, want := stdout.String(), "a\nb\nmiddle\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	if got := stderr.String(); got != "sent\n" {
+		t.Errorf("stderr = %q, want %q", got, "sent\n")
+	}
+	if fmt.Sprint(order) != "[0 2 1]" {
+		t.Errorf("results in order %v, want [0 2 1]", order)
+	}
+}
+
+func TestPipeTargets(t *testing.T) {
+	named := func(name string, attrs ...string) parser.CodeBlock {
+		a := map[string]string{"name": name}
+		for i := 0; i+1 < len(attrs); i += 2 {
+			a[attrs[i]] = attrs[i+1]
+		}
+		return parser.CodeBlock{Language: "sh", Attributes: a}
+	}
+	tests := []struct {
+		name    string
+		blocks  []parser.CodeBlock
+		want    map[int]int
+		wantErr bool
+	}{
+		{"pipe", []parser.CodeBlock{named("a", "pipe-to", "b"), named("c"), named("b")}, map[int]int{0: 2}, false},
+		{"earlier block", []parser.CodeBlock{named("b"), named("a", "pipe-to", "b")}, nil, true},
+		{"missing", []parser.CodeBlock{named("a", "pipe-to", "b")}, nil, true},
+		{"another stage", []parser.CodeBlock{named("a", "pipe-to", "b"), named("b", "stage", "teardown")}, nil, true},
+		{"chain", []parser.CodeBlock{named("a", "pipe-to", "b"), named("b", "pipe-to", "c"), named("c")}, nil, true},
+		{"two senders", []parser.CodeBlock{named("a", "pipe-to", "c"), named("b", "pipe-to", "c"), named("c")}, nil, true},
+		{"stdin", []parser.CodeBlock{named("a", "pipe-to", "b"), named("b", "stdin", "data.csv")}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PipeTargets(tt.blocks)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PipeTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("PipeTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAll_Prev(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	blocks := []parser.CodeBlock{
+		{Language: "sh", Command: "echo {{ prev.i }}"},
+		{Language: "sh", Command: "echo hello; exit 3"},
+		{Language: "text"},
+		{Language: "sh", Command: `{{ prev.exit_code == 3 ? "echo recovered" : "echo unexpected" }} {{ shquote(prev.stdout) }}`},
+	}
+	var stdout, stderr bytes.Buffer
+	r := &Runner{Stdout: &stdout, Stderr: &stderr, KeepGoing: true}
+	if err := r.RunAll(context.Background(), blocks); err == nil {
+		t.Fatal("RunAll() should return error")
+	}
+	if got, want := stdout.String(), "-1\nhello\nrecovered hello\n\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
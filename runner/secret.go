@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"cmp"
+	"io"
+	"slices"
+	"strings"
+)
+
+// secretMask replaces the values of secrets in the output.
+const secretMask = "***"
+
+// secretValues returns the values masked in the output: the secrets and the lines of multi-line secrets,
+// longest first so that a secret containing another one is masked as a whole.
+func (r *Runner) secretValues() []string {
+	var values []string
+	for _, v := range r.Secrets {
+		for _, s := range append([]string{v}, strings.Split(v, "\n")...) {
+			if s = strings.TrimSpace(s); s != "" && !slices.Contains(values, s) {
+				values = append(values, s)
+			}
+		}
+	}
+	slices.SortFunc(values, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	return values
+}
+
+// secrets returns the value of {{secrets}}.
+func (r *Runner) secrets() map[string]string {
+	if r.Secrets == nil {
+		return map[string]string{}
+	}
+	return r.Secrets
+}
+
+// Mask returns s with the values of Secrets replaced with ***.
+func (r *Runner) Mask(s string) string {
+	for _, v := range r.secretValues() {
+		s = strings.ReplaceAll(s, v, secretMask)
+	}
+	return s
+}
+
+// trace returns Trace with the values of secrets masked (nil if Trace is not set).
+func (r *Runner) trace() io.Writer {
+	if r.Trace == nil {
+		return nil
+	}
+	return traceWriter{w: r.Trace, mask: r.Mask}
+}
+
+// traceWriter masks the values of secrets in the trace. Every trace line is written at once,
+// so a secret is never split across writes.
+type traceWriter struct {
+	w    io.Writer
+	mask func(string) string
+}
+
+func (t traceWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(t.w, t.mask(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// maskWriter replaces the values of secrets in the output written to w. A secret can be split across
+// writes, so the end of the output that may start a secret is held until more output or flush.
+type maskWriter struct {
+	w       io.Writer
+	values  [][]byte
+	pending []byte
+}
+
+// newMaskWriter returns a writer masking the values in the output written to w (nil if there are no values).
+func newMaskWriter(w io.Writer, values []string) *maskWriter {
+	if len(values) == 0 {
+		return nil
+	}
+	m := &maskWriter{w: w}
+	for _, v := range values {
+		m.values = append(m.values, []byte(v))
+	}
+	return m
+}
+
+func (m *maskWriter) Write(p []byte) (int, error) {
+	m.pending = append(m.pending, p...)
+	for _, v := range m.values {
+		m.pending = bytes.ReplaceAll(m.pending, v, []byte(secretMask))
+	}
+	hold := 0
+	for _, v := range m.values {
+		for n := min(len(v)-1, len(m.pending)); n > hold; n-- {
+			if bytes.HasSuffix(m.pending, v[:n]) {
+				hold = n
+				break
+			}
+		}
+	}
+	if _, err := m.w.Write(m.pending[:len(m.pending)-hold]); err != nil {
+		return 0, err
+	}
+	m.pending = slices.Clone(m.pending[len(m.pending)-hold:])
+	return len(p), nil
+}
+
+// flush writes the output held back.
+func (m *maskWriter) flush() {
+	if len(m.pending) > 0 {
+		_, _ = m.w.Write(m.pending) //nostyle:handlerrors
+		m.pending = nil
+	}
+}
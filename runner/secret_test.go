@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestMaskWriter(t *testing.T) {
+	values := (&Runner{Secrets: map[string]string{"token": "s3cret", "key": "line1\nline2", "inner": "cre"}}).secretValues()
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"whole", []string{"token=s3cret\n"}, "token=***\n"},
+		{"split across writes", []string{"token=s3", "cr", "et done\n"}, "token=*** done\n"},
+		{"held prefix flushed", []string{"ends with s3c"}, "ends with s3c"},
+		{"lines of a multi-line secret", []string{"line2 and line1\n"}, "*** and ***\n"},
+		{"contained secret", []string{"cre s3cret"}, "*** ***"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			m := newMaskWriter(&buf, values)
+			for _, w := range tt.writes {
+				if _, err := m.Write([]byte(w)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m.flush()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if newMaskWriter(&bytes.Buffer{}, nil) != nil {
+		t.Error("got a writer without values")
+	}
+}
+
+func TestRun_Secrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	var stdout, stderr bytes.Buffer
+	var result *Result
+	r := New("", nil)
+	r.Stdout, r.Stderr = &stdout, &stderr
+	r.CaptureOutput = true
+	r.Secrets = map[string]string{"token": "s3cret"}
+	r.OnResult = func(res *Result) { result = res }
+	block := parser.CodeBlock{Language: "sh", Command: "sh -c 'echo token={{secrets.token}}; echo {{secrets.token}} >&2'"}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "token=***\n" || stderr.String() != "***\n" {
+		t.Errorf("got stdout %q and stderr %q, want the secret masked", stdout.String(), stderr.String())
+	}
+	if result.Stdout != "token=***\n" || result.Command != "sh -c 'echo token=***; echo *** >&2'" {
+		t.Errorf("got captured stdout %q and command %q, want the secret masked", result.Stdout, result.Command)
+	}
+}
+
+func TestRun_SecretsInTraceAndLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	var trace, log bytes.Buffer
+	r := New("", nil)
+	r.Stdout, r.Stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	r.Secrets = map[string]string{"TOKEN": "hunter2"}
+	r.Trace = &trace
+	r.Logger = slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	block := parser.CodeBlock{Language: "sh", Command: "sh -c {{ shquote(secrets.TOKEN) }}"}
+	if err := r.Run(context.Background(), block, 0); err == nil {
+		t.Fatal("Run() should fail running the secret as a command")
+	}
+	for name, out := range map[string]string{"trace": trace.String(), "log": log.String()} {
+		if strings.Contains(out, "hunter2") {
+			t.Errorf("%s contains the secret:\n%s", name, out)
+		}
+	}
+	if want := `secrets = map[string]string{"TOKEN":"***"}`; !strings.Contains(trace.String(), want) {
+		t.Errorf("trace does not contain %q:\n%s", want, trace.String())
+	}
+	if want := `command="sh -c '***'"`; !strings.Contains(log.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, log.String())
+	}
+}
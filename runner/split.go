@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"strings"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrSplit is the attribute dividing the content of a block into chunks at the delimiter (e.g., split="---").
+// The command runs once per chunk with {{chunk}} and {{chunk_i}}, and the chunk as its stdin.
+const AttrSplit = "split"
+
+// splitChunks divides the content of a code block at the delimiter of the split attribute.
+// Surrounding whitespace of the chunks is trimmed and empty chunks are dropped.
+// An empty delimiter (or a bare split attribute, which the parser sets to "true") divides the content at blank lines.
+func splitChunks(block parser.CodeBlock) []string {
+	delim := block.Attributes[AttrSplit]
+	if delim == "" || delim == "true" {
+		delim = "\n\n"
+	}
+	var chunks []string
+	for _, c := range strings.Split(block.Content, delim) {
+		if c = strings.TrimSpace(c); c != "" {
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"delimiter", "```yaml {split=\"---\"} kubectl apply -f -\na: 1\n---\nb: 2\n```\n", []string{"a: 1", "b: 2"}},
+		{"empty delimiter", "```sql {split=\"\"} psql\nSELECT 1;\n\nSELECT 2;\n```\n", []string{"SELECT 1;", "SELECT 2;"}},
+		{"bare attribute", "```sql {split} psql\nSELECT 1;\n\nSELECT 2;\n```\n", []string{"SELECT 1;", "SELECT 2;"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := parser.Parse([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := splitChunks(blocks[0]); !slices.Equal(got, tt.want) {
+				t.Errorf("splitChunks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_SplitStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.csv"), []byte("alice\nbob\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		attrs map[string]string
+		pipe  bool
+	}{
+		{"stdin attribute", map[string]string{"split": "---", "stdin": "users.csv"}, false},
+		{"pipe", map[string]string{"split": "---"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			r := &Runner{Stdout: &stdout, Stderr: &bytes.Buffer{}, File: filepath.Join(dir, "README.md")}
+			if tt.pipe {
+				r.stdin = strings.NewReader("piped\n")
+			}
+			// Two chunks, neither of which may run
+			block := parser.CodeBlock{Language: "sh", Command: "cat", Content: "a\n---\nb\n", Attributes: tt.attrs}
+			err := r.Run(t.Context(), block, 0)
+			if err == nil || !strings.Contains(err.Error(), "stdin cannot be used with split") {
+				t.Errorf("Run() error = %v, want stdin cannot be used with split", err)
+			}
+			if stdout.Len() > 0 {
+				t.Errorf("stdout = %q, want no chunk to run", stdout.String())
+			}
+		})
+	}
+}
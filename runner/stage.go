@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// Attributes controlling when a block is run.
+const (
+	AttrStage  = "stage"  // Stage of the block (setup, main or teardown)
+	AttrAlways = "always" // If true, the block runs even after a failure or an interrupt
+)
+
+// Stages of code blocks. RunAll runs setup blocks first and teardown blocks last.
+const (
+	StageSetup    = "setup"
+	StageMain     = "main"
+	StageTeardown = "teardown"
+)
+
+var stages = []string{StageSetup, StageMain, StageTeardown}
+
+// BlockStage returns the stage of a code block (main if not specified).
+func BlockStage(block parser.CodeBlock) (string, error) {
+	v, ok := block.Attributes[AttrStage]
+	if !ok {
+		return StageMain, nil
+	}
+	if !slices.Contains(stages, v) {
+		return "", fmt.Errorf("invalid %s %q: must be one of setup, main or teardown", AttrStage, v)
+	}
+	return v, nil
+}
+
+// RunsAlways reports whether a code block runs even after a failure or an interrupt.
+// Teardown blocks always run.
+func RunsAlways(block parser.CodeBlock) (bool, error) {
+	stage, err := BlockStage(block)
+	if err != nil {
+		return false, err
+	}
+	v, ok := block.Attributes[AttrAlways]
+	if !ok {
+		return stage == StageTeardown, nil
+	}
+	always, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", AttrAlways, err)
+	}
+	return always, nil
+}
+
+// RunOrder returns the indexes of blocks in the order they are run: setup, main and teardown blocks,
+// each in document order.
+func RunOrder(blocks []parser.CodeBlock) ([]int, error) {
+	rank := make([]int, len(blocks))
+	order := make([]int, len(blocks))
+	for i, block := range blocks {
+		stage, err := BlockStage(block)
+		if err == nil {
+			_, err = RunsAlways(block)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("code block %d: %w", i+1, err)
+		}
+		rank[i] = slices.Index(stages, stage)
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return rank[a] - rank[b]
+	})
+	return order, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrStdin is the attribute connecting the stdin of the command to a file instead of the content
+// of the block (e.g., stdin=testdata/users.csv). Relative paths are resolved from the directory of the document.
+const AttrStdin = "stdin"
+
+// StdinPath returns the path of the file of the stdin attribute of a code block in the document file
+// ("-" or "" for stdin, whose relative paths are resolved from the working directory), and whether the block has one.
+func StdinPath(file string, block parser.CodeBlock) (string, bool) {
+	path, ok := block.Attributes[AttrStdin]
+	if !ok {
+		return "", false
+	}
+	if path != "" && !filepath.IsAbs(path) && file != "" && file != "-" {
+		path = filepath.Join(filepath.Dir(file), path)
+	}
+	return path, true
+}
+
+// openStdin opens the file of the stdin attribute of a code block (nil if the block has none).
+func (r *Runner) openStdin(block parser.CodeBlock) (*os.File, error) {
+	path, ok := StdinPath(r.File, block)
+	if !ok {
+		return nil, nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%s requires a path", AttrStdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", AttrStdin, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"path/filepath"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+// AttrWorkdir is the attribute running the command of a code block in a directory (e.g., workdir=services/api).
+// Relative paths are resolved from the directory of the document. It is also set by the workdir directive
+// of the document (see parser.AttrWorkdir).
+const AttrWorkdir = parser.AttrWorkdir
+
+// WorkDir returns the working directory of a code block in the document file given by the workdir attribute,
+// or "" for the working directory of runblock. Relative paths of documents read from stdin are resolved from the working directory.
+func WorkDir(file string, block parser.CodeBlock) string {
+	dir := block.Attributes[AttrWorkdir]
+	if dir == "" {
+		return ""
+	}
+	if !filepath.IsAbs(dir) && file != "" && file != "-" {
+		dir = filepath.Join(filepath.Dir(file), dir)
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/k1LoW/runblock/parser"
+)
+
+func TestWorkDir(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "api")
+	tests := []struct {
+		name string
+		file string
+		dir  string
+		want string
+	}{
+		{"none", "docs/README.md", "", ""},
+		{"relative to the document", "docs/README.md", "../services/api", filepath.Join("services", "api")},
+		{"stdin", "", "services/api", filepath.Join("services", "api")},
+		{"absolute", "docs/README.md", abs, abs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := parser.CodeBlock{Attributes: map[string]string{}}
+			if tt.dir != "" {
+				block.Attributes[AttrWorkdir] = tt.dir
+			}
+			if got := WorkDir(tt.file, block); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Workdir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("services", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	artifacts := t.TempDir()
+
+	var got *Result
+	var stdout bytes.Buffer
+	r := &Runner{
+		File:         "README.md",
+		Stdout:       &stdout,
+		ArtifactsDir: artifacts,
+		OnResult:     func(result *Result) { got = result },
+	}
+	block := parser.CodeBlock{
+		Language:   "sh",
+		Command:    "sh -c 'basename \"$PWD\" && echo ok > out.txt'",
+		Attributes: map[string]string{AttrWorkdir: "services/api", AttrArtifacts: "out.txt"},
+	}
+	if err := r.Run(context.Background(), block, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stdout.String(), "api\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if want := []string{filepath.Join(artifacts, "block-1", "out.txt")}; !slices.Equal(got.Artifacts, want) {
+		t.Errorf("Artifacts = %q, want %q", got.Artifacts, want)
+	}
+
+	// The working directory is a part of the hash
+	res, err := r.Resolve(block, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := block
+	other.Attributes = map[string]string{AttrWorkdir: "services"}
+	res2, err := r.Resolve(other, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Hash() == res2.Hash() {
+		t.Error("want different hashes for different working directories")
+	}
+}
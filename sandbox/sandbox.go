@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+// Package sandbox runs commands with a read-only view of the filesystem.
+//
+// On Linux, commands are confined with Landlock when the kernel supports it,
+// or with bubblewrap (bwrap) when it is installed. Landlock is applied by
+// re-executing the current binary, which the init function of this package
+// intercepts before main, so importing this package is enough to support it.
+package sandbox
+
+import (
+	"errors"
+)
+
+// ErrUnsupported is returned when no sandbox is available.
+var ErrUnsupported = errors.New("read-only sandbox requires Landlock (Linux 5.13+) or bubblewrap")
+
+// envConfig is the environment variable passing the configuration to the re-executed binary.
+const envConfig = "RUNBLOCK_SANDBOX"
@@ -0,0 +1,209 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"syscall"
+	"unsafe"
+)
+
+func init() {
+	if v, ok := os.LookupEnv(envConfig); ok {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", reexec(v))
+		os.Exit(126)
+	}
+}
+
+// config is the configuration passed to the re-executed binary.
+type config struct {
+	AllowWrite []string `json:"allow_write"`
+}
+
+// Landlock system calls and constants (see linux/landlock.h).
+const (
+	sysLandlockCreateRuleset = 444
+	sysLandlockAddRule       = 445
+	sysLandlockRestrictSelf  = 446
+
+	landlockCreateRulesetVersion = 1 << 0
+	landlockRulePathBeneath      = 1
+
+	accessWriteFile  = 1 << 1
+	accessRemoveDir  = 1 << 4
+	accessRemoveFile = 1 << 5
+	accessMakeChar   = 1 << 6
+	accessMakeDir    = 1 << 7
+	accessMakeReg    = 1 << 8
+	accessMakeSock   = 1 << 9
+	accessMakeFifo   = 1 << 10
+	accessMakeBlock  = 1 << 11
+	accessMakeSym    = 1 << 12
+	accessRefer      = 1 << 13 // ABI 2
+	accessTruncate   = 1 << 14 // ABI 3
+
+	prSetNoNewPrivs = 38
+)
+
+// landlockABI returns the Landlock ABI version supported by the kernel (0 if unsupported).
+func landlockABI() int {
+	v, _, errno := syscall.Syscall(sysLandlockCreateRuleset, 0, 0, landlockCreateRulesetVersion)
+	if errno != 0 {
+		return 0
+	}
+	return int(v)
+}
+
+// ReadOnly configures cmd to run without write access to the filesystem
+// except to the paths in allowWrite (and character devices such as /dev/null).
+// It must be called after the environment of cmd is set.
+func ReadOnly(cmd *exec.Cmd, allowWrite []string) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	paths := make([]string, 0, len(allowWrite))
+	for _, p := range allowWrite {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, abs)
+	}
+
+	if landlockABI() > 0 {
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate the executable: %w", err)
+		}
+		b, err := json.Marshal(config{AllowWrite: paths})
+		if err != nil {
+			return err
+		}
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, envConfig+"="+string(b))
+		cmd.Args = append([]string{"runblock-sandbox", cmd.Path}, cmd.Args[1:]...)
+		cmd.Path = self
+		return nil
+	}
+
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		return ErrUnsupported
+	}
+	args := []string{"bwrap", "--die-with-parent", "--ro-bind", "/", "/", "--dev", "/dev"}
+	for _, p := range paths {
+		args = append(args, "--bind", p, p)
+	}
+	cmd.Args = append(append(args, "--", cmd.Path), cmd.Args[1:]...)
+	cmd.Path = bwrap
+	return nil
+}
+
+// reexec restricts the process with Landlock and executes the command in os.Args[1:].
+// It only returns on failure.
+func reexec(v string) error {
+	var c config
+	if err := json.Unmarshal([]byte(v), &c); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(os.Args) < 2 {
+		return errors.New("no command")
+	}
+	env := slices.DeleteFunc(os.Environ(), func(e string) bool {
+		return len(e) > len(envConfig) && e[:len(envConfig)+1] == envConfig+"="
+	})
+
+	// Landlock restricts the calling thread, which then executes the command
+	runtime.LockOSThread()
+	if err := restrict(c.AllowWrite); err != nil {
+		return err
+	}
+	return syscall.Exec(os.Args[1], os.Args[1:], env)
+}
+
+// restrict restricts the calling thread from writing to the filesystem except to the paths.
+func restrict(allowWrite []string) error {
+	abi := landlockABI()
+	if abi == 0 {
+		return ErrUnsupported
+	}
+	const fileAccess = accessWriteFile | accessTruncate
+	access := uint64(accessWriteFile | accessRemoveDir | accessRemoveFile | accessMakeChar | accessMakeDir |
+		accessMakeReg | accessMakeSock | accessMakeFifo | accessMakeBlock | accessMakeSym)
+	if abi >= 2 {
+		access |= accessRefer
+	}
+	if abi >= 3 {
+		access |= accessTruncate
+	}
+
+	attr := struct{ handledAccessFS uint64 }{access}
+	fd, _, errno := syscall.Syscall(sysLandlockCreateRuleset, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create Landlock ruleset: %w", errno)
+	}
+	defer func() { _ = syscall.Close(int(fd)) }() //nostyle:handlerrors
+
+	rules := map[string]uint64{"/dev": accessWriteFile & access}
+	for _, p := range allowWrite {
+		rules[p] = access
+	}
+	for p, allowed := range rules {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return fmt.Errorf("failed to allow writes to %s: %w", p, err)
+		}
+		if !fi.IsDir() {
+			allowed &= fileAccess
+		}
+		pfd, err := syscall.Open(p, syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", p, err)
+		}
+		rule := struct {
+			allowedAccess uint64
+			parentFD      int32
+		}{allowed, int32(pfd)}
+		_, _, errno := syscall.Syscall6(sysLandlockAddRule, fd, landlockRulePathBeneath, uintptr(unsafe.Pointer(&rule)), 0, 0, 0)
+		_ = syscall.Close(pfd) //nostyle:handlerrors
+		if errno != 0 {
+			return fmt.Errorf("failed to allow writes to %s: %w", p, errno)
+		}
+	}
+
+	if _, _, errno := syscall.Syscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to set no_new_privs: %w", errno)
+	}
+	if _, _, errno := syscall.Syscall(sysLandlockRestrictSelf, fd, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to enforce Landlock ruleset: %w", errno)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2026 Ken'ichiro Oyama <k1lowxb@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	if landlockABI() == 0 {
+		if _, err := exec.LookPath("bwrap"); err != nil {
+			t.Skip("neither Landlock nor bubblewrap is available")
+		}
+	}
+	allowed := t.TempDir()
+	denied := t.TempDir()
+
+	cmd := exec.Command("sh", "-c", `echo ok > "$1/a" && cat "$1/a" && echo ng > "$2/b" 2>/dev/null`, "sh", allowed, denied)
+	if err := ReadOnly(cmd, []string{allowed}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("writing to a denied directory should fail: %v", err)
+	}
+	if got, want := string(out), "ok\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(denied, "b")); !os.IsNotExist(err) {
+		t.Errorf("file was written to the denied directory: %v", err)
+	}
+}